@@ -3,12 +3,15 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/Thunder-Compute/thunder-cli/pkg/types"
@@ -27,6 +30,10 @@ var ErrTransport = errors.New("transport error")
 type APIError struct {
 	StatusCode int
 	Message    string
+	// Code is the machine-readable "error" field of the response, if any.
+	Code string
+	// RetryAfter is the wait the server asked for in a Retry-After header.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
@@ -37,6 +44,52 @@ type Client struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+	// reportErrors sends breadcrumbs and server errors to Sentry.
+	reportErrors bool
+	// after waits between retries; tests replace time.After.
+	after func(time.Duration) <-chan time.Time
+
+	tokens  TokenSource
+	tokenMu sync.Mutex
+}
+
+// TokenSource keeps a Client's bearer token fresh. Token is asked before
+// every request and may refresh a token that is about to expire; Refresh is
+// called once when the server rejects a token with a 401, and the request
+// is retried with the token it returns.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Refresh(ctx context.Context, rejected string) (string, error)
+}
+
+// SetTokenSource makes the client take its token from ts.
+func (c *Client) SetTokenSource(ts TokenSource) {
+	c.tokens = ts
+}
+
+// SetRetryPolicy replaces DefaultRetryPolicy for this client.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	c.retry = p
+}
+
+// SetHTTPClient replaces the client's *http.Client, whose transport
+// otherwise records Sentry spans.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.httpClient = hc
+}
+
+// SetUserAgent sets the User-Agent header sent with every request.
+func (c *Client) SetUserAgent(ua string) {
+	c.userAgent = ua
+}
+
+// SetErrorReporting turns the client's Sentry breadcrumbs and server error
+// reports on or off. NewClient turns them on, which suits the CLI but not
+// programs that use the package as a library.
+func (c *Client) SetErrorReporting(enabled bool) {
+	c.reportErrors = enabled
 }
 
 func NewClient(token, baseURL string) *Client {
@@ -47,50 +100,156 @@ func NewClient(token, baseURL string) *Client {
 			Timeout:   30 * time.Second,
 			Transport: sentryhttpclient.NewSentryRoundTripper(nil),
 		},
+		retry:        DefaultRetryPolicy,
+		reportErrors: true,
+		after:        time.After,
 	}
 }
 
+// request is one API call, which may be sent more than once.
+type request struct {
+	method string
+	path   string
+	data   []byte
+	result interface{}
+	// idempotencyKey is sent as Idempotency-Key, identical on every retry,
+	// so the server applies a create or modify at most once.
+	idempotencyKey string
+}
+
 func (c *Client) doRequest(ctx context.Context, method, path string, body, result interface{}) error {
-	sentry.AddBreadcrumb(&sentry.Breadcrumb{
-		Category: "api",
-		Message:  method + " " + path,
-		Level:    sentry.LevelInfo,
-	})
+	return c.do(ctx, method, path, body, result, "")
+}
 
-	var bodyReader io.Reader
+// doIdempotentRequest is doRequest for a POST that changes state, tagged
+// with an idempotency key so that it can be retried like a GET.
+func (c *Client) doIdempotentRequest(ctx context.Context, method, path string, body, result interface{}) error {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	return c.do(ctx, method, path, body, result, hex.EncodeToString(key))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, result interface{}, idempotencyKey string) error {
+	c.breadcrumb(sentry.LevelInfo, method+" "+path)
+
+	req := &request{method: method, path: path, result: result, idempotencyKey: idempotencyKey}
 	if body != nil {
-		data, err := json.Marshal(body)
-		if err != nil {
+		var err error
+		if req.data, err = json.Marshal(body); err != nil {
 			return fmt.Errorf("failed to marshal request: %w", err)
 		}
-		bodyReader = bytes.NewReader(data)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	token := c.currentToken(ctx)
+	err := c.sendWithRetries(ctx, req, token)
+	var apiErr *APIError
+	if c.tokens == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		return err
+	}
+
+	refreshed, refreshErr := c.tokens.Refresh(ctx, token)
+	if refreshErr != nil {
+		c.breadcrumb(sentry.LevelWarning, "token refresh failed: "+refreshErr.Error())
+		return err
+	}
+	if refreshed == token {
+		return err
+	}
+	c.tokenMu.Lock()
+	c.token = refreshed
+	c.tokenMu.Unlock()
+	return c.sendWithRetries(ctx, req, refreshed)
+}
+
+// sendWithRetries sends req until it succeeds, fails in a way the retry
+// policy does not retry, or runs out of attempts. Server errors are
+// reported to Sentry once, after the last attempt.
+func (c *Client) sendWithRetries(ctx context.Context, req *request, token string) error {
+	err := c.sendAttempts(ctx, req, token)
+	var apiErr *APIError
+	if c.reportErrors && errors.As(err, &apiErr) && apiErr.StatusCode >= 500 {
+		sentry.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("api_method", req.method)
+			scope.SetTag("api_path", req.path)
+			scope.SetTag("status_code", fmt.Sprintf("%d", apiErr.StatusCode))
+			scope.SetLevel(sentry.LevelError)
+			sentry.CaptureMessage(fmt.Sprintf("API server error: %s %s returned %d", req.method, req.path, apiErr.StatusCode))
+		})
+	}
+	return err
+}
+
+func (c *Client) sendAttempts(ctx context.Context, req *request, token string) error {
+	for attempt := 1; ; attempt++ {
+		err := c.send(ctx, req, token)
+		if err == nil || attempt >= c.retry.MaxAttempts || !c.retry.retryable(ctx, req, err) {
+			return err
+		}
+		delay, ok := c.retry.delay(attempt, err)
+		if !ok {
+			return err
+		}
+		c.breadcrumb(sentry.LevelWarning, fmt.Sprintf("retrying %s %s in %s: %v", req.method, req.path, delay, err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.after(delay):
+		}
+	}
+}
+
+func (c *Client) breadcrumb(level sentry.Level, message string) {
+	if c.reportErrors {
+		sentry.AddBreadcrumb(&sentry.Breadcrumb{Category: "api", Message: message, Level: level})
+	}
+}
+
+// currentToken returns the token to send, letting the TokenSource refresh
+// it first. A failed refresh falls back to the token already held, which
+// the server may still accept.
+func (c *Client) currentToken(ctx context.Context) string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.tokens != nil {
+		if token, err := c.tokens.Token(ctx); err == nil {
+			c.token = token
+		}
+	}
+	return c.token
+}
+
+// send makes one attempt at req with the given bearer token.
+func (c *Client) send(ctx context.Context, req *request, token string) error {
+	var bodyReader io.Reader
+	if req.data != nil {
+		bodyReader = bytes.NewReader(req.data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, c.baseURL+req.path, bodyReader)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Thunder-Client", "GO-CLI")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Thunder-Client", "GO-CLI")
+	if c.userAgent != "" {
+		httpReq.Header.Set("User-Agent", c.userAgent)
+	}
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return fmt.Errorf("%w: failed to make request: %w", ErrTransport, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 500 {
-		sentry.WithScope(func(scope *sentry.Scope) {
-			scope.SetTag("api_method", method)
-			scope.SetTag("api_path", path)
-			scope.SetTag("status_code", fmt.Sprintf("%d", resp.StatusCode))
-			scope.SetLevel(sentry.LevelError)
-			sentry.CaptureMessage(fmt.Sprintf("API server error: %s %s returned %d", method, path, resp.StatusCode))
-		})
-	}
-
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("failed to read response: %w", err)
@@ -100,11 +259,14 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 		if resp.StatusCode == 401 {
 			return &APIError{StatusCode: 401, Message: "authentication failed: invalid token"}
 		}
-		apiErr := &APIError{StatusCode: resp.StatusCode}
+		apiErr := &APIError{StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
 		var parsed struct {
 			Message string `json:"message"`
+			Code    string `json:"error"`
 		}
-		if json.Unmarshal(respBody, &parsed) == nil && parsed.Message != "" {
+		_ = json.Unmarshal(respBody, &parsed)
+		apiErr.Code = parsed.Code
+		if parsed.Message != "" {
 			apiErr.Message = parsed.Message
 		} else {
 			apiErr.Message = fmt.Sprintf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
@@ -112,8 +274,8 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 		return apiErr
 	}
 
-	if result != nil && len(respBody) > 0 {
-		if err := json.Unmarshal(respBody, result); err != nil {
+	if req.result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, req.result); err != nil {
 			return fmt.Errorf("failed to parse response: %w", err)
 		}
 	}
@@ -141,6 +303,77 @@ func (c *Client) ValidateToken(ctx context.Context) (*ValidateTokenResult, error
 	return &result, nil
 }
 
+// RefreshToken exchanges a refresh token for a new access token. It is sent
+// without the client's bearer token, which has usually expired by now.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*RefreshTokenResponse, error) {
+	var result RefreshTokenResponse
+	data, err := json.Marshal(RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := c.send(ctx, &request{method: "POST", path: "/v1/auth/refresh", data: data, result: &result}, ""); err != nil {
+		return nil, err
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("token refresh returned no token")
+	}
+	return &result, nil
+}
+
+// Device authorization errors returned by PollDeviceToken.
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("polling too fast")
+	ErrAccessDenied         = errors.New("authorization was denied")
+	ErrDeviceCodeExpired    = errors.New("device code expired")
+)
+
+// StartDeviceAuth begins a device authorization: the user approves the
+// returned code in a browser on any machine while the CLI polls
+// PollDeviceToken.
+func (c *Client) StartDeviceAuth(ctx context.Context) (*DeviceCodeResponse, error) {
+	var result DeviceCodeResponse
+	if err := c.send(ctx, &request{method: "POST", path: "/v1/auth/device/code", data: []byte("{}"), result: &result}, ""); err != nil {
+		return nil, err
+	}
+	if result.DeviceCode == "" || result.UserCode == "" {
+		return nil, fmt.Errorf("device authorization returned no code")
+	}
+	return &result, nil
+}
+
+// PollDeviceToken checks once whether the user has approved deviceCode. It
+// returns ErrAuthorizationPending until they have, ErrSlowDown if polled too
+// often, and ErrAccessDenied or ErrDeviceCodeExpired when it is over.
+func (c *Client) PollDeviceToken(ctx context.Context, deviceCode string) (*RefreshTokenResponse, error) {
+	data, err := json.Marshal(DeviceTokenRequest{DeviceCode: deviceCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	var result RefreshTokenResponse
+	err = c.send(ctx, &request{method: "POST", path: "/v1/auth/device/token", data: data, result: &result}, "")
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case "authorization_pending":
+			return nil, ErrAuthorizationPending
+		case "slow_down":
+			return nil, ErrSlowDown
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if result.Token == "" {
+		return nil, fmt.Errorf("device authorization returned no token")
+	}
+	return &result, nil
+}
+
 func (c *Client) ListInstancesWithIPUpdateCtx(ctx context.Context) ([]Instance, error) {
 	var raw map[string]Instance
 	if err := c.doRequest(ctx, "GET", "/v1/instances/list?update_ips=true", nil, &raw); err != nil {
@@ -153,14 +386,18 @@ func (c *Client) ListInstancesWithIPUpdate() ([]Instance, error) {
 	return c.ListInstancesWithIPUpdateCtx(context.Background())
 }
 
-func (c *Client) ListInstances() ([]Instance, error) {
+func (c *Client) ListInstancesCtx(ctx context.Context) ([]Instance, error) {
 	var raw map[string]Instance
-	if err := c.doRequest(context.Background(), "GET", "/v1/instances/list", nil, &raw); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/instances/list", nil, &raw); err != nil {
 		return nil, err
 	}
 	return sortedInstances(raw), nil
 }
 
+func (c *Client) ListInstances() ([]Instance, error) {
+	return c.ListInstancesCtx(context.Background())
+}
+
 func (c *Client) AddSSHKeyCtx(ctx context.Context, instanceID string) (*AddSSHKeyResponse, error) {
 	var resp AddSSHKeyResponse
 	if err := c.doRequest(ctx, "POST", fmt.Sprintf("/v1/instances/%s/add_key", instanceID), nil, &resp); err != nil {
@@ -173,9 +410,9 @@ func (c *Client) AddSSHKey(instanceID string) (*AddSSHKeyResponse, error) {
 	return c.AddSSHKeyCtx(context.Background(), instanceID)
 }
 
-func (c *Client) ListTemplates() ([]TemplateEntry, error) {
+func (c *Client) ListTemplatesCtx(ctx context.Context) ([]TemplateEntry, error) {
 	var raw types.ThunderTemplatesResponse
-	if err := c.doRequest(context.Background(), "GET", "/v1/thunder-templates", nil, &raw); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/thunder-templates", nil, &raw); err != nil {
 		return nil, err
 	}
 	entries := make([]TemplateEntry, 0, len(raw))
@@ -185,87 +422,162 @@ func (c *Client) ListTemplates() ([]TemplateEntry, error) {
 	return entries, nil
 }
 
-func (c *Client) CreateInstance(req CreateInstanceRequest) (*CreateInstanceResponse, error) {
+func (c *Client) ListTemplates() ([]TemplateEntry, error) {
+	return c.ListTemplatesCtx(context.Background())
+}
+
+func (c *Client) CreateInstanceCtx(ctx context.Context, req CreateInstanceRequest) (*CreateInstanceResponse, error) {
 	var resp CreateInstanceResponse
-	if err := c.doRequest(context.Background(), "POST", "/v1/instances/create", req, &resp); err != nil {
+	if err := c.doIdempotentRequest(ctx, "POST", "/v1/instances/create", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-func (c *Client) DeleteInstance(instanceID string) (*DeleteInstanceResponse, error) {
-	if err := c.doRequest(context.Background(), "POST", fmt.Sprintf("/v1/instances/%s/delete", instanceID), nil, nil); err != nil {
+func (c *Client) CreateInstance(req CreateInstanceRequest) (*CreateInstanceResponse, error) {
+	return c.CreateInstanceCtx(context.Background(), req)
+}
+
+func (c *Client) DeleteInstanceCtx(ctx context.Context, instanceID string) (*DeleteInstanceResponse, error) {
+	if err := c.doRequest(ctx, "POST", fmt.Sprintf("/v1/instances/%s/delete", instanceID), nil, nil); err != nil {
 		return nil, err
 	}
 	return &DeleteInstanceResponse{Message: "Instance deleted successfully", Success: true}, nil
 }
 
-// ModifyInstance modifies an existing instance configuration.
-func (c *Client) ModifyInstance(instanceID string, req InstanceModifyRequest) (*InstanceModifyResponse, error) {
+func (c *Client) DeleteInstance(instanceID string) (*DeleteInstanceResponse, error) {
+	return c.DeleteInstanceCtx(context.Background(), instanceID)
+}
+
+// StartInstanceCtx boots a stopped instance.
+func (c *Client) StartInstanceCtx(ctx context.Context, instanceID string) (*InstanceActionResponse, error) {
+	if err := c.instanceAction(ctx, instanceID, "start", "instance cannot be started (must be STOPPED)"); err != nil {
+		return nil, err
+	}
+	return &InstanceActionResponse{Message: "Instance starting", Success: true}, nil
+}
+
+func (c *Client) StartInstance(instanceID string) (*InstanceActionResponse, error) {
+	return c.StartInstanceCtx(context.Background(), instanceID)
+}
+
+// StopInstanceCtx shuts a running instance down, keeping its disk.
+func (c *Client) StopInstanceCtx(ctx context.Context, instanceID string) (*InstanceActionResponse, error) {
+	if err := c.instanceAction(ctx, instanceID, "stop", "instance cannot be stopped (must be RUNNING)"); err != nil {
+		return nil, err
+	}
+	return &InstanceActionResponse{Message: "Instance stopping", Success: true}, nil
+}
+
+func (c *Client) StopInstance(instanceID string) (*InstanceActionResponse, error) {
+	return c.StopInstanceCtx(context.Background(), instanceID)
+}
+
+func (c *Client) instanceAction(ctx context.Context, instanceID, action, conflictMsg string) error {
+	err := c.doRequest(ctx, "POST", fmt.Sprintf("/v1/instances/%s/%s", instanceID, action), nil, nil)
+	return explainInstanceError(err, conflictMsg)
+}
+
+// explainInstanceError rewords 404 and 409 responses for an instance,
+// keeping them *APIErrors so that callers can still tell them apart.
+func explainInstanceError(err error, conflictMsg string) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case 404:
+			return &APIError{StatusCode: 404, Code: apiErr.Code, Message: "instance not found"}
+		case 409:
+			return &APIError{StatusCode: 409, Code: apiErr.Code, Message: conflictMsg}
+		}
+	}
+	return err
+}
+
+// ModifyInstanceCtx modifies an existing instance configuration.
+func (c *Client) ModifyInstanceCtx(ctx context.Context, instanceID string, req InstanceModifyRequest) (*InstanceModifyResponse, error) {
 	var resp InstanceModifyResponse
-	err := c.doRequest(context.Background(), "POST", fmt.Sprintf("/v1/instances/%s/modify", instanceID), req, &resp)
+	err := c.doIdempotentRequest(ctx, "POST", fmt.Sprintf("/v1/instances/%s/modify", instanceID), req, &resp)
 	if err != nil {
-		var apiErr *APIError
-		if errors.As(err, &apiErr) {
-			switch apiErr.StatusCode {
-			case 404:
-				return nil, fmt.Errorf("instance not found")
-			case 409:
-				return nil, fmt.Errorf("instance cannot be modified (may not be in RUNNING state)")
-			}
-		}
-		return nil, err
+		return nil, explainInstanceError(err, "instance cannot be modified (may not be in RUNNING state)")
 	}
 	return &resp, nil
 }
 
-func (c *Client) CreateSnapshot(req CreateSnapshotRequest) (*CreateSnapshotResponse, error) {
+func (c *Client) ModifyInstance(instanceID string, req InstanceModifyRequest) (*InstanceModifyResponse, error) {
+	return c.ModifyInstanceCtx(context.Background(), instanceID, req)
+}
+
+func (c *Client) CreateSnapshotCtx(ctx context.Context, req CreateSnapshotRequest) (*CreateSnapshotResponse, error) {
 	var resp CreateSnapshotResponse
-	if err := c.doRequest(context.Background(), "POST", "/v1/snapshots/create", req, &resp); err != nil {
+	if err := c.doIdempotentRequest(ctx, "POST", "/v1/snapshots/create", req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-func (c *Client) ListSnapshots() (ListSnapshotsResponse, error) {
+func (c *Client) CreateSnapshot(req CreateSnapshotRequest) (*CreateSnapshotResponse, error) {
+	return c.CreateSnapshotCtx(context.Background(), req)
+}
+
+func (c *Client) ListSnapshotsCtx(ctx context.Context) (ListSnapshotsResponse, error) {
 	var resp ListSnapshotsResponse
-	if err := c.doRequest(context.Background(), "GET", "/v1/snapshots/list", nil, &resp); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/snapshots/list", nil, &resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
+func (c *Client) ListSnapshots() (ListSnapshotsResponse, error) {
+	return c.ListSnapshotsCtx(context.Background())
+}
+
+func (c *Client) DeleteSnapshotCtx(ctx context.Context, snapshotID string) error {
+	return c.doRequest(ctx, "DELETE", fmt.Sprintf("/v1/snapshots/%s", snapshotID), nil, nil)
+}
+
 func (c *Client) DeleteSnapshot(snapshotID string) error {
-	return c.doRequest(context.Background(), "DELETE", fmt.Sprintf("/v1/snapshots/%s", snapshotID), nil, nil)
+	return c.DeleteSnapshotCtx(context.Background(), snapshotID)
 }
 
-// FetchPricing retrieves the public pricing data from the API.
-func (c *Client) FetchPricing() (map[string]float64, error) {
+// FetchPricingCtx retrieves the public pricing data from the API.
+func (c *Client) FetchPricingCtx(ctx context.Context) (map[string]float64, error) {
 	var result struct {
 		Pricing map[string]float64 `json:"pricing"`
 	}
-	if err := c.doRequest(context.Background(), "GET", "/v1/pricing", nil, &result); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/pricing", nil, &result); err != nil {
 		return nil, err
 	}
 	return result.Pricing, nil
 }
 
-// GetSpecs retrieves GPU spec configurations from the API.
-func (c *Client) GetSpecs() (map[string]GpuSpecConfig, error) {
+func (c *Client) FetchPricing() (map[string]float64, error) {
+	return c.FetchPricingCtx(context.Background())
+}
+
+// GetSpecsCtx retrieves GPU spec configurations from the API.
+func (c *Client) GetSpecsCtx(ctx context.Context) (map[string]GpuSpecConfig, error) {
 	var result struct {
 		Specs map[string]GpuSpecConfig `json:"specs"`
 	}
-	if err := c.doRequest(context.Background(), "GET", "/v1/specs", nil, &result); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/specs", nil, &result); err != nil {
 		return nil, err
 	}
 	return result.Specs, nil
 }
 
-// GetAvailability retrieves per-spec GPU availability from the API.
-func (c *Client) GetAvailability() (*GPUAvailabilityResponse, error) {
+func (c *Client) GetSpecs() (map[string]GpuSpecConfig, error) {
+	return c.GetSpecsCtx(context.Background())
+}
+
+// GetAvailabilityCtx retrieves per-spec GPU availability from the API.
+func (c *Client) GetAvailabilityCtx(ctx context.Context) (*GPUAvailabilityResponse, error) {
 	var result GPUAvailabilityResponse
-	if err := c.doRequest(context.Background(), "GET", "/v1/status", nil, &result); err != nil {
+	if err := c.doRequest(ctx, "GET", "/v1/status", nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
+
+func (c *Client) GetAvailability() (*GPUAvailabilityResponse, error) {
+	return c.GetAvailabilityCtx(context.Background())
+}
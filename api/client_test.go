@@ -1,7 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -202,3 +207,109 @@ func TestNewClientWithCustomURL(t *testing.T) {
 	assert.Equal(t, customURL, client.baseURL)
 	assert.Equal(t, token, client.token)
 }
+
+func TestStartStopInstance(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		paths = append(paths, r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/v1/instances/9/") {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := NewClient("token", server.URL)
+
+	resp, err := client.StopInstance("3")
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	_, err = client.StartInstance("3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/v1/instances/3/stop", "/v1/instances/3/start"}, paths)
+
+	_, err = client.StartInstance("9")
+	assert.EqualError(t, err, "instance cannot be started (must be STOPPED)")
+}
+
+func TestListInstancesCtxCancel(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := NewClient("token", server.URL).ListInstancesCtx(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+type fakeTokenSource struct {
+	token     string
+	refreshed string
+	refreshes int
+}
+
+func (f *fakeTokenSource) Token(ctx context.Context) (string, error) { return f.token, nil }
+
+func (f *fakeTokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	f.refreshes++
+	f.token = f.refreshed
+	return f.token, nil
+}
+
+func TestDoRequestRefreshesOn401(t *testing.T) {
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"name":"x"}`, string(body), "the body is resent on retry")
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("stale", server.URL)
+	tokens := &fakeTokenSource{token: "stale", refreshed: "fresh"}
+	client.SetTokenSource(tokens)
+
+	var result map[string]bool
+	require.NoError(t, client.doRequest(context.Background(), "POST", "/x", map[string]string{"name": "x"}, &result))
+	assert.True(t, result["ok"])
+	assert.Equal(t, []string{"Bearer stale", "Bearer fresh"}, auths)
+	assert.Equal(t, 1, tokens.refreshes)
+
+	// A token the server still rejects after refreshing is not retried again.
+	tokens.token, tokens.refreshed = "bad", "still-bad"
+	auths = nil
+	err := client.doRequest(context.Background(), "POST", "/x", map[string]string{"name": "x"}, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 401, apiErr.StatusCode)
+	assert.Len(t, auths, 2)
+}
+
+func TestRefreshTokenRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/auth/refresh", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		var req RefreshTokenRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "refresh-1", req.RefreshToken)
+		_, _ = w.Write([]byte(`{"token":"new","refresh_token":"refresh-2","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient("expired", server.URL).RefreshToken(context.Background(), "refresh-1")
+	require.NoError(t, err)
+	assert.Equal(t, RefreshTokenResponse{Token: "new", RefreshToken: "refresh-2", ExpiresIn: 3600}, *resp)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy decides which failed requests a Client sends again and how
+// long it waits first. Rate limiting (429), gateway errors (502, 503, 504)
+// and ErrTransport failures are retried; other errors are returned at once.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the backoff ceiling after the first failure. It doubles
+	// with every attempt up to MaxDelay, and the wait is drawn uniformly
+	// below it ("full jitter").
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxRetryAfter is the longest Retry-After the client will wait out;
+	// a request asked to wait longer fails instead.
+	MaxRetryAfter time.Duration
+	// RetryNonIdempotent also retries POSTs that carry no idempotency key,
+	// which may then be applied twice.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used by clients from NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:   4,
+	BaseDelay:     500 * time.Millisecond,
+	MaxDelay:      8 * time.Second,
+	MaxRetryAfter: time.Minute,
+}
+
+// NoRetries sends every request exactly once.
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+func (p RetryPolicy) retryable(ctx context.Context, req *request, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if !p.RetryNonIdempotent && req.idempotencyKey == "" && !idempotentMethod(req.method) {
+		return false
+	}
+	if errors.Is(err, ErrTransport) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// idempotentMethod reports whether sending method twice has the same effect
+// as sending it once (RFC 9110 section 9.2.2).
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait after the given failed attempt, or false
+// if the server asked for a longer wait than MaxRetryAfter.
+func (p RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		if p.MaxRetryAfter > 0 && apiErr.RetryAfter > p.MaxRetryAfter {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+
+	ceiling := p.MaxDelay
+	if attempt < 32 && p.BaseDelay<<(attempt-1) < ceiling {
+		ceiling = p.BaseDelay << (attempt - 1)
+	}
+	if ceiling <= 0 {
+		return 0, true
+	}
+	return rand.N(ceiling + 1), true
+}
+
+// parseRetryAfter reads a Retry-After header, given either in seconds or
+// as an HTTP date. It returns 0 if there is none.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
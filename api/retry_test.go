@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedServer answers each request with the next status in its script,
+// then 200 with body, recording every request it saw.
+type scriptedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	body     string
+	requests []*http.Request
+}
+
+func newScriptedServer(t *testing.T, body string, statuses ...int) *scriptedServer {
+	s := &scriptedServer{statuses: statuses, header: http.Header{}, body: body}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		n := len(s.requests)
+		s.requests = append(s.requests, r)
+		for k, v := range s.header {
+			w.Header()[k] = v
+		}
+		if n < len(s.statuses) {
+			w.WriteHeader(s.statuses[n])
+			return
+		}
+		_, _ = w.Write([]byte(s.body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// testRetryClient records the waits between attempts instead of sleeping.
+func testRetryClient(url string, waits *[]time.Duration) *Client {
+	c := NewClient("token", url)
+	c.after = func(d time.Duration) <-chan time.Time {
+		*waits = append(*waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+		return ch
+	}
+	return c
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	server := newScriptedServer(t, `{"1":{"name":"a"}}`, 503, 429, 502)
+	var waits []time.Duration
+	client := testRetryClient(server.URL, &waits)
+
+	instances, err := client.ListInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Len(t, server.requests, 4)
+
+	require.Len(t, waits, 3)
+	for i, wait := range waits {
+		ceiling := DefaultRetryPolicy.BaseDelay << i
+		assert.LessOrEqual(t, wait, ceiling, "attempt %d waits at most its backoff ceiling", i+1)
+		assert.GreaterOrEqual(t, wait, time.Duration(0))
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newScriptedServer(t, `{}`, 504, 504, 504, 504, 504)
+	var waits []time.Duration
+	client := testRetryClient(server.URL, &waits)
+
+	_, err := client.ListInstances()
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 504, apiErr.StatusCode)
+	assert.Len(t, server.requests, DefaultRetryPolicy.MaxAttempts)
+
+	client.SetRetryPolicy(NoRetries)
+	server.requests = nil
+	_, err = client.ListInstances()
+	assert.Error(t, err)
+	assert.Len(t, server.requests, 1)
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	server := newScriptedServer(t, `{}`, 429)
+	server.header.Set("Retry-After", "3")
+	var waits []time.Duration
+	client := testRetryClient(server.URL, &waits)
+
+	_, err := client.ListInstances()
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{3 * time.Second}, waits)
+
+	// A wait beyond MaxRetryAfter is not sat out.
+	server.requests, waits = nil, nil
+	server.statuses = []int{429}
+	server.header.Set("Retry-After", "3600")
+	_, err = client.ListInstances()
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, time.Hour, apiErr.RetryAfter)
+	assert.Empty(t, waits)
+}
+
+func TestRetryOnlyIdempotentPOSTs(t *testing.T) {
+	// Deleting is a plain POST and is not retried.
+	server := newScriptedServer(t, `{"uuid":"u1","key":"k","identifier":3}`, 503)
+	var waits []time.Duration
+	client := testRetryClient(server.URL, &waits)
+	_, err := client.DeleteInstance("3")
+	assert.Error(t, err)
+	assert.Len(t, server.requests, 1)
+
+	// Creating carries an idempotency key, the same on every attempt.
+	server.requests = nil
+	server.statuses = []int{503, 503}
+	resp, err := client.CreateInstance(CreateInstanceRequest{GPUType: "a6000", NumGPUs: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "u1", resp.UUID)
+	require.Len(t, server.requests, 3)
+	key := server.requests[0].Header.Get("Idempotency-Key")
+	assert.Len(t, key, 32)
+	for _, r := range server.requests {
+		assert.Equal(t, key, r.Header.Get("Idempotency-Key"))
+	}
+
+	// Each call gets its own key.
+	server.requests = nil
+	_, err = client.CreateInstance(CreateInstanceRequest{GPUType: "a6000", NumGPUs: 1})
+	require.NoError(t, err)
+	assert.NotEqual(t, key, server.requests[0].Header.Get("Idempotency-Key"))
+}
+
+func TestRetryClientErrorsAreNotRetried(t *testing.T) {
+	server := newScriptedServer(t, `{}`, 400, 404)
+	var waits []time.Duration
+	client := testRetryClient(server.URL, &waits)
+	_, err := client.ListInstances()
+	assert.Error(t, err)
+	assert.Len(t, server.requests, 1)
+	assert.Empty(t, waits)
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	var waits []time.Duration
+	client := testRetryClient(url, &waits)
+	_, err := client.ListInstances()
+	assert.ErrorIs(t, err, ErrTransport)
+	assert.Len(t, waits, DefaultRetryPolicy.MaxAttempts-1)
+}
+
+func TestRetryStopsWhenContextCancelled(t *testing.T) {
+	server := newScriptedServer(t, `{}`, 503, 503, 503)
+	client := NewClient("token", server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	client.after = func(time.Duration) <-chan time.Time {
+		cancel()
+		return make(chan time.Time)
+	}
+
+	err := client.doRequest(ctx, "GET", "/v1/instances/list", nil, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, server.requests, 1)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	date := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, 30*time.Second, parseRetryAfter(date), float64(2*time.Second))
+}
+
+func TestRetryDelayCeiling(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	for attempt := 1; attempt <= 40; attempt++ {
+		d, ok := p.delay(attempt, ErrTransport)
+		require.True(t, ok)
+		assert.LessOrEqual(t, d, min(time.Second<<min(attempt-1, 3), 4*time.Second))
+	}
+}
@@ -21,6 +21,35 @@ type (
 	ValidateTokenResult    = types.ValidateTokenResponse
 )
 
+// RefreshTokenRequest is the body of POST /v1/auth/refresh.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenResponse is a new access token. RefreshToken is set when the
+// server rotates the refresh token too.
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+}
+
+// DeviceCodeResponse starts a device authorization (RFC 8628).
+// VerificationURIComplete, when set, has the user code filled in.
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// DeviceTokenRequest is the body of POST /v1/auth/device/token.
+type DeviceTokenRequest struct {
+	DeviceCode string `json:"device_code"`
+}
+
 // StorageRange defines min/max storage in GB.
 type StorageRange struct {
 	Min int `json:"min"`
@@ -57,10 +86,16 @@ type DeleteInstanceResponse struct {
 	Success bool   `json:"success"`
 }
 
+// InstanceActionResponse is CLI-specific, like DeleteInstanceResponse.
+type InstanceActionResponse struct {
+	Message string `json:"message"`
+	Success bool   `json:"success"`
+}
+
 // ConnectClient defines the interface for API operations used by the connect command.
 // This interface allows for mocking in tests.
 type ConnectClient interface {
-	ListInstances() ([]Instance, error)
+	ListInstancesCtx(ctx context.Context) ([]Instance, error)
 	ListInstancesWithIPUpdateCtx(ctx context.Context) ([]Instance, error)
 	AddSSHKeyCtx(ctx context.Context, instanceID string) (*AddSSHKeyResponse, error)
 }
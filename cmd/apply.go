@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var fleetFile string
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <fleet.yaml>",
+	Short: "Create, modify or delete instances to match a fleet spec",
+	Args:  wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApply(fleetFile, false)
+	},
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan -f <fleet.yaml>",
+	Short: "Show what tnr apply would change and what it would cost",
+	Args:  wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApply(fleetFile, true)
+	},
+}
+
+func init() {
+	applyCmd.SetHelpFunc(wrapHelp(helpmenus.RenderApplyHelp))
+	planCmd.SetHelpFunc(wrapHelp(helpmenus.RenderApplyHelp))
+	for _, c := range []*cobra.Command{applyCmd, planCmd} {
+		c.Flags().StringVarP(&fleetFile, "file", "f", "", "Fleet spec file (YAML or JSON, - for stdin)")
+		rootCmd.AddCommand(c)
+	}
+}
+
+func runApply(path string, dryRun bool) error {
+	if path == "" {
+		return usageErr("a fleet spec is required, e.g. tnr apply -f fleet.yaml")
+	}
+	spec, err := loadFleetSpec(path)
+	if err != nil {
+		return err
+	}
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	state, err := utils.ReadFleetState(spec.Name)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl+C is handled separately while fetching and while applying, so
+	// that it still ends the confirmation prompt in between.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	var env *fleetEnv
+	err = tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		env, e = fetchFleetEnv(ctx, client)
+		return e
+	})
+	stop()
+	if err != nil {
+		return err
+	}
+
+	plan, err := planFleet(spec, state, env)
+	if err != nil {
+		return err
+	}
+	if !JSONOutput {
+		printFleetPlan(plan, env.pricing != nil)
+	}
+	if dryRun {
+		if JSONOutput {
+			printResult(plan)
+		}
+		return nil
+	}
+
+	pruneFleetState(state, env.instances)
+	if len(plan.Actions) == 0 {
+		if JSONOutput {
+			printResult(plan)
+		}
+		return utils.WriteFleetState(state)
+	}
+
+	if !YesFlag {
+		if !tui.IsInteractive() || JSONOutput {
+			return usageErr("use --yes to apply changes in non-interactive mode")
+		}
+		fmt.Print("Apply these changes? (yes/no): ")
+		var confirmation string
+		fmt.Scanln(&confirmation)
+		if confirmation != "yes" && confirmation != "y" {
+			PrintWarningSimple("Apply cancelled")
+			return nil
+		}
+	}
+
+	ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = applyFleetPlan(ctx, client, plan, state)
+	if JSONOutput {
+		printResult(plan)
+	}
+	if err != nil {
+		if !isUserError(err) {
+			sentry.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("operation", "fleet_apply")
+				sentry.CaptureException(err)
+			})
+		}
+		return err
+	}
+	if !JSONOutput {
+		PrintSuccessSimple(fmt.Sprintf("Fleet %s applied", plan.Fleet))
+	}
+	return nil
+}
+
+func fetchFleetEnv(ctx context.Context, client *api.Client) (*fleetEnv, error) {
+	specsMap, err := client.GetSpecsCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch GPU specs: %w", err)
+	}
+	env := &fleetEnv{specs: utils.NewSpecStore(specsMap), capacity: utils.NewSpecStore(specsMap)}
+	if availability, err := client.GetAvailabilityCtx(ctx); err == nil && availability != nil {
+		env.capacity = utils.NewSpecStoreWithAvailability(specsMap, availability.Specs)
+	}
+
+	if env.instances, err = client.ListInstancesCtx(ctx); err != nil {
+		return nil, utils.WrapAPIError(err, "failed to list instances")
+	}
+	if env.templates, err = client.ListTemplatesCtx(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch templates: %w", err)
+	}
+	snapshots, _ := client.ListSnapshotsCtx(ctx)
+	for _, s := range snapshots {
+		if s.Status == "READY" {
+			env.snapshots = append(env.snapshots, s)
+		}
+	}
+	if rates, err := client.FetchPricingCtx(ctx); err == nil {
+		env.pricing = &utils.PricingData{Rates: rates}
+	}
+	return env, nil
+}
+
+// applyFleetPlan runs a plan's actions in order, recording created and
+// deleted instances in state as it goes so an interrupted apply can be
+// resumed. A failed action does not stop the others; the error counts them.
+// Once ctx is done, the remaining actions are skipped.
+func applyFleetPlan(ctx context.Context, client *api.Client, plan *fleetPlan, state *utils.FleetState) error {
+	failed := 0
+	var firstErr error
+	for i := range plan.Actions {
+		if err := ctx.Err(); err != nil {
+			for j := i; j < len(plan.Actions); j++ {
+				plan.Actions[j].Error = "skipped: apply was interrupted"
+			}
+			return fmt.Errorf("apply interrupted with %d of %d changes left; run tnr apply again to finish: %w",
+				len(plan.Actions)-i, len(plan.Actions), err)
+		}
+		a := &plan.Actions[i]
+		err := applyFleetAction(ctx, client, a, state)
+		if err == nil {
+			err = utils.WriteFleetState(state)
+		}
+		if err != nil {
+			a.Error = err.Error()
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+			if !JSONOutput {
+				fmt.Fprintf(os.Stderr, "  ✗ %s %s: %v\n", a.Action, fleetActionLabel(a), err)
+			}
+			continue
+		}
+		if !JSONOutput {
+			fmt.Printf("  ✓ %s %s\n", fleetActionDone[a.Action], fleetActionLabel(a))
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case len(plan.Actions) == 1:
+		return firstErr
+	}
+	return fmt.Errorf("%d of %d changes failed: %w", failed, len(plan.Actions), firstErr)
+}
+
+var fleetActionDone = map[string]string{fleetCreate: "created", fleetModify: "modified", fleetDelete: "deleted"}
+
+func applyFleetAction(ctx context.Context, client *api.Client, a *fleetAction, state *utils.FleetState) error {
+	switch a.Action {
+	case fleetCreate:
+		resp, err := client.CreateInstanceCtx(ctx, *a.create)
+		if err != nil {
+			return fmt.Errorf("failed to create instance: %w", err)
+		}
+		a.InstanceID = fmt.Sprintf("%d", resp.Identifier)
+		state.Add(a.Entry, resp.UUID)
+	case fleetModify:
+		if _, err := client.ModifyInstanceCtx(ctx, a.instance.ID, *a.modify); err != nil {
+			return fmt.Errorf("failed to modify instance: %w", err)
+		}
+	case fleetDelete:
+		if _, err := client.DeleteInstanceCtx(ctx, a.instance.ID); err != nil {
+			return fmt.Errorf("failed to delete instance: %w", err)
+		}
+		state.Remove(a.Entry, a.instance.UUID)
+		if err := cleanupSSHConfig(a.instance.ID, a.instance.GetIP()); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to clean up SSH configuration: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func fleetActionLabel(a *fleetAction) string {
+	if a.InstanceID == "" {
+		return a.Entry
+	}
+	return fmt.Sprintf("%s (instance %s)", a.Entry, a.InstanceID)
+}
+
+// printFleetPlan lists a plan's actions, terraform-style.
+func printFleetPlan(plan *fleetPlan, priced bool) {
+	if len(plan.Actions) == 0 {
+		PrintSuccessSimple(fmt.Sprintf("Fleet %s is up to date", plan.Fleet))
+	} else {
+		fmt.Printf("Fleet %s: %d to create, %d to modify, %d to delete\n\n", plan.Fleet,
+			plan.count(fleetCreate), plan.count(fleetModify), plan.count(fleetDelete))
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		symbols := map[string]string{fleetCreate: "+", fleetModify: "~", fleetDelete: "-"}
+		for i := range plan.Actions {
+			a := &plan.Actions[i]
+			cost := ""
+			if priced {
+				cost = formatPriceDelta(a.HourlyDelta)
+			}
+			fmt.Fprintf(w, "  %s %s\t%s\t%s\n", symbols[a.Action], fleetActionLabel(a), strings.Join(a.Changes, ", "), cost)
+		}
+		_ = w.Flush()
+	}
+
+	if len(plan.Notes) > 0 {
+		fmt.Println()
+		for _, note := range plan.Notes {
+			PrintWarningSimple(note)
+		}
+	}
+	if priced && len(plan.Actions) > 0 {
+		fmt.Printf("\nEstimated cost: %s → %s (%s)\n", utils.FormatPrice(plan.HourlyBefore),
+			utils.FormatPrice(plan.HourlyAfter), formatPriceDelta(plan.HourlyAfter-plan.HourlyBefore))
+	}
+}
+
+// formatPriceDelta renders e.g. "+$1.38/hr" or "-$0.50/hr".
+func formatPriceDelta(delta float64) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	return sign + utils.FormatPrice(math.Abs(delta))
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -21,10 +20,48 @@ import (
 )
 
 var (
-	tunnelPorts []string
-	debugMode   bool
+	tunnelPorts    []string
+	localForwards  []string
+	remoteForwards []string
+	socksPort      int
+	reconnect      bool
+	sessionName    string
+	debugMode      bool
 )
 
+// sessionFlags controls how the interactive shell is run on the instance.
+type sessionFlags struct {
+	reconnect bool   // reattach to a tmux/screen session if the connection drops
+	name      string // named tmux session to create or attach to
+}
+
+// remoteCommand returns the command to run in the PTY, or "" for a login shell.
+func (s sessionFlags) remoteCommand() string {
+	switch {
+	case s.name != "":
+		return utils.TmuxSessionCommand(s.name)
+	case s.reconnect:
+		return utils.PersistentShellCommand(persistentSessionName)
+	default:
+		return ""
+	}
+}
+
+// reattachHint returns the connect invocation that gets back into this session.
+func (s sessionFlags) reattachHint(instanceID string) string {
+	hint := "tnr connect " + instanceID
+	if s.name != "" {
+		hint += " --session " + s.name
+	}
+	if s.reconnect {
+		hint += " --reconnect"
+	}
+	return hint
+}
+
+// persistentSessionName is the tmux/screen session used by --reconnect.
+const persistentSessionName = "tnr"
+
 // mocks for testing
 type connectOptions struct {
 	client        api.ConnectClient
@@ -39,11 +76,11 @@ type sshClient interface {
 	Close() error
 }
 
-func resolveConnectClient(opts *connectOptions, token, baseURL string) api.ConnectClient {
+func resolveConnectClient(opts *connectOptions, config *Config) api.ConnectClient {
 	if opts != nil && opts.client != nil {
 		return opts.client
 	}
-	return api.NewClient(token, baseURL)
+	return newAPIClient(config)
 }
 
 func resolveSessionRunner(opts *connectOptions) func(ctx context.Context, cfg utils.SessionConfig) error {
@@ -81,7 +118,8 @@ var connectCmd = &cobra.Command{
 		if len(args) > 0 {
 			instanceID = args[0]
 		}
-		return runConnect(instanceID, tunnelPorts, debugMode)
+		forwardArgs := forwardFlags{ports: tunnelPorts, local: localForwards, remote: remoteForwards, socks: socksPort}
+		return runConnect(instanceID, forwardArgs, sessionFlags{reconnect: reconnect, name: sessionName}, debugMode)
 	},
 }
 
@@ -90,26 +128,39 @@ func init() {
 
 	rootCmd.AddCommand(connectCmd)
 	connectCmd.Flags().StringSliceVarP(&tunnelPorts, "tunnel", "t", []string{}, "Port forwarding (can specify multiple times: -t 8080 -t 3000)")
+	connectCmd.Flags().StringArrayVarP(&localForwards, "local", "L", []string{}, "Local forward [bind_port:]host:port, reached from the instance (can specify multiple times)")
+	connectCmd.Flags().StringArrayVarP(&remoteForwards, "remote", "R", []string{}, "Reverse forward bind_port[:host:port] on the instance to this machine (can specify multiple times)")
+	connectCmd.Flags().IntVar(&socksPort, "socks", 0, "Run a local SOCKS5 proxy on this port that connects from the instance")
+	connectCmd.Flags().BoolVar(&reconnect, "reconnect", false, "Reconnect automatically if the connection drops, reattaching to a tmux/screen session")
+	connectCmd.Flags().StringVar(&sessionName, "session", "", "Create or attach to a named tmux session on the instance")
 	connectCmd.Flags().BoolVar(&debugMode, "debug", false, "Show detailed timing breakdown")
 	_ = connectCmd.Flags().MarkHidden("debug") //nolint:errcheck // flag hiding failure is non-fatal
 }
 
-func runConnect(instanceID string, tunnelPortsStr []string, debug bool) error {
-	return runConnectWithOptions(instanceID, tunnelPortsStr, debug, nil)
+func runConnect(instanceID string, forwardArgs forwardFlags, sessionArgs sessionFlags, debug bool) error {
+	return runConnectWithOptions(instanceID, forwardArgs, sessionArgs, debug, nil)
 }
 
 // runConnectWithOptions accepts options for testing. If opts is nil, default options are used.
-func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug bool, opts *connectOptions) error {
+func runConnectWithOptions(instanceID string, forwardArgs forwardFlags, sessionArgs sessionFlags, debug bool, opts *connectOptions) error {
 	sentry.AddBreadcrumb(&sentry.Breadcrumb{
 		Category: "connect",
 		Message:  "starting connection",
 		Data: map[string]interface{}{
 			"instance_id": instanceID,
-			"has_tunnels": len(tunnelPortsStr) > 0,
+			"has_tunnels": !forwardArgs.empty(),
+			"reconnect":   sessionArgs.reconnect,
+			"has_session": sessionArgs.name != "",
 		},
 		Level: sentry.LevelInfo,
 	})
 
+	if sessionArgs.name != "" {
+		if err := utils.ValidateSessionName(sessionArgs.name); err != nil {
+			return usageErr("%v", err)
+		}
+	}
+
 	configLoader := resolveConfigLoader(opts)
 	config, err := configLoader()
 	if err != nil {
@@ -123,7 +174,7 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 	skipTTYCheck := opts != nil && opts.skipTTYCheck
 	interactive := (skipTTYCheck || tui.IsInteractive()) && !JSONOutput
 
-	client := resolveConnectClient(opts, config.Token, config.APIURL)
+	client := resolveConnectClient(opts, config)
 
 	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
@@ -147,7 +198,7 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 	var instances []api.Instance
 	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
 		var e error
-		instances, e = client.ListInstances()
+		instances, e = client.ListInstancesCtx(ctx)
 		return e
 	}); err != nil {
 		if ctx.Err() != nil {
@@ -180,18 +231,9 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 			return err
 		}
 	} else {
-		foundInstance := findInstance(instances, instanceID)
-
-		if foundInstance == nil {
-			return usageErr("instance '%s' not found", instanceID)
-		}
-
-		if foundInstance.Status != "RUNNING" {
-			return usageErr("instance '%s' is not running (status: %s)", instanceID, foundInstance.Status)
-		}
-
-		if foundInstance.GetIP() == "" {
-			return usageErr("instance '%s' has no IP address", instanceID)
+		foundInstance, err := findRunningInstance(instances, instanceID)
+		if err != nil {
+			return err
 		}
 
 		instanceID = foundInstance.ID
@@ -218,13 +260,9 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 
 	phaseTimings := make(map[string]time.Duration)
 
-	var tunnelPorts []int
-	for _, portStr := range tunnelPortsStr {
-		port, err := strconv.Atoi(portStr)
-		if err != nil {
-			return usageErr("invalid port: %s", portStr)
-		}
-		tunnelPorts = append(tunnelPorts, port)
+	forwards, err := forwardArgs.parse()
+	if err != nil {
+		return err
 	}
 
 	// Non-interactive progress logging to stderr
@@ -376,7 +414,7 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 			SSHCommand string `json:"ssh_command"`
 		}
 		sshCmd := fmt.Sprintf("ssh -i %s root@%s -p %d", keyFile, instance.GetIP(), port)
-		printJSON(connectInfo{
+		printResult(connectInfo{
 			InstanceID: instanceID,
 			UUID:       instance.UUID,
 			Name:       instance.Name,
@@ -655,6 +693,24 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 		return fmt.Errorf("failed to set up token: %w", err)
 	}
 
+	// The idle watcher calls the API with the token just installed, so a
+	// pending or updated policy goes on now.
+	if policy, policyErr := utils.ReadIdlePolicy(instance.UUID); policyErr == nil && policy != nil {
+		if err := utils.InstallIdleWatcher(sshClient, policy, config.APIURL); err != nil {
+			sentry.AddBreadcrumb(&sentry.Breadcrumb{
+				Category: "connect",
+				Message:  "idle watcher install failed",
+				Data: map[string]interface{}{
+					"error": err.Error(),
+				},
+				Level: sentry.LevelWarning,
+			})
+		} else {
+			policy.InstalledAt = time.Now()
+			_ = utils.WriteIdlePolicy(policy)
+		}
+	}
+
 	if checkCancelled() {
 		return nil
 	}
@@ -663,8 +719,9 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 	tui.SendPhaseComplete(p, 2, phaseTimings["instance_setup"])
 
 	// Update SSH config for easy reconnection via `ssh tnr-{instance_id}`
+	// (tnr-{profile}-{instance_id} outside the default profile)
 	templatePorts := utils.GetTemplateOpenPorts(instance.Template)
-	if sshConfigErr := utils.UpdateSSHConfig(instanceID, instance.GetIP(), port, instance.UUID, tunnelPorts, templatePorts); sshConfigErr != nil {
+	if sshConfigErr := utils.UpdateSSHConfig(instanceID, instance.GetIP(), port, instance.UUID, forwards, templatePorts); sshConfigErr != nil {
 		sentry.AddBreadcrumb(&sentry.Breadcrumb{
 			Category: "connect",
 			Message:  "SSH config update failed",
@@ -681,7 +738,7 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 		Message:  "connection setup complete",
 		Data: map[string]interface{}{
 			"instance_id":   instanceID,
-			"tunnel_count":  len(tunnelPorts),
+			"tunnel_count":  len(forwards),
 			"total_time_ms": time.Since(phase3Start).Milliseconds(),
 		},
 		Level: sentry.LevelInfo,
@@ -713,24 +770,21 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 		}
 	}
 
-	allPorts := make(map[int]bool)
-	for _, p := range tunnelPorts {
-		allPorts[p] = true
-	}
-	for _, p := range templatePorts {
-		allPorts[p] = true
-	}
-	var portList []int
-	for p := range allPorts {
-		portList = append(portList, p)
-	}
-
 	sessionCfg := utils.SessionConfig{
-		Client: sshClient,
-		Ports:  portList,
-		Stdin:  os.Stdin,
-		Stdout: os.Stdout,
-		Stderr: os.Stderr,
+		Client:   sshClient,
+		Forwards: utils.WithTemplatePorts(forwards, templatePorts),
+		Stdin:    os.Stdin,
+		Stdout:   os.Stdout,
+		Stderr:   os.Stderr,
+	}
+	sessionCfg.Command = sessionArgs.remoteCommand()
+	if sessionArgs.reconnect {
+		ip := instance.GetIP()
+		sessionCfg.Reconnect = func(ctx context.Context) (*utils.SSHClient, error) {
+			return utils.RobustSSHConnectWithOptions(ctx, ip, keyFile, port, 120, nil, &utils.SSHConnectOptions{
+				DetectPersistentAuthFailure: true,
+			})
+		}
 	}
 
 	runner := resolveSessionRunner(opts)
@@ -740,6 +794,11 @@ func runConnectWithOptions(instanceID string, tunnelPortsStr []string, debug boo
 		sshClient.Close()
 	}
 
+	if errors.Is(err, utils.ErrReconnectAborted) {
+		PrintWarningSimple(fmt.Sprintf("Gave up reconnecting. Run '%s' to reattach.", sessionArgs.reattachHint(instance.ID)))
+		return nil
+	}
+
 	// Remote shell exit codes and connection drops are not connect errors.
 	if err != nil {
 		var exitErr *ssh.ExitError
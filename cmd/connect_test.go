@@ -43,7 +43,7 @@ type mockAPIClient struct {
 	mu sync.Mutex
 }
 
-func (m *mockAPIClient) ListInstances() ([]api.Instance, error) {
+func (m *mockAPIClient) ListInstancesCtx(ctx context.Context) ([]api.Instance, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.listInstancesCalled++
@@ -194,7 +194,7 @@ func TestRunConnect_InstanceNotFound(t *testing.T) {
 		configLoader: mockConfigLoader("test-token"),
 	}
 
-	err := runConnectWithOptions("nonexistent", []string{}, false, opts)
+	err := runConnectWithOptions("nonexistent", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 
@@ -220,7 +220,7 @@ func TestRunConnect_InstanceNotRunning(t *testing.T) {
 		configLoader: mockConfigLoader("test-token"),
 	}
 
-	err := runConnectWithOptions("inst-1", []string{}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not running")
 
@@ -244,7 +244,7 @@ func TestRunConnect_InstanceNoIP(t *testing.T) {
 		configLoader: mockConfigLoader("test-token"),
 	}
 
-	err := runConnectWithOptions("inst-1", []string{}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no IP address")
 
@@ -268,7 +268,7 @@ func TestRunConnect_NoInstances(t *testing.T) {
 
 	// When no instanceID is provided and no instances exist, should return nil (no error)
 	// but with empty instances list
-	err := runConnectWithOptions("", []string{}, false, opts)
+	err := runConnectWithOptions("", forwardFlags{}, sessionFlags{}, false, opts)
 	// Should not error but exit gracefully
 	assert.NoError(t, err)
 
@@ -292,7 +292,7 @@ func TestRunConnect_InvalidPort(t *testing.T) {
 		configLoader: mockConfigLoader("test-token"),
 	}
 
-	err := runConnectWithOptions("inst-1", []string{"not-a-port"}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{ports: []string{"not-a-port"}}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid port")
 
@@ -311,7 +311,7 @@ func TestRunConnect_NoAuthToken(t *testing.T) {
 		},
 	}
 
-	err := runConnectWithOptions("inst-1", []string{}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no authentication token")
 
@@ -330,7 +330,7 @@ func TestRunConnect_ConfigLoadError(t *testing.T) {
 		},
 	}
 
-	err := runConnectWithOptions("inst-1", []string{}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not authenticated")
 
@@ -352,7 +352,7 @@ func TestRunConnect_ListInstancesError(t *testing.T) {
 		configLoader: mockConfigLoader("test-token"),
 	}
 
-	err := runConnectWithOptions("inst-1", []string{}, false, opts)
+	err := runConnectWithOptions("inst-1", forwardFlags{}, sessionFlags{}, false, opts)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to list instances")
 
@@ -399,13 +399,13 @@ func TestMockAPIClient_ListInstances(t *testing.T) {
 
 	client := &mockAPIClient{instances: instances}
 
-	result, err := client.ListInstances()
+	result, err := client.ListInstancesCtx(context.Background())
 	require.NoError(t, err)
 	assert.Len(t, result, 2)
 	assert.Equal(t, 1, client.listInstancesCalled)
 
 	// Call again
-	_, err = client.ListInstances()
+	_, err = client.ListInstancesCtx(context.Background())
 	require.NoError(t, err)
 	assert.Equal(t, 2, client.listInstancesCalled)
 }
@@ -431,7 +431,7 @@ func TestMockAPIClient_Error(t *testing.T) {
 		listInstancesErr: fmt.Errorf("network error"),
 	}
 
-	_, err := client.ListInstances()
+	_, err := client.ListInstancesCtx(context.Background())
 	require.Error(t, err)
 	assert.Equal(t, "network error", err.Error())
 }
@@ -669,7 +669,7 @@ func TestConcurrentMockAPIClient(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, _ = client.ListInstances()
+			_, _ = client.ListInstancesCtx(context.Background())
 		}()
 	}
 	wg.Wait()
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var costSetBudget float64
+
+var costCmd = &cobra.Command{
+	Use:   "cost",
+	Short: "Show the current burn rate, month-to-date spend and budget",
+	Args:  wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("set-budget") {
+			return runSetBudget(costSetBudget)
+		}
+		return runCost()
+	},
+}
+
+func init() {
+	costCmd.SetHelpFunc(wrapHelp(helpmenus.RenderCostHelp))
+	costCmd.Flags().Float64Var(&costSetBudget, "set-budget", 0, "Set the monthly budget in USD (0 removes it)")
+	rootCmd.AddCommand(costCmd)
+}
+
+// instanceCost is one running instance's line of tnr cost.
+type instanceCost struct {
+	InstanceID  string  `json:"instance_id"`
+	Name        string  `json:"name"`
+	Config      string  `json:"config"`
+	HourlyRate  float64 `json:"hourly_rate"`
+	MonthToDate float64 `json:"month_to_date"`
+}
+
+// costReport is the output of tnr cost. Month-to-date spend is an estimate:
+// it assumes each running instance has run in its current configuration
+// since it was created or the month began, whichever is later.
+type costReport struct {
+	Instances      []instanceCost `json:"instances"`
+	HourlyRate     float64        `json:"hourly_rate"`
+	MonthlyRate    float64        `json:"monthly_rate"`
+	MonthToDate    float64        `json:"month_to_date"`
+	ProjectedMonth float64        `json:"projected_month"`
+	MonthlyBudget  float64        `json:"monthly_budget,omitempty"`
+}
+
+func buildCostReport(instances []api.Instance, pricing *utils.PricingData, specs *utils.SpecStore, now time.Time) *costReport {
+	report := &costReport{Instances: []instanceCost{}}
+	monthStart := utils.MonthStart(now)
+	for i := range instances {
+		inst := &instances[i]
+		if inst.Status != "RUNNING" {
+			continue
+		}
+		line := instanceCost{
+			InstanceID: inst.ID,
+			Name:       inst.Name,
+			Config:     fmt.Sprintf("%sx%s %s", inst.NumGPUs, utils.FormatGPUType(inst.GPUType), inst.Mode),
+			HourlyRate: utils.InstanceHourlyPrice(pricing, specs, inst),
+		}
+		if created, ok := utils.ParseCreatedAt(inst.CreatedAt); ok {
+			if created.Before(monthStart) {
+				created = monthStart
+			}
+			line.MonthToDate = line.HourlyRate * max(now.Sub(created).Hours(), 0)
+		}
+		report.Instances = append(report.Instances, line)
+		report.HourlyRate += line.HourlyRate
+		report.MonthToDate += line.MonthToDate
+	}
+	report.MonthlyRate = report.HourlyRate * utils.HoursPerMonth
+	report.ProjectedMonth = report.projectedSpend(0, now)
+	return report
+}
+
+// projectedSpend estimates this month's total if the burn rate changed by
+// hourlyDelta now and stayed there until the month ends.
+func (r *costReport) projectedSpend(hourlyDelta float64, now time.Time) float64 {
+	return r.MonthToDate + (r.HourlyRate+hourlyDelta)*utils.HoursLeftInMonth(now)
+}
+
+func fetchCostReport(client *api.Client) (*costReport, *utils.PricingData, *utils.SpecStore, error) {
+	var instances []api.Instance
+	var rates map[string]float64
+	var specsMap map[string]api.GpuSpecConfig
+	if err := tui.RunWithBusySpinner("Fetching instances and pricing...", os.Stdout, func() error {
+		var e error
+		if instances, e = client.ListInstances(); e != nil {
+			return fmt.Errorf("failed to fetch instances: %w", e)
+		}
+		if rates, e = client.FetchPricing(); e != nil {
+			return fmt.Errorf("failed to fetch pricing: %w", e)
+		}
+		if specsMap, e = client.GetSpecs(); e != nil {
+			return fmt.Errorf("failed to fetch GPU specs: %w", e)
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, nil, err
+	}
+	pricing, specs := &utils.PricingData{Rates: rates}, utils.NewSpecStore(specsMap)
+	return buildCostReport(instances, pricing, specs, time.Now()), pricing, specs, nil
+}
+
+func runCost() error {
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+	report, _, _, err := fetchCostReport(client)
+	if err != nil {
+		return err
+	}
+	report.MonthlyBudget = loadMonthlyBudget()
+
+	if JSONOutput {
+		printResult(report)
+		return nil
+	}
+
+	if len(report.Instances) == 0 {
+		fmt.Println("No running instances.")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tCONFIG\tRATE\tMONTH TO DATE")
+		for _, line := range report.Instances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t$%.2f\n", line.InstanceID, line.Name, line.Config, utils.FormatPrice(line.HourlyRate), line.MonthToDate)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Burn rate:              %s (~$%.2f/month)\n", utils.FormatPrice(report.HourlyRate), report.MonthlyRate)
+	fmt.Printf("Month to date (est.):   $%.2f\n", report.MonthToDate)
+	fmt.Printf("Projected this month:   $%.2f\n", report.ProjectedMonth)
+	if report.MonthlyBudget > 0 {
+		fmt.Printf("Monthly budget:         $%.2f (%.0f%% projected)\n", report.MonthlyBudget, 100*report.ProjectedMonth/report.MonthlyBudget)
+		if report.ProjectedMonth > report.MonthlyBudget {
+			PrintWarningSimple("Projected spend is over the monthly budget")
+		}
+	}
+	return nil
+}
+
+// checkBudget guards tnr create and modify: a change that raises the burn
+// rate and takes projected spend this month over the budget needs --yes, or
+// confirmation when interactive. If spend can't be fetched, interactive runs
+// go ahead with a warning, but scripts refuse unless --yes is passed.
+// replacing is the instance a modify reconfigures, and hourly prices the
+// configuration being asked for. It reports whether to go ahead.
+func checkBudget(client *api.Client, replacing *api.Instance, hourly func(*utils.PricingData, *utils.SpecStore) float64) (bool, error) {
+	budget := loadMonthlyBudget()
+	if budget <= 0 {
+		return true, nil
+	}
+	report, pricing, specs, err := fetchCostReport(client)
+	if err != nil {
+		if !YesFlag && (!tui.IsInteractive() || JSONOutput) {
+			return false, fmt.Errorf("could not check the monthly budget: %w. Use --yes to go ahead anyway", err)
+		}
+		printBudgetWarning(fmt.Sprintf("Could not check the monthly budget: %v", err))
+		return true, nil
+	}
+
+	delta := hourly(pricing, specs)
+	if replacing != nil && replacing.Status == "RUNNING" {
+		delta -= utils.InstanceHourlyPrice(pricing, specs, replacing)
+	}
+	now := time.Now()
+	projected := report.projectedSpend(delta, now)
+	if delta <= 0 || projected <= budget {
+		return true, nil
+	}
+
+	msg := fmt.Sprintf("This change (%s) brings projected spend this month to $%.2f, over your $%.2f budget",
+		formatPriceDelta(delta), projected, budget)
+	if YesFlag {
+		printBudgetWarning(msg)
+		return true, nil
+	}
+	if !tui.IsInteractive() || JSONOutput {
+		return false, usageErr("%s. Use --yes to go ahead anyway", msg)
+	}
+	PrintWarningSimple(msg)
+	fmt.Print("Continue anyway? (yes/no): ")
+	var confirmation string
+	fmt.Scanln(&confirmation)
+	if confirmation != "yes" && confirmation != "y" {
+		PrintWarningSimple("Cancelled: over budget")
+		return false, nil
+	}
+	return true, nil
+}
+
+func printBudgetWarning(msg string) {
+	if JSONOutput {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", msg)
+		return
+	}
+	PrintWarningSimple(msg)
+}
+
+// loadMonthlyBudget returns the active profile's budget, or 0 if none is
+// set. It is read from the file even when TNR_API_TOKEN supplies the token.
+func loadMonthlyBudget() float64 {
+	file, err := readConfigFile()
+	if err != nil {
+		return 0
+	}
+	if config := file.profile(activeProfile(file)); config != nil {
+		return config.MonthlyBudget
+	}
+	return 0
+}
+
+func runSetBudget(budget float64) error {
+	if budget < 0 {
+		return usageErr("--set-budget must not be negative")
+	}
+	err := updateConfigFile(func(file *configFile) error {
+		name := activeProfile(file)
+		config := &Config{}
+		if existing := file.profile(name); existing != nil {
+			config = existing
+		}
+		config.MonthlyBudget = budget
+		file.setProfile(name, config)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if JSONOutput {
+		printResult(map[string]float64{"monthly_budget": budget})
+	} else if budget == 0 {
+		PrintSuccessSimple("Monthly budget removed")
+	} else {
+		PrintSuccessSimple(fmt.Sprintf("Monthly budget set to $%.2f", budget))
+	}
+	return nil
+}
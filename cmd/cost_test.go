@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func TestBuildCostReport(t *testing.T) {
+	env := testFleetEnv()
+	now := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	running := fleetInstance("0", "u0", "RUNNING", "production", "h100", "1", "18", 100)
+	running.CreatedAt = "2026-02-20T00:00:00Z" // before the month: counted from March 1
+	recent := fleetInstance("1", "u1", "RUNNING", "prototyping", "a100", "1", "4", 100)
+	recent.CreatedAt = "2026-03-10T00:00:00Z"
+	unknown := fleetInstance("2", "u2", "RUNNING", "prototyping", "a6000", "1", "4", 100)
+	stopped := fleetInstance("3", "u3", "STOPPED", "production", "h100", "2", "36", 100)
+
+	report := buildCostReport([]api.Instance{running, recent, unknown, stopped}, env.pricing, env.specs, now)
+	require.Len(t, report.Instances, 3)
+	assert.InDelta(t, 2.00, report.Instances[0].HourlyRate, 1e-9)
+	assert.InDelta(t, 2.00*240, report.Instances[0].MonthToDate, 1e-9)
+	assert.InDelta(t, 0.80, report.Instances[1].HourlyRate, 1e-9)
+	assert.InDelta(t, 0.80*24, report.Instances[1].MonthToDate, 1e-9)
+	assert.Zero(t, report.Instances[2].MonthToDate, "no createdAt, no estimate")
+
+	assert.InDelta(t, 3.30, report.HourlyRate, 1e-9)
+	assert.InDelta(t, 3.30*utils.HoursPerMonth, report.MonthlyRate, 1e-9)
+	assert.InDelta(t, 480+19.2, report.MonthToDate, 1e-9)
+	// 21 days left in March.
+	assert.InDelta(t, 499.2+3.30*21*24, report.ProjectedMonth, 1e-9)
+	assert.InDelta(t, 499.2+4.30*21*24, report.projectedSpend(1, now), 1e-9)
+}
+
+func TestSetBudget(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	assert.Zero(t, loadMonthlyBudget())
+	require.NoError(t, runSetBudget(250))
+	assert.Equal(t, 250.0, loadMonthlyBudget())
+
+	// Logging in again keeps the budget.
+	require.NoError(t, saveConfig(AuthResponse{Token: "new_token"}))
+	assert.Equal(t, 250.0, loadMonthlyBudget())
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "new_token", config.Token)
+
+	require.NoError(t, runSetBudget(0))
+	assert.Zero(t, loadMonthlyBudget())
+	config, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "new_token", config.Token)
+
+	err = runSetBudget(-1)
+	assert.True(t, errors.Is(err, ErrUsage))
+}
+
+func TestCheckBudgetRefusesWhenSpendUnknown(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+	require.NoError(t, runSetBudget(100))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+	client := api.NewClient("token", ts.URL)
+	hourly := func(*utils.PricingData, *utils.SpecStore) float64 { return 1 }
+
+	origYes := YesFlag
+	t.Cleanup(func() { YesFlag = origYes })
+
+	// Tests run without a terminal, so this is the scripted case.
+	YesFlag = false
+	ok, err := checkBudget(client, nil, hourly)
+	require.Error(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, err.Error(), "could not check the monthly budget")
+
+	YesFlag = true
+	ok, err = checkBudget(client, nil, hourly)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
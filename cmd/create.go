@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"slices"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,6 +28,7 @@ var (
 	snapshotAlias   string
 	diskSizeGB      int
 	ephemeralDiskGB int
+	createWait      bool
 )
 
 var createCmd = &cobra.Command{
@@ -52,6 +54,8 @@ func init() {
 	createCmd.Flags().IntVar(&diskSizeGB, "disk-size-gb", 100, "Disk storage in GB (range depends on GPU config)")
 	_ = createCmd.Flags().MarkHidden("disk-size-gb")
 	createCmd.Flags().IntVar(&ephemeralDiskGB, "ephemeral-disk", 0, "Ephemeral storage in GB, mounted at /ephemeral (default: 0)")
+	createCmd.Flags().BoolVar(&createWait, "wait", false, "Wait until the instance is running")
+	addIdleFlags(createCmd, "Act on the instance once it has been idle this long, e.g. 2h")
 }
 
 func createInstanceCmd(client *api.Client, req api.CreateInstanceRequest, resp **api.CreateInstanceResponse) tea.Cmd {
@@ -151,6 +155,10 @@ func runCreate(cmd *cobra.Command) error {
 	if err := resolveTemplateAlias(cmd); err != nil {
 		return err
 	}
+	idle, err := parseIdleFlags(cmd, false)
+	if err != nil {
+		return err
+	}
 
 	client, err := getAuthenticatedClient()
 	if err != nil {
@@ -239,9 +247,7 @@ func runCreate(cmd *cobra.Command) error {
 		} else {
 			// Fully non-interactive succeeded
 			if pricing, pErr := client.FetchPricing(); pErr == nil {
-				pd := &utils.PricingData{Rates: pricing}
-				included := specs.IncludedVCPUs(createConfig.GPUType, createConfig.NumGPUs, createConfig.Mode)
-				price := utils.CalculateHourlyPrice(pd, createConfig.Mode, createConfig.GPUType, createConfig.NumGPUs, createConfig.VCPUs, createConfig.DiskSizeGB, createConfig.EphemeralDiskGB, included)
+				price := createHourlyPrice(&utils.PricingData{Rates: pricing}, specs, createConfig)
 				fmt.Printf("\nEstimated cost: %s\n", utils.FormatPrice(price))
 			}
 
@@ -267,6 +273,12 @@ func runCreate(cmd *cobra.Command) error {
 		}
 	}
 
+	if ok, err := checkBudget(client, nil, func(pd *utils.PricingData, specs *utils.SpecStore) float64 {
+		return createHourlyPrice(pd, specs, createConfig)
+	}); !ok {
+		return err
+	}
+
 	req := api.CreateInstanceRequest{
 		Mode:            api.InstanceMode(createConfig.Mode),
 		GPUType:         createConfig.GPUType,
@@ -278,6 +290,7 @@ func runCreate(cmd *cobra.Command) error {
 	}
 
 	var resp *api.CreateInstanceResponse
+	var inst *api.Instance
 
 	if !interactive {
 		// Non-interactive: direct API call without Bubble Tea
@@ -286,11 +299,17 @@ func runCreate(cmd *cobra.Command) error {
 		if err != nil {
 			return fmt.Errorf("failed to create instance: %w", err)
 		}
-		if JSONOutput {
-			printJSON(resp)
-		} else {
+		if !JSONOutput {
 			fmt.Printf("Instance created: ID=%d UUID=%s\n", resp.Identifier, resp.UUID)
 		}
+		if createWait {
+			if inst, err = waitWithSpinner(client, resp.UUID, waitRunning, defaultWaitTimeout, waitSettle); err != nil {
+				return err
+			}
+		}
+		if JSONOutput {
+			printResult(resp)
+		}
 	} else {
 		progressModel := tui.NewProgressModel("Creating instance...",
 			createInstanceCmd(client, req, &resp),
@@ -312,11 +331,29 @@ func runCreate(cmd *cobra.Command) error {
 		if result.Err() != nil {
 			return fmt.Errorf("failed to create instance: %w", result.Err())
 		}
+		if createWait {
+			if inst, err = waitWithSpinner(client, resp.UUID, waitRunning, defaultWaitTimeout, waitSettle); err != nil {
+				return err
+			}
+			PrintSuccessSimple(fmt.Sprintf("Instance %d is running", resp.Identifier))
+		}
 	}
 
+	if idle.set {
+		if inst == nil {
+			// Not up yet: the policy is installed by the first tnr connect.
+			inst = &api.Instance{ID: strconv.Itoa(resp.Identifier), UUID: resp.UUID}
+		}
+		return applyIdleFlags(client, inst, idle)
+	}
 	return nil
 }
 
+func createHourlyPrice(pd *utils.PricingData, specs *utils.SpecStore, config *tui.CreateConfig) float64 {
+	included := specs.IncludedVCPUs(config.GPUType, config.NumGPUs, config.Mode)
+	return utils.CalculateHourlyPrice(pd, config.Mode, config.GPUType, config.NumGPUs, config.VCPUs, config.DiskSizeGB, config.EphemeralDiskGB, included)
+}
+
 func validateCreateConfig(config *tui.CreateConfig, templates []api.TemplateEntry, snapshots []api.Snapshot, diskSizeWasSet bool, specs *utils.SpecStore) error {
 	config.Mode = strings.ToLower(config.Mode)
 	config.GPUType = strings.ToLower(config.GPUType)
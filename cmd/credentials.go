@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// credentialStores caches opened stores, so the encrypted store asks for
+// its passphrase at most once per run.
+var credentialStores = map[string]utils.CredentialStore{}
+
+// openCredentialStore returns the named store, or nil for plaintext, where
+// the credentials stay in cli_config.json. Tests replace it.
+var openCredentialStore = func(name string) (utils.CredentialStore, error) {
+	if store, ok := credentialStores[name]; ok {
+		return store, nil
+	}
+	var store utils.CredentialStore
+	switch name {
+	case "", utils.CredentialStorePlaintext:
+		return nil, nil
+	case utils.CredentialStoreKeychain:
+		keychain, err := utils.NewKeychainStore()
+		if err != nil {
+			return nil, err
+		}
+		store = keychain
+	case utils.CredentialStoreEncrypted:
+		path, err := utils.EncryptedCredentialsPath()
+		if err != nil {
+			return nil, err
+		}
+		store = utils.NewEncryptedFileStore(path, promptCredentialPassphrase)
+	default:
+		return nil, usageErr("unknown credential store '%s': use keychain, encrypted or plaintext", name)
+	}
+	credentialStores[name] = store
+	return store, nil
+}
+
+// credentialStoreName picks where a new login's credentials go:
+// --credential-store, then TNR_CREDENTIAL_STORE, then the OS keychain if
+// there is one. explicit is false for that last, automatic choice.
+func credentialStoreName() (name string, explicit bool) {
+	if loginCredentialStore != "" {
+		return loginCredentialStore, true
+	}
+	if env := os.Getenv("TNR_CREDENTIAL_STORE"); env != "" {
+		return env, true
+	}
+	if utils.KeychainAvailable() {
+		return utils.CredentialStoreKeychain, false
+	}
+	return utils.CredentialStorePlaintext, false
+}
+
+// promptCredentialPassphrase reads the encrypted store's passphrase from
+// TNR_CREDENTIAL_PASSPHRASE or, interactively, the terminal.
+func promptCredentialPassphrase(create bool) (string, error) {
+	if pass := os.Getenv("TNR_CREDENTIAL_PASSPHRASE"); pass != "" {
+		return pass, nil
+	}
+	if !tui.IsInteractive() || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", usageErr("the encrypted credential store needs a passphrase: set TNR_CREDENTIAL_PASSPHRASE")
+	}
+
+	prompt := "Credential store passphrase: "
+	if create {
+		prompt = "Choose a passphrase for the credential store: "
+	}
+	pass, err := readPassphrase(prompt)
+	if err != nil || !create {
+		return pass, err
+	}
+	confirm, err := readPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != pass {
+		return "", usageErr("passphrases do not match")
+	}
+	return pass, nil
+}
+
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(pass), nil
+}
+
+// storeCredentials moves config's token and refresh token into the chosen
+// credential store, recording which one in config. previous is the store
+// the profile used before, whose copy is removed.
+func storeCredentials(profile string, config *Config, previous string) error {
+	name, explicit := credentialStoreName()
+	store, err := openCredentialStore(name)
+	if err == nil && store != nil {
+		err = store.Set(profile, &utils.Credentials{Token: config.Token, RefreshToken: config.RefreshToken})
+	}
+	if err != nil {
+		if explicit {
+			return fmt.Errorf("failed to save credentials to the %s store: %w", name, err)
+		}
+		PrintWarningSimple(fmt.Sprintf("Could not use the OS keychain (%v); saving credentials in plaintext", err))
+		name, store = utils.CredentialStorePlaintext, nil
+	}
+
+	config.CredentialStore = ""
+	if store != nil {
+		config.CredentialStore = name
+		config.Token, config.RefreshToken = "", ""
+	}
+	if previous != "" && previous != config.CredentialStore {
+		forgetCredentials(profile, previous)
+	}
+	return nil
+}
+
+// resolveCredentials fills in config's token and refresh token from its
+// credential store, if it has one.
+func resolveCredentials(profile string, config *Config) error {
+	if config.CredentialStore == "" {
+		return nil
+	}
+	store, err := openCredentialStore(config.CredentialStore)
+	if err != nil {
+		return err
+	}
+	creds, err := store.Get(profile)
+	if errors.Is(err, utils.ErrCredentialsNotFound) {
+		return fmt.Errorf("credentials for profile '%s' are missing from the %s store", profile, config.CredentialStore)
+	}
+	if err != nil {
+		return err
+	}
+	config.Token, config.RefreshToken = creds.Token, creds.RefreshToken
+	return nil
+}
+
+// forgetCredentials removes a profile's credentials from a store. Failure
+// is only warned about: the profile is being removed either way.
+func forgetCredentials(profile, storeName string) {
+	store, err := openCredentialStore(storeName)
+	if err == nil && store != nil {
+		err = store.Delete(profile)
+	}
+	if err != nil {
+		PrintWarningSimple(fmt.Sprintf("Could not remove credentials from the %s store: %v", storeName, err))
+	}
+}
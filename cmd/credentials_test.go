@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// useMemoryCredentialStore makes the keychain an in-memory store.
+func useMemoryCredentialStore(t *testing.T) *utils.MemoryCredentialStore {
+	t.Helper()
+	store := utils.NewMemoryCredentialStore()
+	original := openCredentialStore
+	openCredentialStore = func(name string) (utils.CredentialStore, error) {
+		if name == utils.CredentialStoreKeychain {
+			return store, nil
+		}
+		return original(name)
+	}
+	t.Cleanup(func() { openCredentialStore = original })
+	return store
+}
+
+func TestSaveConfigUsesCredentialStore(t *testing.T) {
+	path := testProfileEnv(t)
+	store := useMemoryCredentialStore(t)
+	t.Setenv("TNR_CREDENTIAL_STORE", utils.CredentialStoreKeychain)
+
+	require.NoError(t, saveConfig(AuthResponse{Token: "secret-token", RefreshToken: "secret-refresh"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-")
+	assert.Contains(t, string(data), `"credential_store": "keychain"`)
+
+	creds, err := store.Get(defaultProfile)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", creds.Token)
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", config.Token)
+	assert.Equal(t, "secret-refresh", config.RefreshToken)
+
+	// Logging in again with plaintext takes the token out of the keychain.
+	t.Setenv("TNR_CREDENTIAL_STORE", utils.CredentialStorePlaintext)
+	require.NoError(t, saveConfig(AuthResponse{Token: "plain-token"}))
+	_, err = store.Get(defaultProfile)
+	assert.ErrorIs(t, err, utils.ErrCredentialsNotFound)
+	config, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "plain-token", config.Token)
+	assert.Empty(t, config.CredentialStore)
+}
+
+func TestLoadConfigMissingStoredCredentials(t *testing.T) {
+	testProfileEnv(t)
+	store := useMemoryCredentialStore(t)
+	t.Setenv("TNR_CREDENTIAL_STORE", utils.CredentialStoreKeychain)
+	require.NoError(t, saveConfig(AuthResponse{Token: "secret-token"}))
+
+	require.NoError(t, store.Delete(defaultProfile))
+	_, err := LoadConfig()
+	assert.ErrorContains(t, err, "missing from the keychain store")
+}
+
+func TestLogoutForgetsStoredCredentials(t *testing.T) {
+	testProfileEnv(t)
+	store := useMemoryCredentialStore(t)
+	t.Setenv("TNR_CREDENTIAL_STORE", utils.CredentialStoreKeychain)
+	ProfileFlag = "team"
+	require.NoError(t, saveConfig(AuthResponse{Token: "secret-token"}))
+
+	require.NoError(t, runLogout())
+	_, err := store.Get("team")
+	assert.ErrorIs(t, err, utils.ErrCredentialsNotFound)
+}
+
+func TestSaveConfigUnknownCredentialStore(t *testing.T) {
+	testProfileEnv(t)
+	t.Setenv("TNR_CREDENTIAL_STORE", "vault")
+	assert.ErrorIs(t, saveConfig(AuthResponse{Token: "t"}), ErrUsage)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/Thunder-Compute/thunder-cli/api"
 	"github.com/Thunder-Compute/thunder-cli/tui"
 	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
 // deleteCmd represents the delete command
@@ -104,7 +105,7 @@ func runDelete(args []string) error {
 			return fmt.Errorf("failed to delete instance: %w", deleteErr)
 		}
 		if JSONOutput {
-			printJSON(resp)
+			printResult(resp)
 		} else {
 			fmt.Printf("Deleted instance %s\n", instanceID)
 		}
@@ -169,7 +170,7 @@ func removeSSHHostEntry(configPath, instanceID string) error {
 		return err
 	}
 
-	hostName := fmt.Sprintf("tnr-%s", instanceID)
+	hostName := utils.SSHHostAlias(instanceID)
 	result := filterSSHHostBlock(string(data), hostName)
 	return os.WriteFile(configPath, []byte(result), 0o600)
 }
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/internal/mockapi"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var (
+	mockAPIAddr       string
+	mockAPIToken      string
+	mockAPILatency    time.Duration
+	mockAPIFailRate   float64
+	mockAPITransition time.Duration
+	mockAPISSH        bool
+	mockAPISSHAddr    string
+)
+
+// devCmd represents the dev parent command
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Tools for developing against Thunder Compute",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Show help when parent command is called without subcommand
+		_ = cmd.Help()
+	},
+}
+
+var devMockAPICmd = &cobra.Command{
+	Use:   "mock-api",
+	Short: "Run a local fake of the Thunder Compute API",
+	Args:  wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMockAPI()
+	},
+}
+
+func init() {
+	devCmd.SetHelpFunc(wrapHelp(helpmenus.RenderDevHelp))
+	devMockAPICmd.SetHelpFunc(wrapHelp(helpmenus.RenderDevHelp))
+
+	devMockAPICmd.Flags().StringVar(&mockAPIAddr, "addr", "127.0.0.1:8787", "Address to serve the API on")
+	devMockAPICmd.Flags().StringVar(&mockAPIToken, "token", "mock-token", "Bearer token the API accepts")
+	devMockAPICmd.Flags().DurationVar(&mockAPILatency, "latency", 0, "Delay added to every response")
+	devMockAPICmd.Flags().Float64Var(&mockAPIFailRate, "fail-rate", 0, "Fraction of requests, from 0 to 1, that fail with a 503")
+	devMockAPICmd.Flags().DurationVar(&mockAPITransition, "transition", 5*time.Second, "How long instances stay in statuses like PROVISIONING or STOPPING")
+	devMockAPICmd.Flags().BoolVar(&mockAPISSH, "ssh", false, "Also run an SSH server so connect, exec and scp work against mock instances")
+	devMockAPICmd.Flags().StringVar(&mockAPISSHAddr, "ssh-addr", "127.0.0.1:0", "Address to serve SSH on (with --ssh)")
+
+	devCmd.AddCommand(devMockAPICmd)
+	rootCmd.AddCommand(devCmd)
+}
+
+func runMockAPI() error {
+	if mockAPIFailRate < 0 || mockAPIFailRate > 1 {
+		return usageErr("--fail-rate must be between 0 and 1")
+	}
+	if mockAPIToken == "" {
+		return usageErr("--token cannot be empty")
+	}
+
+	server := mockapi.New(mockapi.Config{
+		Token:          mockAPIToken,
+		Latency:        mockAPILatency,
+		FailureRate:    mockAPIFailRate,
+		TransitionTime: mockAPITransition,
+	})
+
+	ln, err := net.Listen("tcp", mockAPIAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", mockAPIAddr, err)
+	}
+	httpServer := &http.Server{Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 2)
+	go func() { serveErr <- httpServer.Serve(ln) }()
+
+	if mockAPISSH {
+		stopSSH, err := startMockSSH(server, serveErr)
+		if err != nil {
+			_ = httpServer.Close()
+			return err
+		}
+		defer stopSSH()
+	}
+
+	fmt.Printf("Mock API listening on http://%s\n\n", ln.Addr())
+	fmt.Println("Point tnr at it with:")
+	fmt.Printf("  export TNR_API_URL=http://%s\n", ln.Addr())
+	fmt.Printf("  export TNR_API_TOKEN=%s\n\n", mockAPIToken)
+	fmt.Println("Press Ctrl+C to stop.")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("mock API stopped: %w", err)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = httpServer.Shutdown(shutdownCtx)
+	return nil
+}
+
+// startMockSSH serves SSH for the mock instances and points the API at it.
+// Instance files live in a scratch directory that the returned stop
+// function removes.
+func startMockSSH(server *mockapi.Server, serveErr chan<- error) (func(), error) {
+	base, err := utils.ThunderSubdir("mock-api")
+	if err != nil {
+		base = ""
+	}
+	root, err := os.MkdirTemp(base, "instances-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance directory: %w", err)
+	}
+
+	sshServer, err := mockapi.NewSSHServer(server, root)
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", mockAPISSHAddr)
+	if err != nil {
+		os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to listen on %s: %w", mockAPISSHAddr, err)
+	}
+	server.SetSSHPort(ln.Addr().(*net.TCPAddr).Port)
+
+	go func() {
+		if err := sshServer.Serve(ln); err != nil {
+			serveErr <- err
+		}
+	}()
+	fmt.Printf("Mock SSH listening on %s, instance files in %s\n", ln.Addr(), root)
+	return func() {
+		ln.Close()
+		os.RemoveAll(root)
+	}, nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/internal/mockapi"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+)
+
+// TestStopAgainstMockAPI runs `tnr stop --yes --wait` end to end against
+// the mock API.
+func TestStopAgainstMockAPI(t *testing.T) {
+	testProfileEnv(t)
+	server := mockapi.New(mockapi.Config{Token: "mock-token", TransitionTime: 50 * time.Millisecond})
+	id := server.AddInstance(api.Instance{})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	t.Setenv("TNR_API_URL", ts.URL)
+	t.Setenv("TNR_API_TOKEN", "mock-token")
+
+	origDelay := instancePollDelay
+	instancePollDelay = func(time.Duration) time.Duration { return 10 * time.Millisecond }
+	origYes, origWait := YesFlag, powerWait
+	YesFlag, powerWait = true, true
+	t.Cleanup(func() { instancePollDelay, YesFlag, powerWait = origDelay, origYes, origWait })
+
+	require.NoError(t, runPower(tui.StopAction, []string{id}))
+
+	client := api.NewClient("mock-token", ts.URL)
+	instances, err := client.ListInstances()
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "STOPPED", instances[0].Status)
+
+	err = runPower(tui.StopAction, []string{id})
+	assert.ErrorIs(t, err, ErrUsage)
+}
@@ -28,3 +28,13 @@ func wrapArgs(fn cobra.PositionalArgs) cobra.PositionalArgs {
 func usageErr(format string, args ...any) error {
 	return fmt.Errorf("%w: "+format, append([]any{ErrUsage}, args...)...)
 }
+
+// exitCodeError asks Execute to exit with a specific status without printing
+// anything, e.g. to propagate a remote command's exit status from tnr exec.
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	termx "github.com/charmbracelet/x/term"
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var (
+	execAllRunning bool
+	execParallel   int
+)
+
+var execCmd = &cobra.Command{
+	Use:          "exec <instance_id...> -- <command...>",
+	Short:        "Run a command on one or more Thunder Compute instances",
+	Args:         wrapArgs(cobra.MinimumNArgs(1)),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, command, err := splitExecArgs(args, cmd.ArgsLenAtDash(), execAllRunning)
+		if err != nil {
+			return err
+		}
+		return runExec(targets, command)
+	},
+}
+
+func init() {
+	execCmd.SetHelpFunc(wrapHelp(helpmenus.RenderExecHelp))
+
+	rootCmd.AddCommand(execCmd)
+	execCmd.Flags().BoolVar(&execAllRunning, "all-running", false, "Run the command on every running instance")
+	execCmd.Flags().IntVar(&execParallel, "parallel", 0, "Maximum number of instances to run on concurrently (default: all)")
+}
+
+// splitExecArgs separates instance identifiers from the remote command. With
+// `--`, everything before it is a target; without it, only the first argument
+// is (or none, with --all-running).
+func splitExecArgs(args []string, dash int, allRunning bool) ([]string, []string, error) {
+	var targets, command []string
+	switch {
+	case dash > 0:
+		targets, command = args[:dash], args[dash:]
+	case dash == 0:
+		command = args
+	case allRunning:
+		command = args
+	default:
+		targets, command = args[:1], args[1:]
+	}
+
+	if allRunning && len(targets) > 0 {
+		return nil, nil, usageErr("cannot combine instance IDs with --all-running")
+	}
+	if !allRunning && len(targets) == 0 {
+		return nil, nil, usageErr("no instance specified (use: tnr exec <instance_id> -- <command>)")
+	}
+	if len(command) == 0 {
+		return nil, nil, usageErr("no command specified (use: tnr exec <instance_id> -- <command>)")
+	}
+	return targets, command, nil
+}
+
+// mocks for testing
+type execOptions struct {
+	client       api.ConnectClient
+	configLoader func() (*Config, error)
+	dialer       func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error)
+	runner       func(ctx context.Context, client *utils.SSHClient, command string, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+	stdin        io.Reader
+	stdout       io.Writer
+	stderr       io.Writer
+	allRunning   bool
+	parallel     int
+}
+
+func defaultExecOptions() *execOptions {
+	var stdin io.Reader
+	// Only forward stdin when something is piped in; an attached terminal
+	// would otherwise hold the remote command open waiting for input.
+	if !termx.IsTerminal(os.Stdin.Fd()) {
+		stdin = os.Stdin
+	}
+	return &execOptions{
+		configLoader: LoadConfig,
+		dialer:       dialInstance,
+		runner:       utils.ExecuteSSHCommandStreaming,
+		stdin:        stdin,
+		stdout:       os.Stdout,
+		stderr:       os.Stderr,
+		allRunning:   execAllRunning,
+		parallel:     execParallel,
+	}
+}
+
+// execResult is the --json output of tnr exec for a single instance.
+type execResult struct {
+	InstanceID string `json:"instance_id"`
+	Name       string `json:"name,omitempty"`
+	Command    string `json:"command"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+
+	err error // underlying failure behind Error, kept for error classification
+}
+
+func (r execResult) failed() bool {
+	return r.Error != "" || r.ExitCode != 0
+}
+
+func runExec(targets, commandArgs []string) error {
+	return runExecWithOptions(targets, commandArgs, defaultExecOptions())
+}
+
+// runExecWithOptions runs a non-interactive command on the selected instances.
+// A single literal target streams output unprefixed and returns an
+// exitCodeError carrying the remote exit status; several targets (or a glob,
+// or --all-running) fan out concurrently and exit 1 if any host failed.
+func runExecWithOptions(targets, commandArgs []string, opts *execOptions) error {
+	// Arguments are joined with spaces and interpreted by the remote shell,
+	// the same way OpenSSH handles `ssh host cmd args...`.
+	command := strings.TrimSpace(strings.Join(commandArgs, " "))
+	if command == "" {
+		return usageErr("no command specified (use: tnr exec <instance_id> -- <command>)")
+	}
+
+	config, err := opts.configLoader()
+	if err != nil {
+		return usageErr("not authenticated. Please run 'tnr login' first")
+	}
+	if config.Token == "" {
+		return usageErr("no authentication token found. Please run 'tnr login'")
+	}
+
+	client := opts.client
+	if client == nil {
+		client = newAPIClient(config)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	txn := sentry.StartTransaction(ctx, "cli.exec",
+		sentry.WithOpName("cli.command"),
+	)
+	defer txn.Finish()
+	ctx = txn.Context()
+
+	instances, err := client.ListInstancesCtx(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	selected, err := findRunningInstances(instances, targets, opts.allRunning)
+	if err != nil {
+		return err
+	}
+
+	sentry.AddBreadcrumb(&sentry.Breadcrumb{
+		Category: "exec",
+		Message:  "running command",
+		Data: map[string]interface{}{
+			"instance_count": len(selected),
+		},
+		Level: sentry.LevelInfo,
+	})
+
+	fanOut := opts.allRunning || len(targets) != 1 || isInstancePattern(targets[0]) || strings.Contains(targets[0], ",")
+	if !fanOut {
+		return runExecSingle(ctx, client, selected[0], command, opts)
+	}
+	return runExecFanOut(ctx, client, selected, command, opts)
+}
+
+func runExecSingle(ctx context.Context, client api.ConnectClient, instance *api.Instance, command string, opts *execOptions) error {
+	stdout, stderr := opts.stdout, opts.stderr
+	var stdoutBuf, stderrBuf bytes.Buffer
+	if JSONOutput {
+		stdout, stderr = &stdoutBuf, &stderrBuf
+	}
+
+	result := runExecOnInstance(ctx, client, instance, command, opts, opts.stdin, stdout, stderr)
+	if ctx.Err() != nil {
+		return &exitCodeError{code: 130}
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	if JSONOutput {
+		result.Stdout = stdoutBuf.String()
+		result.Stderr = stderrBuf.String()
+		printResult(result)
+	}
+
+	if result.ExitCode != 0 {
+		return &exitCodeError{code: result.ExitCode}
+	}
+	return nil
+}
+
+func runExecFanOut(ctx context.Context, client api.ConnectClient, instances []*api.Instance, command string, opts *execOptions) error {
+	labelWidth := 0
+	for _, inst := range instances {
+		labelWidth = max(labelWidth, len(execLabel(inst)))
+	}
+
+	parallel := opts.parallel
+	if parallel <= 0 || parallel > len(instances) {
+		parallel = len(instances)
+	}
+	sem := make(chan struct{}, parallel)
+
+	results := make([]execResult, len(instances))
+	var outMu sync.Mutex
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		go func(i int, inst *api.Instance) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			var stdoutBuf, stderrBuf bytes.Buffer
+			var stdout, stderr io.Writer = &stdoutBuf, &stderrBuf
+			var stdoutPrefix, stderrPrefix *prefixWriter
+			if !JSONOutput {
+				prefix := fmt.Sprintf("[%-*s] ", labelWidth, execLabel(inst))
+				stdoutPrefix = &prefixWriter{mu: &outMu, out: opts.stdout, prefix: prefix}
+				stderrPrefix = &prefixWriter{mu: &outMu, out: opts.stderr, prefix: prefix}
+				stdout, stderr = stdoutPrefix, stderrPrefix
+			}
+
+			// Stdin cannot be shared between hosts, so fan-out never forwards it.
+			results[i] = runExecOnInstance(ctx, client, inst, command, opts, nil, stdout, stderr)
+
+			if JSONOutput {
+				results[i].Stdout = stdoutBuf.String()
+				results[i].Stderr = stderrBuf.String()
+			} else {
+				stdoutPrefix.Flush()
+				stderrPrefix.Flush()
+			}
+		}(i, inst)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return &exitCodeError{code: 130}
+	}
+
+	if JSONOutput {
+		printResult(results)
+	} else {
+		renderExecSummary(opts.stderr, results)
+	}
+
+	for _, r := range results {
+		if r.failed() {
+			return &exitCodeError{code: 1}
+		}
+	}
+	return nil
+}
+
+// runExecOnInstance connects to one instance and runs command, recording
+// connection and session failures in the result instead of returning them.
+func runExecOnInstance(ctx context.Context, client api.ConnectClient, instance *api.Instance, command string, opts *execOptions, stdin io.Reader, stdout, stderr io.Writer) execResult {
+	result := execResult{
+		InstanceID: instance.ID,
+		Name:       instance.Name,
+		Command:    command,
+		ExitCode:   -1,
+	}
+
+	sshClient, err := opts.dialer(ctx, client, instance)
+	if err != nil {
+		result.err = err
+		result.Error = err.Error()
+		return result
+	}
+	defer sshClient.Close()
+
+	start := time.Now()
+	exitCode, err := opts.runner(ctx, sshClient, command, stdin, stdout, stderr)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.err = fmt.Errorf("failed to run command on instance '%s': %w", instance.ID, err)
+		result.Error = result.err.Error()
+		return result
+	}
+	result.ExitCode = exitCode
+	return result
+}
+
+func execLabel(inst *api.Instance) string {
+	if inst.Name != "" {
+		return inst.Name
+	}
+	return inst.ID
+}
+
+// renderExecSummary prints a per-host result table after a fan-out run.
+func renderExecSummary(out io.Writer, results []execResult) {
+	succeeded := 0
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tEXIT\tDURATION\tRESULT")
+	for _, r := range results {
+		exit := fmt.Sprintf("%d", r.ExitCode)
+		outcome := "ok"
+		switch {
+		case r.Error != "":
+			exit = "-"
+			outcome = r.Error
+		case r.ExitCode != 0:
+			outcome = "failed"
+		default:
+			succeeded++
+		}
+		duration := (time.Duration(r.DurationMs) * time.Millisecond).Round(10 * time.Millisecond)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.InstanceID, r.Name, exit, duration, outcome)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d/%d instances succeeded\n", succeeded, len(results))
+}
+
+// prefixWriter prefixes every line written through it. Partial lines are
+// buffered until a newline (or Flush) so output from concurrent hosts never
+// interleaves mid-line; mu is shared by all writers targeting the same output.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes any trailing partial line.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s\n", w.prefix, line)
+}
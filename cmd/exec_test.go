@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func newTestExecOptions(client api.ConnectClient, exitCode int, runErr error) (*execOptions, *bytes.Buffer, *string) {
+	var stdout bytes.Buffer
+	var gotCommand string
+	opts := &execOptions{
+		client:       client,
+		configLoader: mockConfigLoader("test-token"),
+		dialer: func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error) {
+			return &utils.SSHClient{}, nil
+		},
+		runner: func(ctx context.Context, client *utils.SSHClient, command string, stdin io.Reader, out, errOut io.Writer) (int, error) {
+			gotCommand = command
+			_, _ = out.Write([]byte("hello\n"))
+			return exitCode, runErr
+		},
+		stdout: &stdout,
+		stderr: io.Discard,
+	}
+	return opts, &stdout, &gotCommand
+}
+
+func TestRunExec_Success(t *testing.T) {
+	mockClient := &mockAPIClient{
+		instances: []api.Instance{
+			createTestInstance("0", "uuid-0", "box", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+		},
+	}
+	opts, stdout, gotCommand := newTestExecOptions(mockClient, 0, nil)
+
+	err := runExecWithOptions([]string{"0"}, []string{"nvidia-smi", "-L"}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "nvidia-smi -L", *gotCommand)
+	assert.Equal(t, "hello\n", stdout.String())
+}
+
+func TestRunExec_PropagatesExitCode(t *testing.T) {
+	mockClient := &mockAPIClient{
+		instances: []api.Instance{
+			createTestInstance("0", "uuid-0", "box", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+		},
+	}
+	opts, _, _ := newTestExecOptions(mockClient, 42, nil)
+
+	err := runExecWithOptions([]string{"0"}, []string{"false"}, opts)
+	var exitErr *exitCodeError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 42, exitErr.code)
+}
+
+func TestRunExec_RunnerError(t *testing.T) {
+	mockClient := &mockAPIClient{
+		instances: []api.Instance{
+			createTestInstance("0", "uuid-0", "box", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+		},
+	}
+	opts, _, _ := newTestExecOptions(mockClient, -1, errors.New("connection lost"))
+
+	err := runExecWithOptions([]string{"0"}, []string{"uptime"}, opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection lost")
+}
+
+func TestRunExec_Validation(t *testing.T) {
+	mockClient := &mockAPIClient{
+		instances: []api.Instance{
+			createTestInstance("0", "uuid-0", "box", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+			createTestInstance("1", "uuid-1", "stopped", "10.0.0.2", "STOPPED", "base", "prototyping", 22),
+		},
+	}
+
+	tests := []struct {
+		name       string
+		instanceID string
+		args       []string
+		wantErr    string
+	}{
+		{name: "empty command", instanceID: "0", args: []string{"  "}, wantErr: "no command specified"},
+		{name: "unknown instance", instanceID: "9", args: []string{"true"}, wantErr: "not found"},
+		{name: "stopped instance", instanceID: "1", args: []string{"true"}, wantErr: "not running"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, _, _ := newTestExecOptions(mockClient, 0, nil)
+			err := runExecWithOptions([]string{tt.instanceID}, tt.args, opts)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrUsage))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSplitExecArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		dash        int
+		allRunning  bool
+		wantTargets []string
+		wantCommand []string
+		wantErr     string
+	}{
+		{name: "single target without dash", args: []string{"0", "uptime"}, dash: -1, wantTargets: []string{"0"}, wantCommand: []string{"uptime"}},
+		{name: "single target with dash", args: []string{"0", "ls", "-la"}, dash: 1, wantTargets: []string{"0"}, wantCommand: []string{"ls", "-la"}},
+		{name: "multiple targets", args: []string{"0", "1", "2", "nvidia-smi"}, dash: 3, wantTargets: []string{"0", "1", "2"}, wantCommand: []string{"nvidia-smi"}},
+		{name: "all running without dash", args: []string{"uptime"}, dash: -1, allRunning: true, wantCommand: []string{"uptime"}},
+		{name: "all running with dash", args: []string{"uptime"}, dash: 0, allRunning: true, wantCommand: []string{"uptime"}},
+		{name: "all running with targets", args: []string{"0", "uptime"}, dash: 1, allRunning: true, wantErr: "cannot combine"},
+		{name: "no targets", args: []string{"uptime"}, dash: 0, wantErr: "no instance specified"},
+		{name: "no command", args: []string{"0"}, dash: -1, wantErr: "no command specified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, command, err := splitExecArgs(tt.args, tt.dash, tt.allRunning)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTargets, targets)
+			assert.Equal(t, tt.wantCommand, command)
+		})
+	}
+}
+
+func TestFindRunningInstances(t *testing.T) {
+	instances := []api.Instance{
+		createTestInstance("0", "uuid-0", "sweep-a", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+		createTestInstance("1", "uuid-1", "sweep-b", "10.0.0.2", "RUNNING", "base", "prototyping", 22),
+		createTestInstance("2", "uuid-2", "sweep-c", "10.0.0.3", "STOPPED", "base", "prototyping", 22),
+		createTestInstance("3", "uuid-3", "eval", "10.0.0.4", "RUNNING", "base", "prototyping", 22),
+	}
+
+	ids := func(selected []*api.Instance) []string {
+		var out []string
+		for _, inst := range selected {
+			out = append(out, inst.ID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name        string
+		identifiers []string
+		allRunning  bool
+		want        []string
+		wantErr     string
+	}{
+		{name: "id list", identifiers: []string{"3", "0"}, want: []string{"0", "3"}},
+		{name: "comma separated", identifiers: []string{"0,eval"}, want: []string{"0", "3"}},
+		{name: "glob skips stopped", identifiers: []string{"sweep-*"}, want: []string{"0", "1"}},
+		{name: "deduplicated", identifiers: []string{"0", "sweep-a", "uuid-0"}, want: []string{"0"}},
+		{name: "all running", allRunning: true, want: []string{"0", "1", "3"}},
+		{name: "literal not running", identifiers: []string{"2"}, wantErr: "not running"},
+		{name: "glob without match", identifiers: []string{"prod-*"}, wantErr: "no running instances match"},
+		{name: "invalid glob", identifiers: []string{"[a"}, wantErr: "invalid instance pattern"},
+		{name: "nothing", identifiers: []string{" , "}, wantErr: "no instances specified"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := findRunningInstances(instances, tt.identifiers, tt.allRunning)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids(selected))
+		})
+	}
+}
+
+func TestRunExec_FanOut(t *testing.T) {
+	mockClient := &mockAPIClient{
+		instances: []api.Instance{
+			createTestInstance("0", "uuid-0", "sweep-a", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+			createTestInstance("1", "uuid-1", "sweep-b", "10.0.0.2", "RUNNING", "base", "prototyping", 22),
+			createTestInstance("2", "uuid-2", "sweep-c", "10.0.0.3", "RUNNING", "base", "prototyping", 22),
+		},
+	}
+
+	var mu sync.Mutex
+	var ran []string
+	var stdout, stderr bytes.Buffer
+	opts := &execOptions{
+		client:       mockClient,
+		configLoader: mockConfigLoader("test-token"),
+		dialer: func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error) {
+			if instance.ID == "2" {
+				return nil, errors.New("SSH service not available")
+			}
+			return &utils.SSHClient{}, nil
+		},
+		runner: func(ctx context.Context, client *utils.SSHClient, command string, stdin io.Reader, out, errOut io.Writer) (int, error) {
+			assert.Nil(t, stdin)
+			mu.Lock()
+			ran = append(ran, command)
+			mu.Unlock()
+			_, _ = out.Write([]byte("line one\nline "))
+			_, _ = out.Write([]byte("two"))
+			return 0, nil
+		},
+		stdin:    strings.NewReader("ignored"),
+		stdout:   &stdout,
+		stderr:   &stderr,
+		parallel: 2,
+	}
+
+	err := runExecWithOptions([]string{"sweep-*"}, []string{"hostname"}, opts)
+	var exitErr *exitCodeError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 1, exitErr.code)
+
+	assert.Len(t, ran, 2)
+	out := stdout.String()
+	assert.Contains(t, out, "[sweep-a] line one\n")
+	assert.Contains(t, out, "[sweep-a] line two\n")
+	assert.Contains(t, out, "[sweep-b] line one\n")
+	assert.Contains(t, stderr.String(), "SSH service not available")
+	assert.Contains(t, stderr.String(), "2/3 instances succeeded")
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	w := &prefixWriter{mu: &mu, out: &out, prefix: "[a] "}
+
+	_, _ = w.Write([]byte("partial"))
+	assert.Empty(t, out.String())
+	_, _ = w.Write([]byte(" line\nnext\n"))
+	assert.Equal(t, "[a] partial line\n[a] next\n", out.String())
+	_, _ = w.Write([]byte("tail"))
+	w.Flush()
+	assert.Equal(t, "[a] partial line\n[a] next\n[a] tail\n", out.String())
+}
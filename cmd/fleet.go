@@ -0,0 +1,529 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// FleetSpec describes the instances `tnr apply` converges the account to.
+// Spec files are YAML; JSON files parse as YAML too.
+type FleetSpec struct {
+	// Name identifies the fleet's instances (see utils.FleetInstanceName).
+	// It defaults to the spec file's base name.
+	Name      string       `yaml:"name"`
+	Instances []FleetEntry `yaml:"instances"`
+}
+
+// FleetEntry is a group of identically configured instances. Fields mirror
+// the `tnr create` flags.
+type FleetEntry struct {
+	Name          string `yaml:"name"`
+	Count         *int   `yaml:"count"` // default 1
+	Mode          string `yaml:"mode"`
+	GPU           string `yaml:"gpu"`
+	NumGPUs       int    `yaml:"num_gpus"`
+	VCPUs         int    `yaml:"vcpus"`
+	PrimaryDisk   int    `yaml:"primary_disk"`
+	EphemeralDisk int    `yaml:"ephemeral_disk"`
+	Template      string `yaml:"template"`
+	Snapshot      string `yaml:"snapshot"` // alias for template
+	// Ports lists forwarded HTTP ports and ranges. Omit it to leave ports
+	// alone; an empty list removes them all.
+	Ports []string `yaml:"ports"`
+}
+
+func (e FleetEntry) count() int {
+	if e.Count == nil {
+		return 1
+	}
+	return *e.Count
+}
+
+// loadFleetSpec reads and checks a spec file; "-" reads standard input.
+func loadFleetSpec(path string) (*FleetSpec, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, usageErr("fleet spec not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read fleet spec: %w", err)
+	}
+
+	spec, err := parseFleetSpec(data)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Name == "" && path != "-" {
+		spec.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if spec.Name == "" {
+		return nil, usageErr("fleet spec needs a name when read from standard input")
+	}
+	if !utils.ValidFleetName(spec.Name) {
+		return nil, usageErr("invalid fleet name %q: use letters, digits, '.', '_' and '-'", spec.Name)
+	}
+	return spec, nil
+}
+
+func parseFleetSpec(data []byte) (*FleetSpec, error) {
+	var spec FleetSpec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, usageErr("invalid fleet spec: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i, e := range spec.Instances {
+		switch {
+		case e.Name == "":
+			return nil, usageErr("instance entry %d has no name", i+1)
+		case !utils.ValidFleetName(e.Name):
+			return nil, usageErr("invalid instance entry name %q: use letters, digits, '.', '_' and '-'", e.Name)
+		case seen[e.Name]:
+			return nil, usageErr("duplicate instance entry %q", e.Name)
+		case e.count() < 0:
+			return nil, usageErr("%s: count cannot be negative", e.Name)
+		case e.Template != "" && e.Snapshot != "" && e.Template != e.Snapshot:
+			return nil, usageErr("%s: template and snapshot are aliases; use only one", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return &spec, nil
+}
+
+// fleetEnv is everything planning reads from the API.
+type fleetEnv struct {
+	instances []api.Instance
+	templates []api.TemplateEntry
+	snapshots []api.Snapshot
+	// specs validates configurations; capacity also knows current GPU
+	// availability, which only matters for instances about to be created.
+	specs    *utils.SpecStore
+	capacity *utils.SpecStore
+	pricing  *utils.PricingData // nil if pricing could not be fetched
+}
+
+// fleetConfig is one instance's configuration, normalized for comparison
+// and pricing.
+type fleetConfig struct {
+	Mode        string
+	GPU         string
+	NumGPUs     int
+	VCPUs       int
+	DiskGB      int
+	EphemeralGB int
+	Template    string
+	Ports       []int // nil when unmanaged
+}
+
+// desiredFleetConfig validates an entry the way `tnr create` validates its
+// flags.
+func desiredFleetConfig(e FleetEntry, env *fleetEnv) (fleetConfig, error) {
+	template := e.Template
+	if template == "" {
+		template = e.Snapshot
+	}
+	diskSizeWasSet := e.PrimaryDisk != 0
+	cfg := &tui.CreateConfig{
+		Mode:            e.Mode,
+		GPUType:         e.GPU,
+		NumGPUs:         e.NumGPUs,
+		VCPUs:           e.VCPUs,
+		Template:        template,
+		DiskSizeGB:      e.PrimaryDisk,
+		EphemeralDiskGB: e.EphemeralDisk,
+	}
+	if !diskSizeWasSet {
+		cfg.DiskSizeGB = 100
+	}
+	if err := validateCreateConfig(cfg, env.templates, env.snapshots, diskSizeWasSet, env.specs); err != nil {
+		return fleetConfig{}, fmt.Errorf("%s: %w", e.Name, err)
+	}
+	if cfg.EphemeralDiskGB < 0 {
+		return fleetConfig{}, usageErr("%s: ephemeral disk size cannot be negative", e.Name)
+	}
+
+	want := fleetConfig{
+		Mode:        cfg.Mode,
+		GPU:         cfg.GPUType,
+		NumGPUs:     cfg.NumGPUs,
+		VCPUs:       cfg.VCPUs,
+		DiskGB:      cfg.DiskSizeGB,
+		EphemeralGB: cfg.EphemeralDiskGB,
+		Template:    cfg.Template,
+	}
+	if e.Ports != nil {
+		ports, err := utils.ParsePorts(strings.Join(e.Ports, ","))
+		if err != nil {
+			return fleetConfig{}, usageErr("%s: %v", e.Name, err)
+		}
+		want.Ports = append([]int{}, ports...)
+		sort.Ints(want.Ports)
+	}
+	return want, nil
+}
+
+func currentFleetConfig(inst *api.Instance, specs *utils.SpecStore) fleetConfig {
+	cur := fleetConfig{
+		Mode:        strings.ToLower(inst.Mode),
+		NumGPUs:     1,
+		DiskGB:      inst.Storage,
+		EphemeralGB: inst.EphemeralDiskGB,
+		Template:    inst.Template,
+		Ports:       append([]int{}, inst.HTTPPorts...),
+	}
+	cur.GPU, _ = specs.NormalizeGPUType(inst.GPUType, cur.Mode)
+	if n, err := strconv.Atoi(inst.NumGPUs); err == nil {
+		cur.NumGPUs = n
+	}
+	if n, err := strconv.Atoi(inst.CPUCores); err == nil {
+		cur.VCPUs = n
+	}
+	sort.Ints(cur.Ports)
+	return cur
+}
+
+func (c fleetConfig) hourlyPrice(env *fleetEnv) float64 {
+	included := env.specs.IncludedVCPUs(c.GPU, c.NumGPUs, c.Mode)
+	return utils.CalculateHourlyPrice(env.pricing, c.Mode, c.GPU, c.NumGPUs, c.VCPUs, c.DiskGB, c.EphemeralGB, included)
+}
+
+// String renders e.g. "h100 x1 production, 18 vCPUs, 200 GB disk, pytorch".
+func (c fleetConfig) String() string {
+	s := fmt.Sprintf("%s x%d %s, %d vCPUs, %d GB disk", c.GPU, c.NumGPUs, c.Mode, c.VCPUs, c.DiskGB)
+	if c.EphemeralGB > 0 {
+		s += fmt.Sprintf(", %d GB ephemeral", c.EphemeralGB)
+	}
+	return s + ", " + c.Template
+}
+
+// Fleet plan actions.
+const (
+	fleetCreate = "create"
+	fleetModify = "modify"
+	fleetDelete = "delete"
+)
+
+// fleetAction is one API call of a plan.
+type fleetAction struct {
+	Action      string   `json:"action"`
+	Entry       string   `json:"entry"`
+	InstanceID  string   `json:"instance_id,omitempty"`
+	Changes     []string `json:"changes,omitempty"`
+	HourlyDelta float64  `json:"hourly_delta"`
+	Error       string   `json:"error,omitempty"` // set by a failed apply
+
+	instance *api.Instance
+	create   *api.CreateInstanceRequest
+	modify   *api.InstanceModifyRequest
+}
+
+// fleetPlan is the difference between a spec and the account.
+type fleetPlan struct {
+	Fleet        string        `json:"fleet"`
+	Actions      []fleetAction `json:"actions"`
+	Notes        []string      `json:"notes,omitempty"`
+	HourlyBefore float64       `json:"hourly_before"`
+	HourlyAfter  float64       `json:"hourly_after"`
+}
+
+func (p *fleetPlan) count(action string) int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// fleetMembers returns the live instances of each entry of a fleet: those
+// carrying a name apply gave them and those recorded in state. Recorded
+// instances without such a name come first, in the order they were
+// created, then named ones by number, so the newest are last.
+func fleetMembers(fleet string, state *utils.FleetState, instances []api.Instance) map[string][]*api.Instance {
+	members := make(map[string][]*api.Instance)
+	for entry, uuids := range state.Entries {
+		for _, uuid := range uuids {
+			for i := range instances {
+				inst := &instances[i]
+				if inst.UUID != uuid || inst.Status == "DELETING" {
+					continue
+				}
+				if _, _, named := utils.ParseFleetInstanceName(fleet, inst.Name); !named {
+					members[entry] = append(members[entry], inst)
+				}
+				break
+			}
+		}
+	}
+
+	named := make(map[string][]*api.Instance)
+	for i := range instances {
+		inst := &instances[i]
+		if entry, _, ok := utils.ParseFleetInstanceName(fleet, inst.Name); ok && inst.Status != "DELETING" {
+			named[entry] = append(named[entry], inst)
+		}
+	}
+	for entry, insts := range named {
+		sort.SliceStable(insts, func(i, j int) bool {
+			return fleetInstanceNumber(fleet, insts[i]) < fleetInstanceNumber(fleet, insts[j])
+		})
+		members[entry] = append(members[entry], insts...)
+	}
+	return members
+}
+
+// fleetInstanceNumber returns the number in an instance's fleet name, or -1
+// if it has none.
+func fleetInstanceNumber(fleet string, inst *api.Instance) int {
+	if _, n, ok := utils.ParseFleetInstanceName(fleet, inst.Name); ok {
+		return n
+	}
+	return -1
+}
+
+// pruneFleetState forgets recorded instances that no longer exist.
+func pruneFleetState(state *utils.FleetState, instances []api.Instance) {
+	live := make(map[string]bool, len(instances))
+	for _, inst := range instances {
+		if inst.Status != "DELETING" {
+			live[inst.UUID] = true
+		}
+	}
+	for entry, uuids := range state.Entries {
+		var kept []string
+		for _, uuid := range uuids {
+			if live[uuid] {
+				kept = append(kept, uuid)
+			}
+		}
+		if len(kept) == 0 {
+			delete(state.Entries, entry)
+		} else {
+			state.Entries[entry] = kept
+		}
+	}
+}
+
+// planFleet works out the creates, modifies and deletes that converge the
+// fleet's instances (see fleetMembers) to spec. Surplus instances are
+// deleted newest first; entries dropped from the spec lose all their
+// instances.
+func planFleet(spec *FleetSpec, state *utils.FleetState, env *fleetEnv) (*fleetPlan, error) {
+	plan := &fleetPlan{Fleet: spec.Name, Actions: []fleetAction{}}
+	members := fleetMembers(spec.Name, state, env.instances)
+
+	for _, e := range spec.Instances {
+		want, err := desiredFleetConfig(e, env)
+		if err != nil {
+			return nil, err
+		}
+		live := members[e.Name]
+		for _, inst := range live {
+			plan.HourlyBefore += currentFleetConfig(inst, env.specs).hourlyPrice(env)
+		}
+
+		for i := len(live) - 1; i >= e.count(); i-- {
+			plan.Actions = append(plan.Actions, deleteFleetAction(e.Name, live[i], env))
+		}
+		for _, inst := range live[:min(len(live), e.count())] {
+			action, result, notes, err := planFleetModify(e.Name, inst, want, env)
+			if err != nil {
+				return nil, err
+			}
+			plan.Notes = append(plan.Notes, notes...)
+			if action != nil {
+				plan.Actions = append(plan.Actions, *action)
+			}
+			plan.HourlyAfter += result.hourlyPrice(env)
+		}
+
+		if missing := e.count() - len(live); missing > 0 {
+			if !env.capacity.IsSpecAvailable(want.GPU, want.NumGPUs, want.Mode) {
+				return nil, usageErr("%s: GPU configuration %s x%d in %s mode is currently unavailable", e.Name, want.GPU, want.NumGPUs, want.Mode)
+			}
+			if len(want.Ports) > 0 {
+				plan.Notes = append(plan.Notes, fmt.Sprintf("%s: ports are forwarded once new instances are RUNNING; run tnr apply again then", e.Name))
+			}
+			price := want.hourlyPrice(env)
+			used := make(map[int]bool)
+			for _, inst := range live {
+				used[fleetInstanceNumber(spec.Name, inst)] = true
+			}
+			n := 0
+			for range missing {
+				for used[n] {
+					n++
+				}
+				used[n] = true
+				plan.Actions = append(plan.Actions, fleetAction{
+					Action:      fleetCreate,
+					Entry:       e.Name,
+					Changes:     []string{want.String()},
+					HourlyDelta: price,
+					create: &api.CreateInstanceRequest{
+						Mode:            api.InstanceMode(want.Mode),
+						GPUType:         want.GPU,
+						NumGPUs:         want.NumGPUs,
+						CPUCores:        want.VCPUs,
+						Template:        want.Template,
+						DiskSizeGB:      want.DiskGB,
+						EphemeralDiskGB: want.EphemeralGB,
+						Name:            utils.FleetInstanceName(spec.Name, e.Name, n),
+					},
+				})
+				plan.HourlyAfter += price
+			}
+		}
+	}
+
+	// Entries removed from the spec.
+	inSpec := make(map[string]bool)
+	for _, e := range spec.Instances {
+		inSpec[e.Name] = true
+	}
+	var dropped []string
+	for entry := range members {
+		if !inSpec[entry] {
+			dropped = append(dropped, entry)
+		}
+	}
+	sort.Strings(dropped)
+	for _, entry := range dropped {
+		for _, inst := range members[entry] {
+			action := deleteFleetAction(entry, inst, env)
+			plan.HourlyBefore -= action.HourlyDelta
+			plan.Actions = append(plan.Actions, action)
+		}
+	}
+	return plan, nil
+}
+
+func deleteFleetAction(entry string, inst *api.Instance, env *fleetEnv) fleetAction {
+	return fleetAction{
+		Action:      fleetDelete,
+		Entry:       entry,
+		InstanceID:  inst.ID,
+		HourlyDelta: -currentFleetConfig(inst, env.specs).hourlyPrice(env),
+		instance:    inst,
+	}
+}
+
+// planFleetModify compares a live instance with its entry, returning the
+// modify action (nil if none is needed or possible yet), the configuration
+// the instance ends up with, and notes on differences apply cannot fix.
+func planFleetModify(entry string, inst *api.Instance, want fleetConfig, env *fleetEnv) (*fleetAction, fleetConfig, []string, error) {
+	cur := currentFleetConfig(inst, env.specs)
+	result := cur
+	presets := &tui.ModifyPresets{}
+	var changes, notes []string
+	label := fmt.Sprintf("%s (instance %s)", entry, inst.ID)
+
+	modeChanged := cur.Mode != want.Mode
+	if modeChanged {
+		presets.Mode = &want.Mode
+		changes = append(changes, fmt.Sprintf("mode %s → %s", cur.Mode, want.Mode))
+	}
+	if cur.GPU != want.GPU {
+		presets.GPUType = &want.GPU
+		changes = append(changes, fmt.Sprintf("gpu %s → %s", cur.GPU, want.GPU))
+	}
+	if cur.NumGPUs != want.NumGPUs || (modeChanged && want.Mode == "production") {
+		presets.NumGPUs = &want.NumGPUs
+		if cur.NumGPUs != want.NumGPUs {
+			changes = append(changes, fmt.Sprintf("gpus %d → %d", cur.NumGPUs, want.NumGPUs))
+		}
+	}
+	if want.Mode == "prototyping" && (cur.VCPUs != want.VCPUs || modeChanged) {
+		presets.VCPUs = &want.VCPUs
+		if cur.VCPUs != want.VCPUs {
+			changes = append(changes, fmt.Sprintf("vcpus %d → %d", cur.VCPUs, want.VCPUs))
+		}
+	}
+	switch {
+	case want.DiskGB > cur.DiskGB:
+		presets.DiskSizeGB = &want.DiskGB
+		changes = append(changes, fmt.Sprintf("disk %d → %d GB", cur.DiskGB, want.DiskGB))
+	case want.DiskGB < cur.DiskGB:
+		notes = append(notes, fmt.Sprintf("%s: disk cannot shrink from %d to %d GB", label, cur.DiskGB, want.DiskGB))
+	}
+	if cur.EphemeralGB != want.EphemeralGB {
+		presets.EphemeralDiskGB = &want.EphemeralGB
+		changes = append(changes, fmt.Sprintf("ephemeral disk %d → %d GB", cur.EphemeralGB, want.EphemeralGB))
+	}
+	if cur.Template != "" && !strings.EqualFold(cur.Template, want.Template) {
+		notes = append(notes, fmt.Sprintf("%s: template is %s, not %s; delete the instance to recreate it", label, cur.Template, want.Template))
+	}
+
+	var addPorts, removePorts []int
+	if want.Ports != nil {
+		for _, p := range want.Ports {
+			if !slices.Contains(cur.Ports, p) {
+				addPorts = append(addPorts, p)
+			}
+		}
+		for _, p := range cur.Ports {
+			if !slices.Contains(want.Ports, p) {
+				removePorts = append(removePorts, p)
+			}
+		}
+		if len(addPorts) > 0 {
+			changes = append(changes, "forward ports "+utils.FormatPorts(addPorts))
+		}
+		if len(removePorts) > 0 {
+			changes = append(changes, "stop forwarding ports "+utils.FormatPorts(removePorts))
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, result, notes, nil
+	}
+	if inst.Status != "RUNNING" {
+		notes = append(notes, fmt.Sprintf("%s: is %s; run tnr apply again once it is RUNNING to %s", label, inst.Status, strings.Join(changes, ", ")))
+		return nil, result, notes, nil
+	}
+
+	req := api.InstanceModifyRequest{}
+	if !presets.IsEmpty() {
+		var err error
+		if req, err = validateAndBuildModifyRequest(presets, inst, env.specs); err != nil {
+			return nil, result, nil, fmt.Errorf("%s: %w", label, err)
+		}
+		result.Mode, result.GPU, result.NumGPUs, result.VCPUs = want.Mode, want.GPU, want.NumGPUs, want.VCPUs
+		result.DiskGB = max(cur.DiskGB, want.DiskGB)
+		result.EphemeralGB = want.EphemeralGB
+	}
+	req.AddPorts, req.RemovePorts = addPorts, removePorts
+
+	return &fleetAction{
+		Action:      fleetModify,
+		Entry:       entry,
+		InstanceID:  inst.ID,
+		Changes:     changes,
+		HourlyDelta: result.hourlyPrice(env) - cur.hourlyPrice(env),
+		instance:    inst,
+		modify:      &req,
+	}, result, notes, nil
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func TestParseFleetSpec(t *testing.T) {
+	spec, err := parseFleetSpec([]byte(`
+name: training
+instances:
+  - name: trainer
+    count: 2
+    mode: production
+    gpu: h100
+    num_gpus: 2
+    template: pytorch
+    ports: [8888, "9000-9002"]
+  - name: proto
+    mode: prototyping
+    gpu: a6000
+    vcpus: 8
+    template: base
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "training", spec.Name)
+	require.Len(t, spec.Instances, 2)
+	assert.Equal(t, 2, spec.Instances[0].count())
+	assert.Equal(t, []string{"8888", "9000-9002"}, spec.Instances[0].Ports)
+	assert.Equal(t, 1, spec.Instances[1].count())
+	assert.Nil(t, spec.Instances[1].Ports)
+
+	spec, err = parseFleetSpec([]byte(`{"instances": [{"name": "a", "mode": "production", "gpu": "a100", "template": "base", "ports": []}]}`))
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Instances[0].Ports)
+
+	for name, tc := range map[string]struct{ spec, want string }{
+		"unknown field": {"instances:\n  - name: a\n    gpus: 2\n", "field gpus not found"},
+		"no name":       {"instances:\n  - mode: production\n", "entry 1 has no name"},
+		"duplicate":     {"instances:\n  - name: a\n  - name: a\n", "duplicate instance entry"},
+		"negative":      {"instances:\n  - name: a\n    count: -1\n", "count cannot be negative"},
+		"alias clash":   {"instances:\n  - name: a\n    template: x\n    snapshot: y\n", "aliases"},
+		"bad name":      {"instances:\n  - name: ../a\n", "invalid instance entry name"},
+	} {
+		_, err := parseFleetSpec([]byte(tc.spec))
+		require.Error(t, err, name)
+		assert.True(t, errors.Is(err, ErrUsage), name)
+		assert.Contains(t, err.Error(), tc.want, name)
+	}
+}
+
+func testFleetEnv(instances ...api.Instance) *fleetEnv {
+	return &fleetEnv{
+		instances: instances,
+		templates: []api.TemplateEntry{tmplEntry("base", "Base"), tmplEntry("pytorch", "PyTorch")},
+		specs:     testSpecStore(),
+		capacity:  testSpecStore(),
+		pricing: &utils.PricingData{Rates: map[string]float64{
+			"h100_x1_production":    2.00,
+			"h100_x2_production":    4.00,
+			"a6000_x1_prototyping":  0.50,
+			"a100xl_x1_prototyping": 0.80,
+			"additional_vcpus":      0.10,
+			"disk_gb":               0.01,
+		}},
+	}
+}
+
+func fleetInstance(id, uuid, status, mode, gpu, numGPUs, vcpus string, disk int) api.Instance {
+	return api.Instance{ID: id, UUID: uuid, Status: status, Mode: mode, GPUType: gpu, NumGPUs: numGPUs,
+		CPUCores: vcpus, Storage: disk, Template: "base"}
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestPlanFleetCreatesMissingInstances(t *testing.T) {
+	spec := &FleetSpec{Name: "f", Instances: []FleetEntry{
+		{Name: "trainer", Count: intPtr(2), Mode: "production", GPU: "h100", NumGPUs: 1, Template: "pytorch", PrimaryDisk: 200},
+	}}
+	state := &utils.FleetState{Fleet: "f", Entries: map[string][]string{}}
+
+	plan, err := planFleet(spec, state, testFleetEnv())
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 2)
+	a := plan.Actions[0]
+	assert.Equal(t, fleetCreate, a.Action)
+	assert.Equal(t, "h100", a.create.GPUType)
+	assert.Equal(t, 18, a.create.CPUCores)
+	assert.Equal(t, 200, a.create.DiskSizeGB)
+	assert.Equal(t, "pytorch", a.create.Template)
+	assert.Equal(t, "f/trainer/0", a.create.Name)
+	assert.Equal(t, "f/trainer/1", plan.Actions[1].create.Name)
+	assert.InDelta(t, 3.00, a.HourlyDelta, 1e-9)
+	assert.InDelta(t, 0, plan.HourlyBefore, 1e-9)
+	assert.InDelta(t, 6.00, plan.HourlyAfter, 1e-9)
+}
+
+func TestPlanFleetValidatesLikeCreate(t *testing.T) {
+	spec := &FleetSpec{Name: "f", Instances: []FleetEntry{
+		{Name: "proto", Mode: "prototyping", GPU: "a6000", VCPUs: 6, Template: "base"},
+	}}
+	_, err := planFleet(spec, &utils.FleetState{Entries: map[string][]string{}}, testFleetEnv())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	assert.Contains(t, err.Error(), "vcpus must be one of [4 8] for a6000")
+}
+
+func TestPlanFleetConverges(t *testing.T) {
+	env := testFleetEnv(
+		fleetInstance("1", "u1", "RUNNING", "prototyping", "a6000", "1", "4", 100),
+		fleetInstance("2", "u2", "RUNNING", "production", "h100", "1", "18", 100),
+		fleetInstance("3", "u3", "RUNNING", "production", "h100", "1", "18", 100),
+		fleetInstance("4", "u4", "RUNNING", "prototyping", "a6000", "1", "4", 100),
+		fleetInstance("5", "u5", "RUNNING", "prototyping", "a6000", "1", "4", 100), // not recorded
+	)
+	env.instances[0].HTTPPorts = []int{8080}
+	state := &utils.FleetState{Fleet: "f", Entries: map[string][]string{
+		"proto":   {"u1", "gone"},
+		"trainer": {"u2", "u3"},
+		"old":     {"u4"},
+	}}
+	spec := &FleetSpec{Name: "f", Instances: []FleetEntry{
+		{Name: "proto", Mode: "prototyping", GPU: "a100", VCPUs: 8, PrimaryDisk: 200, Template: "base", Ports: []string{"8888"}},
+		{Name: "trainer", Count: intPtr(1), Mode: "production", GPU: "h100", NumGPUs: 1, Template: "base"},
+	}}
+
+	plan, err := planFleet(spec, state, env)
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 3)
+
+	modify := plan.Actions[0]
+	assert.Equal(t, fleetModify, modify.Action)
+	assert.Equal(t, "1", modify.InstanceID)
+	assert.Equal(t, []string{"gpu a6000 → a100xl", "vcpus 4 → 8", "disk 100 → 200 GB", "forward ports 8888", "stop forwarding ports 8080"}, modify.Changes)
+	assert.Equal(t, "a100xl", *modify.modify.GPUType)
+	assert.Equal(t, 8, *modify.modify.CPUCores)
+	assert.Equal(t, 200, *modify.modify.DiskSizeGB)
+	assert.Equal(t, []int{8888}, modify.modify.AddPorts)
+	assert.Equal(t, []int{8080}, modify.modify.RemovePorts)
+	assert.InDelta(t, 0.30+0.40+1.00, modify.HourlyDelta, 1e-9)
+
+	assert.Equal(t, fleetDelete, plan.Actions[1].Action)
+	assert.Equal(t, "3", plan.Actions[1].InstanceID, "surplus instances go newest first")
+	assert.Equal(t, fleetDelete, plan.Actions[2].Action)
+	assert.Equal(t, "old", plan.Actions[2].Entry)
+	assert.Equal(t, "4", plan.Actions[2].InstanceID)
+
+	assert.InDelta(t, 0.50+2+2+0.50, plan.HourlyBefore, 1e-9)
+	assert.InDelta(t, 0.80+0.40+1.00+2, plan.HourlyAfter, 1e-9)
+
+	pruneFleetState(state, env.instances)
+	assert.Equal(t, []string{"u1"}, state.Entries["proto"])
+}
+
+// TestPlanFleetFindsInstancesByName checks that apply recognizes its
+// instances without recorded state, as when run from another machine.
+func TestPlanFleetFindsInstancesByName(t *testing.T) {
+	named := func(inst api.Instance, name string) api.Instance {
+		inst.Name = name
+		return inst
+	}
+	env := testFleetEnv(
+		named(fleetInstance("1", "u1", "RUNNING", "production", "h100", "1", "18", 100), "f/trainer/0"),
+		named(fleetInstance("2", "u2", "RUNNING", "production", "h100", "1", "18", 100), "f/trainer/2"),
+		named(fleetInstance("3", "u3", "RUNNING", "prototyping", "a6000", "1", "4", 100), "f/old/0"),
+		named(fleetInstance("4", "u4", "RUNNING", "production", "h100", "1", "18", 100), "other/trainer/0"),
+		named(fleetInstance("5", "u5", "RUNNING", "production", "h100", "1", "18", 100), "instance-5"),
+	)
+	spec := &FleetSpec{Name: "f", Instances: []FleetEntry{
+		{Name: "trainer", Count: intPtr(3), Mode: "production", GPU: "h100", NumGPUs: 1, Template: "base"},
+	}}
+
+	plan, err := planFleet(spec, &utils.FleetState{Fleet: "f", Entries: map[string][]string{}}, env)
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, fleetCreate, plan.Actions[0].Action)
+	assert.Equal(t, "f/trainer/1", plan.Actions[0].create.Name, "new instances take the first free number")
+	assert.Equal(t, fleetDelete, plan.Actions[1].Action)
+	assert.Equal(t, "old", plan.Actions[1].Entry)
+	assert.Equal(t, "3", plan.Actions[1].InstanceID)
+
+	spec.Instances[0].Count = intPtr(1)
+	plan, err = planFleet(spec, &utils.FleetState{Fleet: "f", Entries: map[string][]string{}}, env)
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, "2", plan.Actions[0].InstanceID, "surplus instances go highest number first")
+	assert.Equal(t, "3", plan.Actions[1].InstanceID)
+}
+
+func TestPlanFleetNotesWhatItCannotChange(t *testing.T) {
+	env := testFleetEnv(
+		fleetInstance("1", "u1", "RUNNING", "production", "h100", "1", "18", 300),
+		fleetInstance("2", "u2", "STARTING", "production", "h100", "1", "18", 100),
+	)
+	state := &utils.FleetState{Fleet: "f", Entries: map[string][]string{"a": {"u1"}, "b": {"u2"}}}
+	spec := &FleetSpec{Name: "f", Instances: []FleetEntry{
+		{Name: "a", Mode: "production", GPU: "h100", Template: "pytorch", PrimaryDisk: 200},
+		{Name: "b", Mode: "production", GPU: "h100", NumGPUs: 2, Template: "base"},
+	}}
+
+	plan, err := planFleet(spec, state, env)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Actions)
+	require.Len(t, plan.Notes, 3)
+	assert.Contains(t, plan.Notes[0], "disk cannot shrink from 300 to 200 GB")
+	assert.Contains(t, plan.Notes[1], "template is base, not pytorch")
+	assert.Contains(t, plan.Notes[2], "is STARTING; run tnr apply again once it is RUNNING to gpus 1 → 2")
+}
+
+func TestApplyFleetPlanStopsWhenInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	plan := &fleetPlan{Fleet: "f", Actions: []fleetAction{{Action: fleetCreate, Entry: "a"}, {Action: fleetCreate, Entry: "b"}}}
+
+	// The client is never used once the context is done.
+	err := applyFleetPlan(ctx, nil, plan, &utils.FleetState{Fleet: "f", Entries: map[string][]string{}})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Contains(t, err.Error(), "2 of 2 changes left")
+	for _, a := range plan.Actions {
+		assert.Equal(t, "skipped: apply was interrupted", a.Error)
+	}
+}
+
+func TestFormatPriceDelta(t *testing.T) {
+	assert.Equal(t, "+$1.38/hr", formatPriceDelta(1.38))
+	assert.Equal(t, "-$0.50/hr", formatPriceDelta(-0.5))
+}
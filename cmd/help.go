@@ -95,7 +95,7 @@ func printJSONHelp(cmd *cobra.Command) {
 		})
 	})
 
-	printJSON(h)
+	printResult(h)
 }
 
 // printDefaultHelp renders Cobra's built-in plain-text help by temporarily
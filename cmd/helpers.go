@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"path"
+	"strings"
+
 	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
 func findInstance(instances []api.Instance, identifier string) *api.Instance {
@@ -13,13 +17,168 @@ func findInstance(instances []api.Instance, identifier string) *api.Instance {
 	return nil
 }
 
+// findRunningInstance resolves identifier and checks that the instance can
+// accept SSH connections (running with an assigned IP).
+func findRunningInstance(instances []api.Instance, identifier string) (*api.Instance, error) {
+	instance := findInstance(instances, identifier)
+	if instance == nil {
+		return nil, usageErr("instance '%s' not found", identifier)
+	}
+	if instance.Status != "RUNNING" {
+		return nil, usageErr("instance '%s' is not running (status: %s)", identifier, instance.Status)
+	}
+	if instance.GetIP() == "" {
+		return nil, usageErr("instance '%s' has no IP address", identifier)
+	}
+	return instance, nil
+}
+
+// isInstancePattern reports whether identifier is a glob (e.g. "train-*")
+// rather than a literal instance ID, UUID or name.
+func isInstancePattern(identifier string) bool {
+	return strings.ContainsAny(identifier, "*?[")
+}
+
+// findRunningInstances resolves several identifiers to running instances.
+// Each identifier may be a comma-separated list of IDs, UUIDs or names, or a
+// glob matched against instance names and IDs. Literal identifiers must name
+// a running instance; globs silently skip instances that are not running but
+// must match at least one that is. With allRunning every running instance is
+// selected. Results are de-duplicated and keep the API's ID order.
+func findRunningInstances(instances []api.Instance, identifiers []string, allRunning bool) ([]*api.Instance, error) {
+	selected := make(map[string]bool)
+	isRunning := func(inst *api.Instance) bool {
+		return inst.Status == "RUNNING" && inst.GetIP() != ""
+	}
+
+	if allRunning {
+		for i := range instances {
+			if isRunning(&instances[i]) {
+				selected[instances[i].ID] = true
+			}
+		}
+		if len(selected) == 0 {
+			return nil, usageErr("no running instances found")
+		}
+	}
+
+	for _, raw := range identifiers {
+		for _, identifier := range strings.Split(raw, ",") {
+			identifier = strings.TrimSpace(identifier)
+			if identifier == "" {
+				continue
+			}
+			if !isInstancePattern(identifier) {
+				inst, err := findRunningInstance(instances, identifier)
+				if err != nil {
+					return nil, err
+				}
+				selected[inst.ID] = true
+				continue
+			}
+			matched := false
+			for i := range instances {
+				nameMatch, err := path.Match(identifier, instances[i].Name)
+				if err != nil {
+					return nil, usageErr("invalid instance pattern '%s': %v", identifier, err)
+				}
+				idMatch, _ := path.Match(identifier, instances[i].ID)
+				if (nameMatch || idMatch) && isRunning(&instances[i]) {
+					selected[instances[i].ID] = true
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, usageErr("no running instances match '%s'", identifier)
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, usageErr("no instances specified")
+	}
+
+	var result []*api.Instance
+	for i := range instances {
+		if selected[instances[i].ID] {
+			result = append(result, &instances[i])
+		}
+	}
+	return result, nil
+}
+
 func getAuthenticatedClient() (*api.Client, error) {
 	config, err := LoadConfig()
 	if err != nil {
-		return nil, usageErr("not authenticated. Please run 'tnr login' first")
+		return nil, usageErr("not authenticated. Please run '%s' first", loginHint())
 	}
 	if config.Token == "" {
-		return nil, usageErr("no authentication token found. Please run 'tnr login'")
+		return nil, usageErr("no authentication token found. Please run '%s'", loginHint())
+	}
+	return newAPIClient(config), nil
+}
+
+// forwardFlags holds the raw port-forward flag values shared by connect and
+// tunnel start.
+type forwardFlags struct {
+	ports  []string // -t/-p: ports or ranges forwarded to the same port, or -L specs
+	local  []string // -L: bindPort[:host]:port
+	remote []string // -R: bindPort[:host]:port
+	socks  int      // --socks: local SOCKS5 proxy port, 0 for none
+}
+
+func (f forwardFlags) empty() bool {
+	return len(f.ports) == 0 && len(f.local) == 0 && len(f.remote) == 0 && f.socks == 0
+}
+
+// parse validates every flag value and merges them into a single forward list.
+func (f forwardFlags) parse() ([]utils.ForwardSpec, error) {
+	var forwards []utils.ForwardSpec
+	for _, value := range f.ports {
+		for _, token := range strings.Split(value, ",") {
+			if strings.Contains(token, ":") {
+				spec, err := utils.ParseForwardSpec(utils.ForwardLocal, token)
+				if err != nil {
+					return nil, usageErr("%v", err)
+				}
+				forwards = append(forwards, spec)
+				continue
+			}
+			ports, err := utils.ParsePorts(token)
+			if err != nil {
+				return nil, usageErr("%v", err)
+			}
+			for _, p := range ports {
+				forwards = append(forwards, utils.LocalForward(p))
+			}
+		}
+	}
+	for _, group := range []struct {
+		direction utils.ForwardDirection
+		values    []string
+	}{
+		{utils.ForwardLocal, f.local},
+		{utils.ForwardRemote, f.remote},
+	} {
+		for _, value := range group.values {
+			spec, err := utils.ParseForwardSpec(group.direction, value)
+			if err != nil {
+				return nil, usageErr("%v", err)
+			}
+			forwards = append(forwards, spec)
+		}
+	}
+
+	if f.socks != 0 {
+		if f.socks < 1 || f.socks > 65535 {
+			return nil, usageErr("invalid SOCKS port %d", f.socks)
+		}
+		forwards = append(forwards, utils.DynamicForward(f.socks))
+	}
+
+	merged, err := utils.MergeForwards(forwards)
+	if err != nil {
+		return nil, usageErr("%v", err)
 	}
-	return api.NewClient(config.Token, config.APIURL), nil
+	return merged, nil
 }
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var idleCmd = &cobra.Command{
+	Use:   "idle [instance_id]",
+	Short: "Show idle auto-shutdown policies",
+	Args:  wrapArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return runIdleList()
+		}
+		return runIdleShow(args[0])
+	},
+}
+
+func init() {
+	idleCmd.SetHelpFunc(wrapHelp(helpmenus.RenderIdleHelp))
+	rootCmd.AddCommand(idleCmd)
+}
+
+// addIdleFlags registers --idle-timeout and --idle-action on create and modify.
+func addIdleFlags(cmd *cobra.Command, timeoutUsage string) {
+	cmd.Flags().Duration("idle-timeout", 0, timeoutUsage)
+	cmd.Flags().String("idle-action", string(utils.IdleActionStop), "What to do once idle: stop or snapshot-and-delete")
+}
+
+// idleFlags is a validated --idle-timeout/--idle-action pair.
+type idleFlags struct {
+	set     bool
+	timeout time.Duration // 0 removes the policy
+	action  utils.IdleAction
+}
+
+// parseIdleFlags validates the idle flags. A zero timeout turns the policy
+// off, which only makes sense for an existing instance.
+func parseIdleFlags(cmd *cobra.Command, allowOff bool) (idleFlags, error) {
+	timeoutSet := cmd.Flags().Changed("idle-timeout")
+	if !timeoutSet {
+		if cmd.Flags().Changed("idle-action") {
+			return idleFlags{}, usageErr("--idle-action requires --idle-timeout")
+		}
+		return idleFlags{}, nil
+	}
+	timeout, _ := cmd.Flags().GetDuration("idle-timeout")
+	rawAction, _ := cmd.Flags().GetString("idle-action")
+	action, err := utils.ParseIdleAction(rawAction)
+	if err != nil {
+		return idleFlags{}, usageErr("%v", err)
+	}
+	switch {
+	case timeout == 0 && allowOff:
+	case timeout < utils.MinIdleTimeout:
+		if allowOff {
+			return idleFlags{}, usageErr("--idle-timeout must be at least %s, or 0 to remove the policy", utils.MinIdleTimeout)
+		}
+		return idleFlags{}, usageErr("--idle-timeout must be at least %s", utils.MinIdleTimeout)
+	}
+	return idleFlags{set: true, timeout: timeout, action: action}, nil
+}
+
+func (f idleFlags) policy(instanceID, instanceUUID string) *utils.IdlePolicy {
+	return &utils.IdlePolicy{
+		InstanceID:     instanceID,
+		InstanceUUID:   instanceUUID,
+		TimeoutSeconds: int64(f.timeout / time.Second),
+		Action:         f.action,
+	}
+}
+
+func describeIdlePolicy(policy *utils.IdlePolicy) string {
+	return fmt.Sprintf("%s after %s idle", policy.Action, policy.Timeout())
+}
+
+// applyIdleFlags records the policy the flags describe and, when the
+// instance is running, installs or removes its watcher right away. An
+// instance that can't be reached picks the policy up on the next tnr connect.
+func applyIdleFlags(client api.ConnectClient, instance *api.Instance, flags idleFlags) error {
+	if flags.timeout == 0 {
+		existing, err := utils.ReadIdlePolicy(instance.UUID)
+		if err != nil {
+			return err
+		}
+		if existing != nil && !existing.InstalledAt.IsZero() {
+			// The watcher lives on the instance's disk, so it has to be
+			// removed from there or it would act on the next boot.
+			if instance.Status != "RUNNING" {
+				return usageErr("instance '%s' must be RUNNING to remove its idle watcher (status: %s)", instance.ID, instance.Status)
+			}
+			if err := withIdleSSH(client, instance, utils.UninstallIdleWatcher); err != nil {
+				return err
+			}
+		}
+		if err := utils.RemoveIdlePolicy(instance.UUID); err != nil {
+			return err
+		}
+		if !JSONOutput {
+			PrintSuccessSimple(fmt.Sprintf("Removed the idle policy from instance %s", instance.ID))
+		}
+		return nil
+	}
+
+	policy := flags.policy(instance.ID, instance.UUID)
+	var installErr error
+	if instance.Status == "RUNNING" {
+		if installErr = installIdlePolicy(client, instance, policy); installErr == nil {
+			policy.InstalledAt = time.Now()
+		}
+	}
+	if err := utils.WriteIdlePolicy(policy); err != nil {
+		return err
+	}
+	switch {
+	case JSONOutput:
+	case installErr != nil:
+		PrintWarningSimple(fmt.Sprintf("Saved the idle policy for instance %s but could not install it (%v); it is installed the next time you run 'tnr connect %s'",
+			instance.ID, installErr, instance.ID))
+	case policy.InstalledAt.IsZero():
+		PrintSuccessSimple(fmt.Sprintf("Instance %s will %s once you run 'tnr connect %s'", instance.ID, describeIdlePolicy(policy), instance.ID))
+	default:
+		PrintSuccessSimple(fmt.Sprintf("Instance %s will %s", instance.ID, describeIdlePolicy(policy)))
+	}
+	return nil
+}
+
+// installIdlePolicy puts policy's watcher on a running instance, along with
+// the token it needs to call the API.
+func installIdlePolicy(client api.ConnectClient, instance *api.Instance, policy *utils.IdlePolicy) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return usageErr("not authenticated. Please run 'tnr login' first")
+	}
+	return withIdleSSH(client, instance, func(sshClient *utils.SSHClient) error {
+		if err := utils.SetupToken(sshClient, config.Token); err != nil {
+			return fmt.Errorf("failed to set up token: %w", err)
+		}
+		return utils.InstallIdleWatcher(sshClient, policy, config.APIURL)
+	})
+}
+
+func withIdleSSH(client api.ConnectClient, instance *api.Instance, fn func(*utils.SSHClient) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return tui.RunWithBusySpinner("Connecting...", os.Stdout, func() error {
+		sshClient, err := dialInstance(ctx, client, instance)
+		if err != nil {
+			return err
+		}
+		defer sshClient.Close()
+		return fn(sshClient)
+	})
+}
+
+func runIdleList() error {
+	policies, err := utils.ListIdlePolicies()
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printList(policies, idleColumns)
+	}
+	if len(policies) == 0 {
+		PrintWarningSimple("No idle policies. Set one with 'tnr create --idle-timeout 2h' or 'tnr modify <instance_id> --idle-timeout 2h'.")
+		return nil
+	}
+	return printList(policies, idleColumns)
+}
+
+// idleColumns are the columns of tnr idle list in table, wide and csv output.
+var idleColumns = []column[*utils.IdlePolicy]{
+	{name: "INSTANCE", value: func(p *utils.IdlePolicy) string { return p.InstanceID }},
+	{name: "UUID", wide: true, value: func(p *utils.IdlePolicy) string { return p.InstanceUUID }},
+	{name: "TIMEOUT", value: func(p *utils.IdlePolicy) string { return p.Timeout().String() }},
+	{name: "ACTION", value: func(p *utils.IdlePolicy) string { return string(p.Action) }},
+	{name: "INSTALLED", value: func(p *utils.IdlePolicy) string {
+		if p.InstalledAt.IsZero() {
+			return "pending connect"
+		}
+		return p.InstalledAt.Local().Format(time.DateTime)
+	}},
+}
+
+// idleReport is the --json output of tnr idle <instance_id>.
+type idleReport struct {
+	Policy           *utils.IdlePolicy        `json:"policy"`
+	Status           string                   `json:"status"`
+	Watcher          *utils.IdleWatcherStatus `json:"watcher,omitempty"`
+	IdleSeconds      *float64                 `json:"idle_seconds,omitempty"`
+	RemainingSeconds *float64                 `json:"remaining_seconds,omitempty"`
+}
+
+func runIdleShow(identifier string) error {
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	var instances []api.Instance
+	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		instances, e = client.ListInstances()
+		return e
+	}); err != nil {
+		return fmt.Errorf("failed to fetch instances: %w", err)
+	}
+	instance := findInstance(instances, identifier)
+	if instance == nil {
+		return usageErr("instance '%s' not found", identifier)
+	}
+
+	policy, err := utils.ReadIdlePolicy(instance.UUID)
+	if err != nil {
+		return err
+	}
+	report := idleReport{Policy: policy, Status: instance.Status}
+	if policy != nil && instance.Status == "RUNNING" {
+		err := withIdleSSH(client, instance, func(sshClient *utils.SSHClient) error {
+			var e error
+			report.Watcher, e = utils.ReadIdleWatcherStatus(sshClient)
+			return e
+		})
+		if err != nil {
+			return err
+		}
+		if report.Watcher.Installed {
+			tracker := report.Watcher.Tracker(policy.Timeout(), time.Now)
+			idle, remaining := tracker.IdleFor().Seconds(), tracker.Remaining().Seconds()
+			report.IdleSeconds, report.RemainingSeconds = &idle, &remaining
+		}
+	}
+
+	if JSONOutput {
+		printResult(report)
+		return nil
+	}
+	if policy == nil {
+		PrintWarningSimple(fmt.Sprintf("Instance %s has no idle policy. Set one with 'tnr modify %s --idle-timeout 2h'.", instance.ID, instance.ID))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Instance:\t%s (%s)\n", instance.ID, instance.Status)
+	fmt.Fprintf(w, "Policy:\t%s\n", describeIdlePolicy(policy))
+	switch {
+	case report.Watcher == nil:
+		fmt.Fprintf(w, "Watcher:\tnot checked; the instance is not running\n")
+	case !report.Watcher.Installed:
+		fmt.Fprintf(w, "Watcher:\tnot installed; run 'tnr connect %s' to install it\n", instance.ID)
+	default:
+		fmt.Fprintf(w, "Watcher:\tinstalled\n")
+		gpu := "unknown"
+		if report.Watcher.GPUUtilization >= 0 {
+			gpu = fmt.Sprintf("%d%%", report.Watcher.GPUUtilization)
+		}
+		fmt.Fprintf(w, "GPU utilisation:\t%s\n", gpu)
+		fmt.Fprintf(w, "Other SSH sessions:\t%d\n", report.Watcher.SSHSessions)
+		if report.Watcher.LastActive.IsZero() {
+			fmt.Fprintf(w, "Idle for:\tnot yet measured\n")
+		} else {
+			idle := time.Duration(*report.IdleSeconds * float64(time.Second)).Round(time.Minute)
+			remaining := time.Duration(*report.RemainingSeconds * float64(time.Second)).Round(time.Minute)
+			fmt.Fprintf(w, "Idle for:\t%s (%s in %s)\n", idle, policy.Action, remaining)
+		}
+		if report.Watcher.Acted != "" {
+			fmt.Fprintf(w, "Last action:\t%s\n", report.Watcher.Acted)
+		}
+		if report.Watcher.APIError != "" {
+			fmt.Fprintf(w, "Last API error:\t%s (%s)\n", report.Watcher.APIError, report.Watcher.APIErrorAt.Local().Format(time.DateTime))
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if report.Watcher != nil && report.Watcher.APIError != "" {
+		// The watcher calls the API with the token saved by the last
+		// connect, which stops working once it expires.
+		PrintWarningSimple(fmt.Sprintf("The watcher cannot reach the API, so the policy will not act. Run 'tnr connect %s' to renew its token.", instance.ID))
+	}
+	return nil
+}
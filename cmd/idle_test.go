@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func idleTestCmd(flags map[string]string) *cobra.Command {
+	cmd := &cobra.Command{Use: "modify"}
+	addIdleFlags(cmd, "")
+	for k, v := range flags {
+		_ = cmd.Flags().Set(k, v)
+	}
+	return cmd
+}
+
+func TestParseIdleFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    map[string]string
+		allowOff bool
+		want     idleFlags
+		wantErr  string
+	}{
+		{name: "unset", flags: nil},
+		{name: "timeout defaults to stop", flags: map[string]string{"idle-timeout": "2h"},
+			want: idleFlags{set: true, timeout: 2 * time.Hour, action: utils.IdleActionStop}},
+		{name: "snapshot and delete", flags: map[string]string{"idle-timeout": "30m", "idle-action": "snapshot-and-delete"},
+			want: idleFlags{set: true, timeout: 30 * time.Minute, action: utils.IdleActionSnapshotAndDelete}},
+		{name: "off on modify", flags: map[string]string{"idle-timeout": "0"}, allowOff: true,
+			want: idleFlags{set: true, action: utils.IdleActionStop}},
+		{name: "off on create", flags: map[string]string{"idle-timeout": "0"}, wantErr: "--idle-timeout must be at least 10m0s"},
+		{name: "too short", flags: map[string]string{"idle-timeout": "5m"}, allowOff: true, wantErr: "or 0 to remove the policy"},
+		{name: "action without timeout", flags: map[string]string{"idle-action": "stop"}, wantErr: "--idle-action requires --idle-timeout"},
+		{name: "unknown action", flags: map[string]string{"idle-timeout": "1h", "idle-action": "delete"}, wantErr: "idle action must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIdleFlags(idleTestCmd(tt.flags), tt.allowOff)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.True(t, errors.Is(err, ErrUsage))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestApplyIdleFlagsWhileNotRunning(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+	client := &mockAPIClient{}
+	inst := &api.Instance{ID: "4", UUID: "uuid-4", Status: "STOPPED"}
+
+	flags := idleFlags{set: true, timeout: 2 * time.Hour, action: utils.IdleActionSnapshotAndDelete}
+	require.NoError(t, applyIdleFlags(client, inst, flags))
+
+	policy, err := utils.ReadIdlePolicy("uuid-4")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, "4", policy.InstanceID)
+	assert.Equal(t, int64(7200), policy.TimeoutSeconds)
+	assert.Equal(t, utils.IdleActionSnapshotAndDelete, policy.Action)
+	assert.True(t, policy.InstalledAt.IsZero(), "a stopped instance is installed on the next connect")
+
+	// A policy that never reached the instance can be dropped locally.
+	require.NoError(t, applyIdleFlags(client, inst, idleFlags{set: true}))
+	policy, err = utils.ReadIdlePolicy("uuid-4")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	// One that did can only be removed from the running instance.
+	require.NoError(t, utils.WriteIdlePolicy(&utils.IdlePolicy{
+		InstanceID: "4", InstanceUUID: "uuid-4", TimeoutSeconds: 3600, Action: utils.IdleActionStop, InstalledAt: time.Now(),
+	}))
+	err = applyIdleFlags(client, inst, idleFlags{set: true})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	policy, err = utils.ReadIdlePolicy("uuid-4")
+	require.NoError(t, err)
+	assert.NotNil(t, policy)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// instancePort returns the SSH port for an instance, defaulting to 22.
+func instancePort(instance *api.Instance) int {
+	if instance.Port == 0 {
+		return 22
+	}
+	return instance.Port
+}
+
+// dialInstance opens an SSH connection to a running instance without any TUI.
+// It follows the same key-management strategy as runConnectWithOptions: a
+// missing local key is requested from the API, an existing key is tried with
+// persistent auth failure detection, and a rejected key is regenerated once.
+func dialInstance(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error) {
+	ip := instance.GetIP()
+	port := instancePort(instance)
+	keyFile := utils.GetKeyFile(instance.UUID)
+
+	newKeyCreated := false
+	if !utils.KeyExists(instance.UUID) {
+		keyResp, err := client.AddSSHKeyCtx(ctx, instance.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add SSH key: %w", err)
+		}
+		if keyResp.Key != nil {
+			if err := utils.SavePrivateKey(instance.UUID, *keyResp.Key); err != nil {
+				return nil, fmt.Errorf("failed to save private key: %w", err)
+			}
+		}
+		newKeyCreated = true
+	}
+
+	if err := utils.WaitForTCPPort(ctx, ip, port, 120*time.Second); err != nil {
+		return nil, fmt.Errorf("SSH service not available: %w", err)
+	}
+
+	var sshClient *utils.SSHClient
+	var err error
+	if newKeyCreated {
+		sshClient, err = utils.RobustSSHConnectWithProgress(ctx, ip, keyFile, port, 120, nil)
+	} else {
+		sshClient, err = utils.RobustSSHConnectWithOptions(ctx, ip, keyFile, port, 60, nil, &utils.SSHConnectOptions{
+			DetectPersistentAuthFailure: true,
+		})
+	}
+	if err == nil {
+		return sshClient, nil
+	}
+
+	needsKeyRegeneration := !newKeyCreated && (errors.Is(err, utils.ErrPersistentAuthFailure) || utils.IsAuthError(err) || utils.IsKeyParseError(err))
+	if !needsKeyRegeneration {
+		return nil, fmt.Errorf("failed to establish SSH connection: %w", err)
+	}
+
+	sentry.AddBreadcrumb(&sentry.Breadcrumb{
+		Category: "ssh",
+		Message:  "SSH auth failed, regenerating key",
+		Data: map[string]interface{}{
+			"instance_id": instance.ID,
+			"error":       err.Error(),
+		},
+		Level: sentry.LevelWarning,
+	})
+
+	keyResp, err := client.AddSSHKeyCtx(ctx, instance.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate new SSH key: %w", err)
+	}
+	if keyResp.Key == nil {
+		return nil, fmt.Errorf("server did not return a new SSH key — try restarting the instance with 'tnr delete %s' and 'tnr create'", instance.ID)
+	}
+	if err := utils.SavePrivateKey(instance.UUID, *keyResp.Key); err != nil {
+		return nil, fmt.Errorf("failed to save new private key: %w", err)
+	}
+
+	sshClient, err = utils.RobustSSHConnectWithOptions(ctx, ip, keyFile, port, 120, nil, &utils.SSHConnectOptions{
+		DetectPersistentAuthFailure: true,
+		PersistentAuthTimeout:       30 * time.Second,
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrPersistentAuthFailure) {
+			return nil, fmt.Errorf("SSH key regeneration succeeded but the instance still rejects connections. "+
+				"The instance may need to be restarted — try 'tnr delete %s' and 'tnr create'", instance.ID)
+		}
+		return nil, fmt.Errorf("failed to establish SSH connection after key regeneration: %w", err)
+	}
+	return sshClient, nil
+}
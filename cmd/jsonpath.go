@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed --output jsonpath expression. It supports the subset
+// of JSONPath that covers picking fields out of tnr's output:
+//
+//	$.name or .name    a field; $ and the leading dot are optional
+//	['name']           a field whose name has dots or spaces
+//	[2], [-1]          an element of a list, counting from the end if negative
+//	[*] or .*          every element of a list or value of an object
+//	..name             the field at any depth
+//
+// kubectl-style braces around the expression, as in {.items[*].id}, are
+// accepted too.
+type jsonPath []jsonPathStep
+
+type jsonPathStep struct {
+	// name is the field to pick, or "*" for every child.
+	name      string
+	index     int
+	isIndex   bool
+	recursive bool
+}
+
+func parseJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return nil, errors.New("empty expression")
+	}
+
+	var path jsonPath
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := splitJSONPathName(s[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after '..' in %q", expr)
+			}
+			path = append(path, jsonPathStep{name: name, recursive: true})
+			s = rest
+		case s[0] == '.':
+			name, rest := splitJSONPathName(s[1:])
+			s = rest
+			if name != "" {
+				path = append(path, jsonPathStep{name: name})
+				continue
+			}
+			// A bare dot may stand for the root, as in {.}, or come before a
+			// bracket, as in {.[*].id}.
+			if !(rest == "" && len(path) == 0) && !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("missing field name after '.' in %q", expr)
+			}
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' in %q", expr)
+			}
+			step, err := parseJSONPathBracket(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, step)
+			s = s[end+1:]
+		default:
+			// A leading field without a dot, as in "id" or "[0].id" after $.
+			if len(path) == 0 {
+				name, rest := splitJSONPathName(s)
+				path = append(path, jsonPathStep{name: name})
+				s = rest
+				continue
+			}
+			return nil, fmt.Errorf("unexpected %q in %q", s, expr)
+		}
+	}
+	return path, nil
+}
+
+// splitJSONPathName splits a field name off the front of s.
+func splitJSONPathName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+func parseJSONPathBracket(inner string) (jsonPathStep, error) {
+	if inner == "*" {
+		return jsonPathStep{name: "*"}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return jsonPathStep{name: inner[1 : len(inner)-1]}, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return jsonPathStep{}, fmt.Errorf("invalid subscript [%s]", inner)
+	}
+	return jsonPathStep{index: n, isIndex: true}, nil
+}
+
+// eval returns every value the path selects in root. Steps that do not
+// match, such as a missing field, select nothing.
+func (p jsonPath) eval(root any) []any {
+	current := []any{root}
+	for _, step := range p {
+		var next []any
+		for _, v := range current {
+			if step.recursive {
+				next = append(next, descendants(v, step.name)...)
+			} else {
+				next = append(next, step.apply(v)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func (step jsonPathStep) apply(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		if step.name == "*" {
+			return v
+		}
+		if step.isIndex {
+			i := step.index
+			if i < 0 {
+				i += len(v)
+			}
+			if i >= 0 && i < len(v) {
+				return []any{v[i]}
+			}
+		}
+	case map[string]any:
+		if step.name == "*" {
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			values := make([]any, len(keys))
+			for i, k := range keys {
+				values[i] = v[k]
+			}
+			return values
+		}
+		if child, ok := v[step.name]; ok && !step.isIndex {
+			return []any{child}
+		}
+	}
+	return nil
+}
+
+// descendants returns the name field of v and of everything nested in it.
+func descendants(v any, name string) []any {
+	var found []any
+	switch v := v.(type) {
+	case []any:
+		for _, child := range v {
+			found = append(found, descendants(child, name)...)
+		}
+	case map[string]any:
+		if child, ok := v[name]; ok {
+			found = append(found, child)
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			found = append(found, descendants(v[k], name)...)
+		}
+	}
+	return found
+}
+
+// writeJSONPath writes every value path selects in data, one per line.
+func writeJSONPath(w io.Writer, path jsonPath, data any) error {
+	value, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+	for _, v := range path.eval(value) {
+		if _, err := fmt.Fprintln(w, jsonValueString(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,8 +12,8 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
+	"strconv"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,7 +22,6 @@ import (
 	"github.com/Thunder-Compute/thunder-cli/api"
 	"github.com/Thunder-Compute/thunder-cli/tui"
 	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
-	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
 const (
@@ -219,6 +218,19 @@ type Config struct {
 	Token        string    `json:"token"`
 	RefreshToken string    `json:"refresh_token,omitempty"`
 	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+	// MonthlyBudget, in USD, makes create and modify check projected spend.
+	MonthlyBudget float64 `json:"monthly_budget,omitempty"`
+	// CredentialStore names where Token and RefreshToken are kept when they
+	// are not in this file: keychain or encrypted.
+	CredentialStore string `json:"credential_store,omitempty"`
+
+	// profile is the profile LoadConfig read this from; it is empty for
+	// TNR_API_TOKEN, which has nowhere to save a refreshed token.
+	profile string
+}
+
+func (c *Config) isExpired() bool {
+	return !c.ExpiresAt.IsZero() && time.Now().After(c.ExpiresAt)
 }
 
 const DefaultAPIURL = "https://api.thundercompute.com:8443"
@@ -230,7 +242,11 @@ func getAPIURL() string {
 	return DefaultAPIURL
 }
 
-var loginToken string
+var (
+	loginToken           string
+	loginCredentialStore string
+	loginDevice          bool
+)
 
 // loginCmd represents the login command
 var loginCmd = &cobra.Command{
@@ -246,6 +262,8 @@ func init() {
 
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().StringVar(&loginToken, "token", "", "Authenticate directly with a token instead of opening browser")
+	loginCmd.Flags().BoolVar(&loginDevice, "device", false, "Log in by entering a code in a browser on any device, for machines without one")
+	loginCmd.Flags().StringVar(&loginCredentialStore, "credential-store", "", "Where to keep the token: keychain, encrypted or plaintext (default: keychain if available)")
 }
 
 func loginMessage(prefix string, result *api.ValidateTokenResult) string {
@@ -317,6 +335,9 @@ func runLogin() error {
 		return nil
 	}
 
+	if loginDevice {
+		return runDeviceLogin()
+	}
 	return runInteractiveLogin()
 }
 
@@ -341,9 +362,13 @@ func runInteractiveLogin() error {
 	model := tui.NewLoginModel(authURLWithParams)
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
+	// The callback's refresh token, kept for saving once the TUI exits. It
+	// is written before the TUI is told of success and read after it exits.
+	var callbackAuth AuthResponse
 	go func() {
 		select {
 		case authResp := <-authChan:
+			callbackAuth = authResp
 			tui.SendLoginSuccess(p, authResp.Token)
 		case err := <-errChan:
 			tui.SendLoginError(p, err)
@@ -355,7 +380,7 @@ func runInteractiveLogin() error {
 	}()
 
 	if err := openBrowser(authURLWithParams); err != nil {
-		fmt.Printf("Failed to open browser automatically: %v\n", err)
+		fmt.Printf("Failed to open browser automatically: %v (without a browser, use 'tnr login --device')\n", err)
 	}
 
 	finalModel, err := p.Run()
@@ -391,6 +416,10 @@ func runInteractiveLogin() error {
 		authResp := AuthResponse{
 			Token: token,
 		}
+		// A token pasted by hand comes without a refresh token.
+		if callbackAuth.Token == token {
+			authResp = callbackAuth
+		}
 		if err := saveConfig(authResp); err != nil {
 			return fmt.Errorf("failed to save credentials: %w", err)
 		}
@@ -464,6 +493,9 @@ func startCallbackServerWithContext(ctx context.Context) (int, <-chan AuthRespon
 			Token:        token,
 			RefreshToken: refreshToken,
 		}
+		if expiresIn, err := strconv.Atoi(r.URL.Query().Get("expires_in")); err == nil {
+			authResp.ExpiresIn = expiresIn
+		}
 
 		authChan <- authResp
 
@@ -503,29 +535,34 @@ func openBrowser(url string) error {
 	return cmd.Start()
 }
 
+// saveConfig stores a fresh login in the active profile.
 func saveConfig(authResp AuthResponse) error {
-	configDir, err := utils.ThunderDir()
-	if err != nil {
-		return err
-	}
-
-	configPath := filepath.Join(configDir, "cli_config.json")
+	return updateConfigFile(func(file *configFile) error {
+		name := activeProfile(file)
 
-	config := Config{
-		Token:        authResp.Token,
-		RefreshToken: authResp.RefreshToken,
-	}
+		config := &Config{
+			Token:        authResp.Token,
+			RefreshToken: authResp.RefreshToken,
+		}
+		// Settings outlive the login that replaces the token.
+		var previousStore string
+		if existing := file.profile(name); existing != nil {
+			config.APIURL = existing.APIURL
+			config.MonthlyBudget = existing.MonthlyBudget
+			previousStore = existing.CredentialStore
+		}
 
-	if authResp.ExpiresIn > 0 {
-		config.ExpiresAt = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
-	}
+		if authResp.ExpiresIn > 0 {
+			config.ExpiresAt = time.Now().Add(time.Duration(authResp.ExpiresIn) * time.Second)
+		}
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
-	}
+		if err := storeCredentials(name, config, previousStore); err != nil {
+			return err
+		}
 
-	return os.WriteFile(configPath, data, 0o600)
+		file.setProfile(name, config)
+		return nil
+	})
 }
 
 // .thunder.json config
@@ -563,21 +600,20 @@ func LoadConfig() (*Config, error) {
 		return config, nil
 	}
 
-	configDir, err := utils.ThunderDir()
+	file, err := readConfigFile()
 	if err != nil {
 		return nil, err
 	}
-
-	configPath := filepath.Join(configDir, "cli_config.json")
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, err
+	name := activeProfile(file)
+	profile := file.profile(name)
+	if profile == nil {
+		return nil, fmt.Errorf("profile '%s' not found", name)
 	}
-
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	config := *profile
+	if err := resolveCredentials(name, &config); err != nil {
 		return nil, err
 	}
+	config.profile = name
 
 	if config.APIURL == "" {
 		config.APIURL = DefaultAPIURL
@@ -611,31 +647,40 @@ func init() {
 }
 
 func runLogout() error {
-	envToken := os.Getenv("TNR_API_TOKEN")
-
-	configDir, err := utils.ThunderDir()
-	if err != nil {
-		return fmt.Errorf("failed to resolve thunder directory: %w", err)
+	if os.Getenv("TNR_API_TOKEN") != "" {
+		PrintWarningSimple("You are authenticated via TNR_API_TOKEN environment variable.")
+		return nil
 	}
 
-	configPath := filepath.Join(configDir, "cli_config.json")
-	configExists := true
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		configExists = false
+	// Only the active profile is logged out; the file goes once it holds
+	// no other profile.
+	var name string
+	loggedIn := false
+	err := updateConfigFile(func(file *configFile) error {
+		name = activeProfile(file)
+		config := file.profile(name)
+		if config == nil {
+			return errConfigUnchanged
+		}
+		loggedIn = true
+		if config.CredentialStore != "" {
+			forgetCredentials(name, config.CredentialStore)
+		}
+		file.deleteProfile(name)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-
-	if !configExists && envToken == "" {
+	if !loggedIn {
 		PrintWarningSimple("You are not logged in.")
 		return nil
-	} else if envToken != "" {
-		PrintWarningSimple("You are authenticated via TNR_API_TOKEN environment variable.")
-		return nil
 	}
 
-	if err := os.Remove(configPath); err != nil {
-		return fmt.Errorf("failed to remove config file: %w", err)
+	if name != defaultProfile {
+		PrintSuccessSimple(fmt.Sprintf("Successfully logged out of profile '%s'!", name))
+		return nil
 	}
-
 	PrintSuccessSimple("Successfully logged out from Thunder Compute!")
 	return nil
 }
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+)
+
+const (
+	// deviceDefaultInterval is the polling interval when the server does
+	// not name one.
+	deviceDefaultInterval = 5 * time.Second
+	// deviceSlowDownStep is added to the interval on every slow_down.
+	deviceSlowDownStep = 5 * time.Second
+	// deviceMaxInterval caps the backoff after network errors.
+	deviceMaxInterval = time.Minute
+)
+
+// deviceAfter waits between device login polls; tests make it immediate.
+var deviceAfter = time.After
+
+// runDeviceLogin logs in without a browser on this machine: the user opens
+// a URL anywhere and enters a short code while tnr polls for approval.
+func runDeviceLogin() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := api.NewClient("", getAPIURL())
+	code, err := client.StartDeviceAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start device login: %w", err)
+	}
+
+	fmt.Println("To log in, open this URL in a browser on any device:")
+	fmt.Println()
+	fmt.Printf("  %s\n", code.VerificationURI)
+	fmt.Println()
+	fmt.Printf("and enter the code: %s\n", code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("\nOr open %s to skip entering it.\n", code.VerificationURIComplete)
+	}
+	fmt.Println()
+
+	var authResp *AuthResponse
+	err = tui.RunWithBusySpinner("Waiting for you to approve the login...", os.Stdout, func() error {
+		var e error
+		authResp, e = pollDeviceToken(ctx, client, code, deviceAfter)
+		return e
+	})
+	if ctx.Err() != nil {
+		PrintWarningSimple("User cancelled authentication")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	validateCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	result, err := api.NewClient(authResp.Token, getAPIURL()).ValidateToken(validateCtx)
+	if err != nil {
+		return fmt.Errorf("token validation failed: %w", err)
+	}
+	if err := saveConfig(*authResp); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	PrintSuccessSimple(loginMessage("Logged in", result))
+	return nil
+}
+
+// pollDeviceToken polls until the user approves or denies the login, the
+// code expires, or ctx is cancelled. It waits the server's interval between
+// polls, lengthening it on slow_down and backing off exponentially while
+// the network fails. after is the timer, time.After outside tests.
+func pollDeviceToken(ctx context.Context, client *api.Client, code *api.DeviceCodeResponse, after func(time.Duration) <-chan time.Time) (*AuthResponse, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = deviceDefaultInterval
+	}
+	var expired <-chan time.Time
+	if code.ExpiresIn > 0 {
+		expired = after(time.Duration(code.ExpiresIn) * time.Second)
+	}
+
+	wait := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-expired:
+			return nil, usageErr("the login code expired. Run 'tnr login --device' again")
+		case <-after(wait):
+		}
+
+		resp, err := client.PollDeviceToken(ctx, code.DeviceCode)
+		switch {
+		case err == nil:
+			return &AuthResponse{Token: resp.Token, RefreshToken: resp.RefreshToken, ExpiresIn: resp.ExpiresIn}, nil
+		case errors.Is(err, api.ErrAuthorizationPending):
+			wait = interval
+		case errors.Is(err, api.ErrSlowDown):
+			interval += deviceSlowDownStep
+			wait = interval
+		case errors.Is(err, api.ErrAccessDenied):
+			return nil, usageErr("the login was denied")
+		case errors.Is(err, api.ErrDeviceCodeExpired):
+			return nil, usageErr("the login code expired. Run 'tnr login --device' again")
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case errors.Is(err, api.ErrTransport) || isServerError(err):
+			wait = min(wait*2, deviceMaxInterval)
+		default:
+			return nil, fmt.Errorf("device login failed: %w", err)
+		}
+	}
+}
+
+func isServerError(err error) bool {
+	var apiErr *api.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// fakeDeviceServer answers device token polls from a script of responses:
+// an RFC 8628 error code, "500", or "" for approval.
+type fakeDeviceServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	script []string
+	polls  int
+}
+
+func newFakeDeviceServer(t *testing.T, script ...string) *fakeDeviceServer {
+	f := &fakeDeviceServer{script: script}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/auth/device/code":
+			_ = json.NewEncoder(w).Encode(api.DeviceCodeResponse{
+				DeviceCode:      "dev-123",
+				UserCode:        "WDJB-MJHT",
+				VerificationURI: "https://console.example.com/device",
+				ExpiresIn:       600,
+				Interval:        2,
+			})
+		case "/v1/auth/device/token":
+			var req api.DeviceTokenRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "dev-123", req.DeviceCode)
+			step := "authorization_pending"
+			if f.polls < len(f.script) {
+				step = f.script[f.polls]
+			}
+			f.polls++
+			switch step {
+			case "":
+				_, _ = w.Write([]byte(`{"token":"device-token","refresh_token":"device-refresh","expires_in":3600}`))
+			case "500":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": step})
+			}
+		case "/v1/auth/validate":
+			assert.Equal(t, "Bearer device-token", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"valid":true,"email":"dev@example.com"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// immediateAfter fires at once, recording the waits asked for.
+func immediateAfter(waits *[]time.Duration) func(time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		*waits = append(*waits, d)
+		ch := make(chan time.Time, 1)
+		if d < 10*time.Minute {
+			ch <- time.Now()
+		}
+		return ch
+	}
+}
+
+func startDeviceAuth(t *testing.T, server *fakeDeviceServer) (*api.Client, *api.DeviceCodeResponse) {
+	t.Helper()
+	client := api.NewClient("", server.URL)
+	code, err := client.StartDeviceAuth(context.Background())
+	require.NoError(t, err)
+	return client, code
+}
+
+func TestPollDeviceTokenBackoff(t *testing.T) {
+	server := newFakeDeviceServer(t, "authorization_pending", "slow_down", "500", "authorization_pending", "")
+	client, code := startDeviceAuth(t, server)
+
+	var waits []time.Duration
+	resp, err := pollDeviceToken(context.Background(), client, code, immediateAfter(&waits))
+	require.NoError(t, err)
+	assert.Equal(t, "device-token", resp.Token)
+	assert.Equal(t, "device-refresh", resp.RefreshToken)
+
+	// The first timer is the code's lifetime. Polls then wait the server's
+	// 2s interval, 5s more after slow_down, double that after the server
+	// error, and the interval again once it answers.
+	assert.Equal(t, []time.Duration{
+		10 * time.Minute,
+		2 * time.Second, 2 * time.Second, 7 * time.Second, 14 * time.Second, 7 * time.Second,
+	}, waits)
+}
+
+func TestPollDeviceTokenEnds(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []string
+		want   string
+	}{
+		{"denied", []string{"access_denied"}, "denied"},
+		{"expired", []string{"authorization_pending", "expired_token"}, "expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeDeviceServer(t, tt.script...)
+			client, code := startDeviceAuth(t, server)
+			var waits []time.Duration
+			_, err := pollDeviceToken(context.Background(), client, code, immediateAfter(&waits))
+			assert.ErrorIs(t, err, ErrUsage)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestPollDeviceTokenCancelled(t *testing.T) {
+	server := newFakeDeviceServer(t)
+	client, code := startDeviceAuth(t, server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := pollDeviceToken(ctx, client, code, time.After)
+		done <- err
+	}()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("polling did not stop when cancelled")
+	}
+}
+
+func TestRunDeviceLogin(t *testing.T) {
+	testProfileEnv(t)
+	server := newFakeDeviceServer(t, "authorization_pending", "")
+	t.Setenv("TNR_API_URL", server.URL)
+	var waits []time.Duration
+	deviceAfter = immediateAfter(&waits)
+	t.Cleanup(func() { deviceAfter = time.After })
+
+	require.NoError(t, runDeviceLogin())
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "device-token", config.Token)
+	assert.Equal(t, "device-refresh", config.RefreshToken)
+	assert.False(t, config.ExpiresAt.IsZero())
+}
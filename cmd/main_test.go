@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// Keep tests that log in out of the developer's OS keychain.
+	os.Setenv("TNR_CREDENTIAL_STORE", "plaintext")
+	os.Exit(m.Run())
+}
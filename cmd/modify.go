@@ -39,6 +39,8 @@ func init() {
 	modifyCmd.Flags().Int("disk-size-gb", 0, "Disk size in GB (cannot shrink, max depends on config)")
 	_ = modifyCmd.Flags().MarkHidden("disk-size-gb")
 	modifyCmd.Flags().Int("ephemeral-disk", -1, "Ephemeral storage in GB, mounted at /ephemeral (0 to disable)")
+	modifyCmd.Flags().Bool("wait", false, "Wait until the instance is running again")
+	addIdleFlags(modifyCmd, "Act on the instance once it has been idle this long, e.g. 2h (0 removes the policy)")
 
 	modifyCmd.SetHelpFunc(wrapHelp(helpmenus.RenderModifyHelp))
 
@@ -46,6 +48,11 @@ func init() {
 }
 
 func runModify(cmd *cobra.Command, args []string) error {
+	idle, err := parseIdleFlags(cmd, true)
+	if err != nil {
+		return err
+	}
+
 	client, err := getAuthenticatedClient()
 	if err != nil {
 		return err
@@ -111,14 +118,19 @@ func runModify(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Build presets from flags
+	modifyPresets := buildModifyPresets(cmd)
+
+	// An idle policy alone doesn't touch the instance's configuration.
+	if idle.set && modifyPresets.IsEmpty() {
+		return applyIdleFlags(client, selectedInstance, idle)
+	}
+
 	// Validate instance is RUNNING
 	if selectedInstance.Status != "RUNNING" {
 		return usageErr("instance must be in RUNNING state to modify (current state: %s)", selectedInstance.Status)
 	}
 
-	// Build presets from flags
-	modifyPresets := buildModifyPresets(cmd)
-
 	var modifyConfig *tui.ModifyConfig
 	var modifyReq api.InstanceModifyRequest
 
@@ -189,48 +201,38 @@ func runModify(cmd *cobra.Command, args []string) error {
 
 	// Display estimated pricing for the resulting configuration
 	if pricing, pricingErr := client.FetchPricing(); pricingErr == nil {
-		pd := &utils.PricingData{Rates: pricing}
-		// Compute resulting config: start with current values, override with modifications
-		resultMode := strings.ToLower(selectedInstance.Mode)
-		resultGPU := strings.ToLower(selectedInstance.GPUType)
-		resultNumGPUs := 1
-		if n, parseErr := strconv.Atoi(selectedInstance.NumGPUs); parseErr == nil {
-			resultNumGPUs = n
-		}
-		resultVCPUs := 4
-		if n, parseErr := strconv.Atoi(selectedInstance.CPUCores); parseErr == nil {
-			resultVCPUs = n
-		}
-		resultDisk := selectedInstance.Storage
+		price := modifiedHourlyPrice(&utils.PricingData{Rates: pricing}, specs, selectedInstance, modifyReq)
+		fmt.Printf("\nEstimated cost: %s\n", utils.FormatPrice(price))
+	}
 
-		if modifyReq.Mode != nil {
-			resultMode = string(*modifyReq.Mode)
-		}
-		if modifyReq.GPUType != nil {
-			resultGPU = *modifyReq.GPUType
-		}
-		if modifyReq.NumGPUs != nil {
-			resultNumGPUs = *modifyReq.NumGPUs
-		}
-		if modifyReq.CPUCores != nil {
-			resultVCPUs = *modifyReq.CPUCores
-		}
-		if modifyReq.DiskSizeGB != nil {
-			resultDisk = *modifyReq.DiskSizeGB
+	if ok, err := checkBudget(client, selectedInstance, func(pd *utils.PricingData, specs *utils.SpecStore) float64 {
+		return modifiedHourlyPrice(pd, specs, selectedInstance, modifyReq)
+	}); !ok {
+		return err
+	}
+
+	wait, _ := cmd.Flags().GetBool("wait")
+	settle := waitSettle
+	if modifyOnlyChangesPorts(modifyReq) {
+		// Port changes apply without a restart.
+		settle = 0
+	}
+
+	// Unless only ports change, the instance restarts, so the idle policy is
+	// installed once it is back: right after --wait, or on the next tnr connect.
+	applyIdle := func(running *api.Instance) error {
+		if !idle.set {
+			return nil
 		}
-		// Get vCPUs from specs for the resulting config
-		if vcpuOpts := specs.VCPUOptions(resultGPU, resultNumGPUs, resultMode); len(vcpuOpts) > 0 && modifyReq.CPUCores == nil {
-			resultVCPUs = vcpuOpts[0]
+		if running == nil && settle == 0 {
+			running = selectedInstance
 		}
-
-		resultEphemeral := selectedInstance.EphemeralDiskGB
-		if modifyReq.EphemeralDiskGB != nil {
-			resultEphemeral = *modifyReq.EphemeralDiskGB
+		if running == nil {
+			restarting := *selectedInstance
+			restarting.Status = ""
+			running = &restarting
 		}
-
-		included := specs.IncludedVCPUs(resultGPU, resultNumGPUs, resultMode)
-		price := utils.CalculateHourlyPrice(pd, resultMode, resultGPU, resultNumGPUs, resultVCPUs, resultDisk, resultEphemeral, included)
-		fmt.Printf("\nEstimated cost: %s\n", utils.FormatPrice(price))
+		return applyIdleFlags(client, running, idle)
 	}
 
 	// Make API call
@@ -242,12 +244,19 @@ func runModify(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to modify instance: %w", err)
 		}
-		if JSONOutput {
-			printJSON(modifyResp)
-		} else {
+		if !JSONOutput {
 			fmt.Printf("Modified instance %s\n", selectedInstance.ID)
 		}
-		return nil
+		var running *api.Instance
+		if wait {
+			if running, err = waitWithSpinner(client, selectedInstance.UUID, waitRunning, defaultWaitTimeout, settle); err != nil {
+				return err
+			}
+		}
+		if JSONOutput {
+			printResult(modifyResp)
+		}
+		return applyIdle(running)
 	}
 
 	p := tea.NewProgram(tui.NewProgressModel("Modifying instance...",
@@ -281,7 +290,64 @@ func runModify(cmd *cobra.Command, args []string) error {
 	}
 
 	// Success output is rendered in the View() method
-	return nil
+	var running *api.Instance
+	if wait {
+		if running, err = waitWithSpinner(client, selectedInstance.UUID, waitRunning, defaultWaitTimeout, settle); err != nil {
+			return err
+		}
+		PrintSuccessSimple(fmt.Sprintf("Instance %s is running", selectedInstance.ID))
+	}
+	return applyIdle(running)
+}
+
+// modifiedHourlyPrice prices the configuration inst will have once req is
+// applied.
+func modifiedHourlyPrice(pd *utils.PricingData, specs *utils.SpecStore, inst *api.Instance, req api.InstanceModifyRequest) float64 {
+	// Compute resulting config: start with current values, override with modifications
+	resultMode := strings.ToLower(inst.Mode)
+	resultGPU, _ := specs.NormalizeGPUType(inst.GPUType, resultMode)
+	resultNumGPUs := 1
+	if n, parseErr := strconv.Atoi(inst.NumGPUs); parseErr == nil {
+		resultNumGPUs = n
+	}
+	resultVCPUs := 4
+	if n, parseErr := strconv.Atoi(inst.CPUCores); parseErr == nil {
+		resultVCPUs = n
+	}
+	resultDisk := inst.Storage
+
+	if req.Mode != nil {
+		resultMode = string(*req.Mode)
+	}
+	if req.GPUType != nil {
+		resultGPU = *req.GPUType
+	}
+	if req.NumGPUs != nil {
+		resultNumGPUs = *req.NumGPUs
+	}
+	if req.CPUCores != nil {
+		resultVCPUs = *req.CPUCores
+	}
+	if req.DiskSizeGB != nil {
+		resultDisk = *req.DiskSizeGB
+	}
+	// Get vCPUs from specs for the resulting config
+	if vcpuOpts := specs.VCPUOptions(resultGPU, resultNumGPUs, resultMode); len(vcpuOpts) > 0 && req.CPUCores == nil {
+		resultVCPUs = vcpuOpts[0]
+	}
+
+	resultEphemeral := inst.EphemeralDiskGB
+	if req.EphemeralDiskGB != nil {
+		resultEphemeral = *req.EphemeralDiskGB
+	}
+
+	included := specs.IncludedVCPUs(resultGPU, resultNumGPUs, resultMode)
+	return utils.CalculateHourlyPrice(pd, resultMode, resultGPU, resultNumGPUs, resultVCPUs, resultDisk, resultEphemeral, included)
+}
+
+func modifyOnlyChangesPorts(req api.InstanceModifyRequest) bool {
+	return req.CPUCores == nil && req.GPUType == nil && req.NumGPUs == nil && req.DiskSizeGB == nil &&
+		req.EphemeralDiskGB == nil && req.Mode == nil
 }
 
 func buildModifyPresets(cmd *cobra.Command) *tui.ModifyPresets {
@@ -0,0 +1,378 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	texttemplate "text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// OutputFlag is the raw --output value, e.g. "yaml" or "template={{.ID}}".
+	OutputFlag string
+	// ColumnsFlag picks and orders the columns of table, wide and csv output.
+	ColumnsFlag []string
+)
+
+// outputFormats lists the --output values, for flag help and errors.
+const outputFormats = "json, yaml, csv, table, wide, template=<tmpl> or jsonpath=<expr>"
+
+// outputFormat is the parsed --output flag.
+type outputFormat struct {
+	// kind is json, yaml, csv, table, wide, template or jsonpath, or empty
+	// when neither --output, --json nor --columns was given.
+	kind string
+	tmpl *texttemplate.Template
+	path jsonPath
+}
+
+// output is the format the current command prints in, set before it runs.
+var output outputFormat
+
+// outputErr is the first error printResult met while rendering a result,
+// such as a template naming a field that does not exist. Execute reports
+// it once the command returns.
+var outputErr error
+
+// structured reports whether the format is for programs rather than people.
+// Commands print their result with printResult instead of prose and never
+// start an interactive view.
+func (f outputFormat) structured() bool {
+	switch f.kind {
+	case "json", "yaml", "csv", "template", "jsonpath":
+		return true
+	}
+	return false
+}
+
+// parseOutputFlag validates --output together with --json and --columns.
+func parseOutputFlag(value string, jsonFlag bool, columns []string) (outputFormat, error) {
+	kind, arg, _ := strings.Cut(value, "=")
+	if jsonFlag {
+		if kind != "" && kind != "json" {
+			return outputFormat{}, usageErr("--json cannot be combined with --output %s", kind)
+		}
+		kind = "json"
+	}
+
+	f := outputFormat{kind: kind}
+	switch kind {
+	case "":
+		if len(columns) > 0 {
+			f.kind = "table"
+		}
+	case "json", "yaml", "csv", "table", "wide":
+		if arg != "" {
+			return outputFormat{}, usageErr("--output %s takes no argument", kind)
+		}
+	case "template", "go-template":
+		if arg == "" {
+			return outputFormat{}, usageErr("--output template needs a template, e.g. template='{{.ID}}'")
+		}
+		tmpl, err := texttemplate.New("output").Funcs(texttemplate.FuncMap{"json": templateJSON}).Parse(arg)
+		if err != nil {
+			return outputFormat{}, usageErr("invalid template: %v", err)
+		}
+		f.kind, f.tmpl = "template", tmpl
+	case "jsonpath":
+		path, err := parseJSONPath(arg)
+		if err != nil {
+			return outputFormat{}, usageErr("invalid jsonpath: %v", err)
+		}
+		f.path = path
+	default:
+		return outputFormat{}, usageErr("unknown output format '%s'; use %s", value, outputFormats)
+	}
+
+	if len(columns) > 0 {
+		switch f.kind {
+		case "table", "wide", "csv":
+		default:
+			return outputFormat{}, usageErr("--columns only applies to table, wide and csv output")
+		}
+	}
+	return f, nil
+}
+
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// printResult prints a command's result in the --output format, JSON unless
+// another was picked. A failure to render it is kept for Execute to report.
+func printResult(data any) {
+	if err := writeOutput(os.Stdout, data); err != nil && outputErr == nil {
+		outputErr = err
+	}
+}
+
+// writeOutput writes data in the --output format. Slices are rendered one
+// element at a time by templates; csv has one row per element.
+func writeOutput(w io.Writer, data any) error {
+	switch output.kind {
+	case "yaml":
+		return writeYAML(w, data)
+	case "csv":
+		return writeGenericCSV(w, data)
+	case "template":
+		return eachElement(data, func(v any) error {
+			if err := output.tmpl.Execute(w, v); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintln(w)
+			return err
+		})
+	case "jsonpath":
+		return writeJSONPath(w, output.path, data)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// eachElement calls fn with every element of a slice, or with data itself
+// if it is not one. Elements are passed by pointer where possible so that
+// templates can call methods with pointer receivers.
+func eachElement(data any, fn func(any) error) error {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return fn(data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.CanAddr() {
+			elem = elem.Addr()
+		}
+		if err := fn(elem.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeYAML writes data as YAML with the field names and order of its JSON
+// encoding.
+func writeYAML(w io.Writer, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// JSON is YAML, so the encoding decodes into a node tree that only needs
+	// its flow style cleared to print as block YAML.
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	clearYAMLStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// writeGenericCSV writes data without columns of its own: one row per
+// element of a list, or a single row for an object, with the JSON field
+// names as the header. Nested values are written as JSON.
+func writeGenericCSV(w io.Writer, data any) error {
+	value, err := toJSONValue(data)
+	if err != nil {
+		return err
+	}
+	rows, ok := value.([]any)
+	if !ok {
+		rows = []any{value}
+	}
+
+	var header []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		obj, ok := row.(map[string]any)
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		header = append(header, keys...)
+	}
+
+	cw := csv.NewWriter(w)
+	if len(header) > 0 {
+		_ = cw.Write(header)
+	}
+	for _, row := range rows {
+		obj, ok := row.(map[string]any)
+		if !ok {
+			_ = cw.Write([]string{jsonValueString(row)})
+			continue
+		}
+		record := make([]string, len(header))
+		for i, k := range header {
+			if v, ok := obj[k]; ok {
+				record[i] = jsonValueString(v)
+			}
+		}
+		_ = cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// toJSONValue converts data to the maps, slices and scalars of its JSON
+// encoding, keeping numbers as json.Number.
+func toJSONValue(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// jsonValueString renders a decoded JSON value for a single cell or line:
+// strings and numbers as they are, null as nothing, anything else as JSON.
+func jsonValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// column is one column of a listing in table, wide and csv output.
+type column[T any] struct {
+	name string
+	// wide columns are left out of the default table; -o wide, csv and
+	// --columns show them.
+	wide  bool
+	value func(T) string
+}
+
+// printList prints items in the --output format: like printResult for
+// structured formats, or as a table of the selected columns.
+func printList[T any](items []T, columns []column[T]) error {
+	return writeList(os.Stdout, items, columns)
+}
+
+func writeList[T any](w io.Writer, items []T, columns []column[T]) error {
+	if output.structured() && output.kind != "csv" {
+		if items == nil {
+			items = []T{}
+		}
+		return writeOutput(w, items)
+	}
+	selected, err := selectColumns(columns)
+	if err != nil {
+		return err
+	}
+	if output.kind == "csv" {
+		return writeCSV(w, items, selected)
+	}
+	return writeTable(w, items, selected)
+}
+
+// selectColumns returns the columns named by --columns, in that order, or
+// else the ones the format shows by default.
+func selectColumns[T any](columns []column[T]) ([]column[T], error) {
+	if len(ColumnsFlag) == 0 {
+		var selected []column[T]
+		for _, c := range columns {
+			if !c.wide || output.kind == "wide" || output.kind == "csv" {
+				selected = append(selected, c)
+			}
+		}
+		return selected, nil
+	}
+
+	byName := make(map[string]column[T], len(columns))
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		byName[columnKey(c.name)] = c
+		names = append(names, c.name)
+	}
+	var selected []column[T]
+	for _, name := range ColumnsFlag {
+		c, ok := byName[columnKey(name)]
+		if !ok {
+			return nil, usageErr("unknown column '%s'; available: %s", name, strings.Join(names, ", "))
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// columnKey normalizes a column name so that --columns matches "API URL"
+// with api_url, api-url or apiurl.
+func columnKey(name string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+func writeTable[T any](w io.Writer, items []T, columns []column[T]) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+	for _, item := range items {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = c.value(item)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV[T any](w io.Writer, items []T, columns []column[T]) error {
+	cw := csv.NewWriter(w)
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	_ = cw.Write(names)
+	for _, item := range items {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = c.value(item)
+		}
+		_ = cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// useOutput sets --output and --columns for the rest of the test.
+func useOutput(t *testing.T, value string, columns ...string) {
+	t.Helper()
+	format, err := parseOutputFlag(value, false, columns)
+	require.NoError(t, err)
+	prevOutput, prevColumns := output, ColumnsFlag
+	output, ColumnsFlag = format, columns
+	t.Cleanup(func() { output, ColumnsFlag = prevOutput, prevColumns })
+}
+
+func testInstances() []api.Instance {
+	ip := "10.0.0.5"
+	return []api.Instance{
+		{ID: "0", UUID: "abc", Status: "RUNNING", IP: &ip, Mode: "PROTOTYPING", NumGPUs: "1", GPUType: "a100xl", CPUCores: "8", Memory: "64", Storage: 100, Template: "base", HTTPPorts: []int{8080}},
+		{ID: "1", UUID: "def", Status: "STOPPED", Mode: "production", NumGPUs: "2", GPUType: "h100", CPUCores: "36", Memory: "180", Storage: 200},
+	}
+}
+
+func TestParseOutputFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		json    bool
+		columns []string
+		kind    string
+		wantErr string
+	}{
+		{value: "", kind: ""},
+		{value: "", json: true, kind: "json"},
+		{value: "json", json: true, kind: "json"},
+		{value: "yaml", kind: "yaml"},
+		{value: "wide", kind: "wide"},
+		{value: "", columns: []string{"id"}, kind: "table"},
+		{value: "csv", columns: []string{"id"}, kind: "csv"},
+		{value: "template={{.ID}}", kind: "template"},
+		{value: "jsonpath={[*].id}", kind: "jsonpath"},
+		{value: "yaml", json: true, wantErr: "--json cannot be combined with --output yaml"},
+		{value: "xml", wantErr: "unknown output format 'xml'"},
+		{value: "template=", wantErr: "needs a template"},
+		{value: "template={{.ID", wantErr: "invalid template"},
+		{value: "jsonpath=", wantErr: "invalid jsonpath"},
+		{value: "table=x", wantErr: "takes no argument"},
+		{value: "json", columns: []string{"id"}, wantErr: "--columns only applies"},
+	}
+	for _, tt := range tests {
+		format, err := parseOutputFlag(tt.value, tt.json, tt.columns)
+		if tt.wantErr != "" {
+			if assert.Error(t, err, tt.value) {
+				assert.True(t, errors.Is(err, ErrUsage))
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.kind, format.kind, tt.value)
+	}
+}
+
+func TestWriteListFormats(t *testing.T) {
+	tests := []struct {
+		value   string
+		columns []string
+		want    string
+	}{
+		{value: "template={{.ID}} {{.GetIP}}", want: "0 10.0.0.5\n1 \n"},
+		{value: "jsonpath={[*].uuid}", want: "abc\ndef\n"},
+		{value: "jsonpath=$[0].httpPorts", want: "[8080]\n"},
+		{value: "table", columns: []string{"status", "id"}, want: "STATUS   ID\nRUNNING  0\nSTOPPED  1\n"},
+		{value: "csv", columns: []string{"id", "gpu", "http_ports"}, want: "ID,GPU,HTTP_PORTS\n0,1xA100 80GB,8080\n1,2xH100,\n"},
+		{value: "table", want: "ID  UUID  STATUS   ADDRESS   MODE         DISK   GPU          vCPUs  RAM    TEMPLATE\n" +
+			"0   abc   RUNNING  10.0.0.5  prototyping  100GB  1xA100 80GB  8      64GB   base\n" +
+			"1   def   STOPPED            production   200GB  2xH100       36     180GB  \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			useOutput(t, tt.value, tt.columns...)
+			var buf bytes.Buffer
+			require.NoError(t, writeList(&buf, testInstances(), statusColumns))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWriteListUnknownColumn(t *testing.T) {
+	useOutput(t, "", "nope")
+	err := writeList(&bytes.Buffer{}, testInstances(), statusColumns)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	assert.Contains(t, err.Error(), "available: ID, NAME, UUID")
+}
+
+func TestWriteOutputYAMLKeepsJSONNames(t *testing.T) {
+	useOutput(t, "yaml")
+	var buf bytes.Buffer
+	require.NoError(t, writeList(&buf, []portEntry{{ID: "0", UUID: "abc", Status: "RUNNING", HTTPPorts: []int{8080, 443}}}, portColumns))
+	assert.Equal(t, "- id: \"0\"\n  uuid: abc\n  status: RUNNING\n  http_ports:\n    - 8080\n    - 443\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, writeList(&buf, []portEntry(nil), portColumns))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteOutputGenericCSV(t *testing.T) {
+	useOutput(t, "csv")
+	var buf bytes.Buffer
+	require.NoError(t, writeOutput(&buf, map[string]any{"snapshot": "snap", "status": "deleted", "ports": []int{1, 2}}))
+	assert.Equal(t, "ports,snapshot,status\n\"[1,2]\",snap,deleted\n", buf.String())
+}
+
+func TestWriteOutputTemplateError(t *testing.T) {
+	useOutput(t, "template={{.Missing}}")
+	err := writeOutput(&bytes.Buffer{}, testInstances())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't evaluate field Missing")
+}
+
+func TestParseJSONPath(t *testing.T) {
+	data := map[string]any{
+		"items": []any{
+			map[string]any{"id": "0", "meta": map[string]any{"zone": "a"}},
+			map[string]any{"id": "1", "meta": map[string]any{"zone": "b"}},
+		},
+		"odd key": 3,
+	}
+	tests := []struct {
+		expr string
+		want []any
+	}{
+		{"$.items[*].id", []any{"0", "1"}},
+		{"{.items[-1].meta.zone}", []any{"b"}},
+		{"items[0].id", []any{"0"}},
+		{"$..zone", []any{"a", "b"}},
+		{"$['odd key']", []any{3}},
+		{"$.items[5].id", nil},
+		{"$.missing", nil},
+	}
+	for _, tt := range tests {
+		path, err := parseJSONPath(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, tt.want, path.eval(data), tt.expr)
+	}
+
+	for _, expr := range []string{"", "$.items[", "$.items[x]", "$..", "$.a."} {
+		_, err := parseJSONPath(expr)
+		assert.Error(t, err, expr)
+	}
+}
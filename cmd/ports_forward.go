@@ -134,7 +134,7 @@ func runPortsForward(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to update ports: %w", err)
 		}
 		if JSONOutput {
-			printJSON(portsResp)
+			printResult(portsResp)
 		} else {
 			fmt.Printf("Ports updated for instance %s\n", selectedInstance.ID)
 		}
@@ -27,6 +27,23 @@ var portsListCmd = &cobra.Command{
 	},
 }
 
+// portEntry is an instance as tnr ports list prints it with --output.
+type portEntry struct {
+	ID        string `json:"id"`
+	UUID      string `json:"uuid"`
+	Status    string `json:"status"`
+	HTTPPorts []int  `json:"http_ports"`
+}
+
+// portColumns are the columns of tnr ports list in table, wide and csv
+// output.
+var portColumns = []column[portEntry]{
+	{name: "ID", value: func(e portEntry) string { return e.ID }},
+	{name: "UUID", value: func(e portEntry) string { return e.UUID }},
+	{name: "STATUS", value: func(e portEntry) string { return e.Status }},
+	{name: "PORTS", value: func(e portEntry) string { return utils.FormatPorts(e.HTTPPorts) }},
+}
+
 func init() {
 	portsListCmd.SetHelpFunc(wrapHelp(helpmenus.RenderPortsListHelp))
 	portsCmd.AddCommand(portsListCmd)
@@ -48,23 +65,13 @@ func runPortsList() error {
 		return fmt.Errorf("failed to fetch instances: %w", err)
 	}
 
-	if len(instances) == 0 {
-		if JSONOutput {
-			printJSON([]any{})
-			return nil
-		}
+	if len(instances) == 0 && !JSONOutput {
 		PrintWarningSimple("No instances found. Use 'tnr create' to create a Thunder Compute instance.")
 		return nil
 	}
 
-	if JSONOutput {
-		type portEntry struct {
-			ID        string `json:"id"`
-			UUID      string `json:"uuid"`
-			Status    string `json:"status"`
-			HTTPPorts []int  `json:"http_ports"`
-		}
-		var entries []portEntry
+	if JSONOutput || output.kind != "" {
+		entries := make([]portEntry, 0, len(instances))
 		for _, inst := range instances {
 			entries = append(entries, portEntry{
 				ID:        inst.ID,
@@ -73,8 +80,7 @@ func runPortsList() error {
 				HTTPPorts: inst.HTTPPorts,
 			})
 		}
-		printJSON(entries)
-		return nil
+		return printList(entries, portColumns)
 	}
 
 	// Initialize styles
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+)
+
+var powerWait bool
+
+var startCmd = &cobra.Command{
+	Use:   "start [instance_id]",
+	Short: "Start a stopped instance",
+	Args:  wrapArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPower(tui.StartAction, args)
+	},
+}
+
+var stopCmd = &cobra.Command{
+	Use:   "stop [instance_id]",
+	Short: "Stop a running instance, keeping its disk",
+	Args:  wrapArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPower(tui.StopAction, args)
+	},
+}
+
+func init() {
+	startCmd.SetHelpFunc(wrapHelp(helpmenus.RenderStartHelp))
+	stopCmd.SetHelpFunc(wrapHelp(helpmenus.RenderStopHelp))
+
+	startCmd.Flags().BoolVar(&powerWait, "wait", false, "Wait until the instance is running")
+	stopCmd.Flags().BoolVar(&powerWait, "wait", false, "Wait until the instance is stopped")
+
+	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(stopCmd)
+}
+
+func runPower(action tui.PowerAction, args []string) error {
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	interactive := tui.IsInteractive() && !JSONOutput
+	if len(args) == 0 && !interactive {
+		return usageErr("instance ID required in non-interactive mode")
+	}
+
+	var instances []api.Instance
+	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		instances, e = client.ListInstances()
+		return e
+	}); err != nil {
+		return fmt.Errorf("failed to fetch instances: %w", err)
+	}
+
+	var selectedInstance *api.Instance
+	if len(args) == 0 {
+		candidates := powerCandidates(instances, action)
+		if len(candidates) == 0 {
+			PrintWarningSimple(fmt.Sprintf("No %s instances to %s.", strings.ToLower(action.FromStatus), action.Verb))
+			return nil
+		}
+		selectedInstance, err = tui.RunPowerSelector(action, candidates)
+		if err != nil {
+			if errors.Is(err, tui.ErrCancelled) {
+				PrintWarningSimple(fmt.Sprintf("User cancelled %s", action.Verb))
+				return nil
+			}
+			return err
+		}
+	} else {
+		selectedInstance = findInstance(instances, args[0])
+		if selectedInstance == nil {
+			return usageErr("instance '%s' not found", args[0])
+		}
+		if err := checkPowerStatus(action, selectedInstance); err != nil {
+			return err
+		}
+	}
+	instanceID := selectedInstance.ID
+
+	waitCondition := waitRunning
+	if action.ToStatus == "STOPPED" {
+		waitCondition = waitStopped
+	}
+
+	if !interactive {
+		if !YesFlag {
+			return usageErr("use --yes to confirm %s in non-interactive mode", action.Verb)
+		}
+		fmt.Fprintf(os.Stderr, action.Progress+"\n", instanceID)
+		resp, err := action.Do(context.Background(), client, instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to %s instance: %w", action.Verb, err)
+		}
+		if !JSONOutput {
+			fmt.Printf("%s instance %s\n", action.Past, instanceID)
+		}
+		if powerWait {
+			if _, err := waitWithSpinner(client, selectedInstance.UUID, waitCondition, defaultWaitTimeout, 0); err != nil {
+				return err
+			}
+		}
+		if JSONOutput {
+			printResult(resp)
+		}
+		return nil
+	}
+
+	successMsg, err := tui.RunPowerProgress(client, action, instanceID)
+	if err != nil {
+		if !isUserError(err) {
+			sentry.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("operation", action.Verb+"_instance")
+				sentry.CaptureException(err)
+			})
+		}
+		return fmt.Errorf("failed to %s instance: %w", action.Verb, err)
+	}
+	if successMsg == "" {
+		return nil
+	}
+
+	if !powerWait {
+		PrintSuccessSimple(successMsg)
+		return nil
+	}
+	if _, err := waitWithSpinner(client, selectedInstance.UUID, waitCondition, defaultWaitTimeout, 0); err != nil {
+		return err
+	}
+	PrintSuccessSimple(fmt.Sprintf("Instance %s is %s", instanceID, waitCondition))
+	return nil
+}
+
+// powerCandidates returns the instances action applies to.
+func powerCandidates(instances []api.Instance, action tui.PowerAction) []api.Instance {
+	var candidates []api.Instance
+	for _, inst := range instances {
+		if inst.Status == action.FromStatus {
+			candidates = append(candidates, inst)
+		}
+	}
+	return candidates
+}
+
+func checkPowerStatus(action tui.PowerAction, instance *api.Instance) error {
+	switch instance.Status {
+	case action.FromStatus:
+		return nil
+	case action.Transition, action.ToStatus:
+		return usageErr("instance '%s' is already %s", instance.ID, strings.ToLower(instance.Status))
+	}
+	return usageErr("instance '%s' must be %s to %s (status: %s)", instance.ID, action.FromStatus, action.Verb, instance.Status)
+}
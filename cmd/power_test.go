@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+)
+
+func TestCheckPowerStatus(t *testing.T) {
+	tests := []struct {
+		action tui.PowerAction
+		status string
+		want   string
+	}{
+		{tui.StopAction, "RUNNING", ""},
+		{tui.StopAction, "STOPPING", "instance '3' is already stopping"},
+		{tui.StopAction, "STOPPED", "instance '3' is already stopped"},
+		{tui.StopAction, "PROVISIONING", "instance '3' must be RUNNING to stop (status: PROVISIONING)"},
+		{tui.StartAction, "STOPPED", ""},
+		{tui.StartAction, "RUNNING", "instance '3' is already running"},
+		{tui.StartAction, "DELETING", "instance '3' must be STOPPED to start (status: DELETING)"},
+	}
+	for _, tt := range tests {
+		err := checkPowerStatus(tt.action, &api.Instance{ID: "3", Status: tt.status})
+		if tt.want == "" {
+			assert.NoError(t, err, "%s %s", tt.action.Verb, tt.status)
+			continue
+		}
+		if assert.Error(t, err, "%s %s", tt.action.Verb, tt.status) {
+			assert.True(t, errors.Is(err, ErrUsage))
+			assert.Contains(t, err.Error(), tt.want)
+		}
+	}
+}
+
+func TestPowerCandidates(t *testing.T) {
+	instances := []api.Instance{{ID: "0", Status: "RUNNING"}, {ID: "1", Status: "STOPPED"}, {ID: "2", Status: "RUNNING"}}
+	assert.Equal(t, []api.Instance{instances[0], instances[2]}, powerCandidates(instances, tui.StopAction))
+	assert.Equal(t, []api.Instance{instances[1]}, powerCandidates(instances, tui.StartAction))
+}
@@ -11,8 +11,10 @@ func PrintError(err error) {
 	if err == nil {
 		return
 	}
-	if JSONOutput {
-		printJSON(map[string]string{"error": err.Error()})
+	// Templates, jsonpath and csv describe the result, not errors, which
+	// go to stderr like in the default output.
+	if JSONOutput && (output.kind == "" || output.kind == "json" || output.kind == "yaml") {
+		_ = writeOutput(os.Stdout, map[string]string{"error": err.Error()})
 		return
 	}
 	fmt.Fprintln(os.Stderr, tui.RenderError(err))
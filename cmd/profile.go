@@ -0,0 +1,415 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+const defaultProfile = utils.DefaultProfile
+
+// ProfileFlag is the global --profile flag; TNR_PROFILE and then the profile
+// chosen with tnr profile use apply when it is unset.
+var ProfileFlag string
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// configLockTimeout bounds the wait for another tnr process rewriting
+// cli_config.json.
+const configLockTimeout = 15 * time.Second
+
+// configFile is cli_config.json. The default profile stays at the top level,
+// where versions of tnr without profiles look for it; the others are kept
+// under profiles.
+type configFile struct {
+	Config
+	CurrentProfile string             `json:"current_profile,omitempty"`
+	Profiles       map[string]*Config `json:"profiles,omitempty"`
+}
+
+func cliConfigPath() (string, error) {
+	configDir, err := utils.ThunderDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "cli_config.json"), nil
+}
+
+// readConfigFile reads cli_config.json, returning os.ErrNotExist if there
+// is none.
+func readConfigFile() (*configFile, error) {
+	path, err := cliConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file configFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// readConfigFileOrEmpty is readConfigFile that treats a missing file as empty.
+func readConfigFileOrEmpty() (*configFile, error) {
+	file, err := readConfigFile()
+	if errors.Is(err, os.ErrNotExist) {
+		return &configFile{}, nil
+	}
+	return file, err
+}
+
+// errConfigUnchanged is returned by an updateConfigFile callback to leave
+// cli_config.json as it is.
+var errConfigUnchanged = errors.New("config unchanged")
+
+// updateConfigFile applies update to cli_config.json and saves the result.
+// Every rewrite of the file goes through here, holding the config lock from
+// read to write, so that concurrent tnr processes can't write back a refresh
+// token another one has already rotated. The file is removed once the last
+// profile in it is deleted.
+func updateConfigFile(update func(*configFile) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), configLockTimeout)
+	defer cancel()
+	return updateConfigFileCtx(ctx, update)
+}
+
+// updateConfigFileCtx is updateConfigFile that gives up waiting for the
+// lock when ctx is done.
+func updateConfigFileCtx(ctx context.Context, update func(*configFile) error) error {
+	release, err := utils.LockConfigFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	file, err := readConfigFileOrEmpty()
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	hadProfiles := len(file.profileNames()) > 0
+	if err := update(file); err != nil {
+		if errors.Is(err, errConfigUnchanged) {
+			return nil
+		}
+		return err
+	}
+	if hadProfiles && len(file.profileNames()) == 0 {
+		path, err := cliConfigPath()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove config file: %w", err)
+		}
+		return nil
+	}
+	if err := writeConfigFile(file); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// writeConfigFile replaces cli_config.json with file. Callers other than
+// tests go through updateConfigFile.
+func writeConfigFile(file *configFile) error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	if len(file.Profiles) == 0 {
+		file.Profiles = nil
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	// A temp file of its own keeps a concurrent writer from truncating or
+	// renaming this one half-written.
+	tmp, err := os.CreateTemp(filepath.Dir(path), "cli_config-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// profile returns the named profile, or nil if it has not been logged in.
+func (f *configFile) profile(name string) *Config {
+	if name == defaultProfile {
+		if f.Token == "" && f.CredentialStore == "" && f.APIURL == "" && f.MonthlyBudget == 0 {
+			return nil
+		}
+		return &f.Config
+	}
+	return f.Profiles[name]
+}
+
+func (f *configFile) setProfile(name string, config *Config) {
+	if name == defaultProfile {
+		f.Config = *config
+		return
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]*Config{}
+	}
+	f.Profiles[name] = config
+}
+
+func (f *configFile) deleteProfile(name string) {
+	if name == defaultProfile {
+		f.Config = Config{}
+	} else {
+		delete(f.Profiles, name)
+	}
+	if f.CurrentProfile == name {
+		f.CurrentProfile = ""
+	}
+}
+
+// profileNames lists the profiles in file, the default profile first.
+func (f *configFile) profileNames() []string {
+	var names []string
+	if f.profile(defaultProfile) != nil {
+		names = append(names, defaultProfile)
+	}
+	others := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		others = append(others, name)
+	}
+	sort.Strings(others)
+	return append(names, others...)
+}
+
+// activeProfile resolves which profile this invocation uses: --profile,
+// then TNR_PROFILE, then the one chosen with tnr profile use. file may be
+// nil.
+func activeProfile(file *configFile) string {
+	if ProfileFlag != "" {
+		return ProfileFlag
+	}
+	if env := os.Getenv("TNR_PROFILE"); env != "" {
+		return env
+	}
+	if file != nil && file.CurrentProfile != "" {
+		return file.CurrentProfile
+	}
+	return defaultProfile
+}
+
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return usageErr("invalid profile name '%s': use up to 32 letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// initProfile resolves the active profile before a command runs, so that
+// SSH keys and host aliases are namespaced by it.
+func initProfile() error {
+	file, _ := readConfigFile()
+	name := activeProfile(file)
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	utils.SetProfile(name)
+	return nil
+}
+
+// loginHint tells the user how to log in to the active profile.
+func loginHint() string {
+	if name := utils.Profile(); name != defaultProfile {
+		return fmt.Sprintf("tnr login --profile %s", name)
+	}
+	return "tnr login"
+}
+
+var profileCmd = &cobra.Command{
+	Use:     "profile",
+	Aliases: []string{"profiles"},
+	Short:   "Manage named auth profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List auth profiles",
+	Args:  wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileList()
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the default for later commands",
+	Args:  wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileUse(args[0])
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile's saved credentials",
+	Args:  wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileDelete(args[0])
+	},
+}
+
+func init() {
+	profileCmd.SetHelpFunc(wrapHelp(helpmenus.RenderProfileHelp))
+	profileListCmd.SetHelpFunc(wrapHelp(helpmenus.RenderProfileHelp))
+	profileUseCmd.SetHelpFunc(wrapHelp(helpmenus.RenderProfileHelp))
+	profileDeleteCmd.SetHelpFunc(wrapHelp(helpmenus.RenderProfileHelp))
+
+	profileCmd.AddCommand(profileListCmd, profileUseCmd, profileDeleteCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+// profileInfo is one entry of tnr profile list --json.
+type profileInfo struct {
+	Name            string `json:"name"`
+	Active          bool   `json:"active"`
+	APIURL          string `json:"api_url,omitempty"`
+	CredentialStore string `json:"credential_store"`
+	Expired         bool   `json:"expired"`
+}
+
+func runProfileList() error {
+	file, err := readConfigFileOrEmpty()
+	if err != nil {
+		return err
+	}
+	active := activeProfile(file)
+
+	profiles := []profileInfo{}
+	for _, name := range file.profileNames() {
+		config := file.profile(name)
+		store := config.CredentialStore
+		if store == "" {
+			store = utils.CredentialStorePlaintext
+		}
+		profiles = append(profiles, profileInfo{
+			Name:            name,
+			Active:          name == active,
+			APIURL:          config.APIURL,
+			CredentialStore: store,
+			Expired:         config.isExpired(),
+		})
+	}
+
+	if JSONOutput {
+		return printList(profiles, profileColumns)
+	}
+	if len(profiles) == 0 {
+		PrintWarningSimple("No profiles. Run 'tnr login' or 'tnr login --profile <name>' to create one.")
+		return nil
+	}
+	return printList(profiles, profileColumns)
+}
+
+// profileColumns are the columns of tnr profile list in table, wide and csv
+// output. The unnamed first one marks the active profile.
+var profileColumns = []column[profileInfo]{
+	{name: "", value: func(p profileInfo) string {
+		if p.Active {
+			return "*"
+		}
+		return ""
+	}},
+	{name: "PROFILE", value: func(p profileInfo) string { return p.Name }},
+	{name: "API URL", value: func(p profileInfo) string {
+		if p.APIURL == "" {
+			return DefaultAPIURL
+		}
+		return p.APIURL
+	}},
+	{name: "STORE", value: func(p profileInfo) string { return p.CredentialStore }},
+	{name: "TOKEN", value: func(p profileInfo) string {
+		if p.Expired {
+			return "expired"
+		}
+		return "valid"
+	}},
+}
+
+func runProfileUse(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	err := updateConfigFile(func(file *configFile) error {
+		if file.profile(name) == nil {
+			return usageErr("profile '%s' not found. Run 'tnr login --profile %s' to create it", name, name)
+		}
+		file.CurrentProfile = name
+		if name == defaultProfile {
+			file.CurrentProfile = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if JSONOutput {
+		printResult(map[string]string{"profile": name})
+	} else {
+		PrintSuccessSimple(fmt.Sprintf("Now using profile '%s'", name))
+		if env := os.Getenv("TNR_PROFILE"); env != "" && env != name {
+			PrintWarningSimple(fmt.Sprintf("TNR_PROFILE=%s still takes precedence in this shell", env))
+		}
+	}
+	return nil
+}
+
+func runProfileDelete(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	err := updateConfigFile(func(file *configFile) error {
+		config := file.profile(name)
+		if config == nil {
+			return usageErr("profile '%s' not found", name)
+		}
+		if config.CredentialStore != "" {
+			forgetCredentials(name, config.CredentialStore)
+		}
+		file.deleteProfile(name)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if JSONOutput {
+		printResult(map[string]string{"deleted": name})
+	} else {
+		PrintSuccessSimple(fmt.Sprintf("Deleted profile '%s'", name))
+	}
+	return nil
+}
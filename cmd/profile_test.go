@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func testProfileEnv(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TNR_HOME", dir)
+	t.Setenv("TNR_PROFILE", "")
+	t.Setenv("TNR_API_TOKEN", "")
+	t.Setenv("TNR_API_URL", "")
+	ProfileFlag = ""
+	t.Cleanup(func() {
+		ProfileFlag = ""
+		utils.SetProfile(defaultProfile)
+	})
+	return filepath.Join(dir, "cli_config.json")
+}
+
+func TestSaveConfigProfiles(t *testing.T) {
+	path := testProfileEnv(t)
+
+	require.NoError(t, saveConfig(AuthResponse{Token: "personal"}))
+	ProfileFlag = "team"
+	require.NoError(t, saveConfig(AuthResponse{Token: "work"}))
+
+	// The default profile stays where versions without profiles read it.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var legacy Config
+	require.NoError(t, json.Unmarshal(data, &legacy))
+	assert.Equal(t, "personal", legacy.Token)
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "work", config.Token)
+
+	ProfileFlag = ""
+	config, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "personal", config.Token)
+
+	t.Setenv("TNR_PROFILE", "team")
+	config, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "work", config.Token)
+
+	t.Setenv("TNR_PROFILE", "missing")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestSaveConfigKeepsProfileSettings(t *testing.T) {
+	testProfileEnv(t)
+	ProfileFlag = "team"
+
+	require.NoError(t, runSetBudget(250))
+	require.NoError(t, saveConfig(AuthResponse{Token: "work"}))
+	assert.Equal(t, 250.0, loadMonthlyBudget())
+
+	ProfileFlag = ""
+	assert.Zero(t, loadMonthlyBudget())
+}
+
+func TestProfileUseAndDelete(t *testing.T) {
+	testProfileEnv(t)
+	require.NoError(t, saveConfig(AuthResponse{Token: "personal"}))
+	ProfileFlag = "team"
+	require.NoError(t, saveConfig(AuthResponse{Token: "work"}))
+	ProfileFlag = ""
+
+	require.NoError(t, runProfileUse("team"))
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "work", config.Token)
+
+	assert.ErrorIs(t, runProfileUse("nope"), ErrUsage)
+	assert.ErrorIs(t, runProfileUse("../keys"), ErrUsage)
+
+	// Deleting the current profile falls back to the default.
+	require.NoError(t, runProfileDelete("team"))
+	config, err = LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "personal", config.Token)
+
+	file, err := readConfigFile()
+	require.NoError(t, err)
+	assert.Equal(t, []string{defaultProfile}, file.profileNames())
+	assert.Empty(t, file.CurrentProfile)
+}
+
+func TestLogoutRemovesActiveProfileOnly(t *testing.T) {
+	path := testProfileEnv(t)
+	require.NoError(t, saveConfig(AuthResponse{Token: "personal"}))
+	ProfileFlag = "team"
+	require.NoError(t, saveConfig(AuthResponse{Token: "work"}))
+
+	require.NoError(t, runLogout())
+	_, err := LoadConfig()
+	assert.Error(t, err)
+
+	ProfileFlag = ""
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "personal", config.Token)
+
+	require.NoError(t, runLogout())
+	assert.NoFileExists(t, path)
+}
+
+// TestSetBudgetWaitsForConfigLock checks that a config rewrite waits for a
+// token refresh in another process and keeps the token it saved.
+func TestSetBudgetWaitsForConfigLock(t *testing.T) {
+	testProfileEnv(t)
+	require.NoError(t, saveConfig(AuthResponse{Token: "old", RefreshToken: "r1"}))
+
+	release, err := utils.LockConfigFile(context.Background())
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() { done <- runSetBudget(100) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("set budget did not wait for the lock: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	file, err := readConfigFile()
+	require.NoError(t, err)
+	file.Token, file.RefreshToken = "new", "r2"
+	require.NoError(t, writeConfigFile(file))
+	release()
+	require.NoError(t, <-done)
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "new", config.Token)
+	assert.Equal(t, "r2", config.RefreshToken)
+	assert.Equal(t, 100.0, config.MonthlyBudget)
+}
+
+func TestInitProfile(t *testing.T) {
+	testProfileEnv(t)
+
+	t.Setenv("TNR_PROFILE", "team")
+	require.NoError(t, initProfile())
+	assert.Equal(t, "team", utils.Profile())
+	assert.Equal(t, "tnr login --profile team", loginHint())
+
+	ProfileFlag = "bad/name"
+	assert.ErrorIs(t, initProfile(), ErrUsage)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// tokenRefreshLeeway is how close to ExpiresAt a token is refreshed before
+// being sent, so it does not expire mid-command.
+const tokenRefreshLeeway = 2 * time.Minute
+
+// newAPIClient returns a client for config that refreshes the token with
+// the saved refresh token when it expires.
+func newAPIClient(config *Config) *api.Client {
+	client := api.NewClient(config.Token, config.APIURL)
+	if config.profile != "" && config.RefreshToken != "" {
+		client.SetTokenSource(&profileTokenSource{
+			profile:      config.profile,
+			apiURL:       config.APIURL,
+			token:        config.Token,
+			refreshToken: config.RefreshToken,
+			expiresAt:    config.ExpiresAt,
+		})
+	}
+	return client
+}
+
+// profileTokenSource keeps a profile's token fresh, saving refreshed tokens
+// back where LoadConfig found them.
+type profileTokenSource struct {
+	profile string
+	apiURL  string
+
+	mu           sync.Mutex
+	token        string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+func (s *profileTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expiresAt.IsZero() || time.Until(s.expiresAt) > tokenRefreshLeeway {
+		return s.token, nil
+	}
+	if err := s.refreshLocked(ctx, s.token); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+func (s *profileTokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != rejected {
+		return s.token, nil
+	}
+	if err := s.refreshLocked(ctx, rejected); err != nil {
+		return "", err
+	}
+	return s.token, nil
+}
+
+// refreshLocked exchanges the refresh token and saves the result. It holds
+// the config file lock throughout, and if another process refreshed stale
+// first it takes that token instead of spending the refresh token again,
+// which the server may have rotated.
+func (s *profileTokenSource) refreshLocked(ctx context.Context, stale string) error {
+	lockCtx, cancel := context.WithTimeout(ctx, configLockTimeout)
+	defer cancel()
+	var current Config
+	err := updateConfigFileCtx(lockCtx, func(file *configFile) error {
+		saved := file.profile(s.profile)
+		if saved == nil {
+			return fmt.Errorf("profile '%s' was logged out", s.profile)
+		}
+		current = *saved
+		if err := resolveCredentials(s.profile, &current); err != nil {
+			return err
+		}
+		if current.Token != stale && current.Token != "" && !current.isExpired() {
+			return errConfigUnchanged
+		}
+		if current.RefreshToken == "" {
+			return fmt.Errorf("no refresh token saved for profile '%s'", s.profile)
+		}
+
+		resp, err := api.NewClient("", s.apiURL).RefreshToken(ctx, current.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to refresh token: %w", err)
+		}
+		current.Token = resp.Token
+		if resp.RefreshToken != "" {
+			current.RefreshToken = resp.RefreshToken
+		}
+		current.ExpiresAt = time.Time{}
+		if resp.ExpiresIn > 0 {
+			current.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+		}
+
+		if saved.CredentialStore == "" {
+			saved.Token, saved.RefreshToken = current.Token, current.RefreshToken
+		} else {
+			store, err := openCredentialStore(saved.CredentialStore)
+			if err != nil {
+				return err
+			}
+			if err := store.Set(s.profile, &utils.Credentials{Token: current.Token, RefreshToken: current.RefreshToken}); err != nil {
+				return fmt.Errorf("failed to save refreshed token: %w", err)
+			}
+		}
+		saved.ExpiresAt = current.ExpiresAt
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.token, s.refreshToken, s.expiresAt = current.Token, current.RefreshToken, current.ExpiresAt
+	return nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// fakeAuthServer accepts only its current access token and rotates the
+// refresh token on every exchange, rejecting one that was already spent.
+type fakeAuthServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	token     string
+	refresh   string
+	refreshes atomic.Int32
+}
+
+func newFakeAuthServer(t *testing.T, token, refresh string) *fakeAuthServer {
+	f := &fakeAuthServer{token: token, refresh: refresh}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.URL.Path == "/v1/auth/refresh" {
+			var req struct {
+				RefreshToken string `json:"refresh_token"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.RefreshToken != f.refresh {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			// Widen the window for concurrent refreshes to collide.
+			time.Sleep(20 * time.Millisecond)
+			n := f.refreshes.Add(1)
+			f.token = fmt.Sprintf("access-%d", n)
+			f.refresh = fmt.Sprintf("refresh-%d", n)
+			_ = json.NewEncoder(w).Encode(map[string]any{"token": f.token, "refresh_token": f.refresh, "expires_in": 3600})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"valid":true}`))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func loginForRefresh(t *testing.T, server *fakeAuthServer, expiresIn int) string {
+	t.Helper()
+	path := testProfileEnv(t)
+	require.NoError(t, saveConfig(AuthResponse{Token: "access-0", RefreshToken: "refresh-0", ExpiresIn: expiresIn}))
+	t.Setenv("TNR_API_URL", server.URL)
+	return path
+}
+
+func validate(t *testing.T) error {
+	t.Helper()
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	_, err = newAPIClient(config).ValidateToken(context.Background())
+	return err
+}
+
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	server := newFakeAuthServer(t, "access-1", "refresh-0")
+	path := loginForRefresh(t, server, 30)
+
+	require.NoError(t, validate(t))
+	assert.EqualValues(t, 1, server.refreshes.Load())
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", config.Token)
+	assert.Equal(t, "refresh-1", config.RefreshToken)
+	assert.True(t, config.ExpiresAt.After(time.Now().Add(time.Hour-time.Minute)))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestTokenRefreshedOn401(t *testing.T) {
+	// The server revoked access-0 although it has not reached ExpiresAt.
+	server := newFakeAuthServer(t, "revoked", "refresh-0")
+	loginForRefresh(t, server, 3600)
+
+	require.NoError(t, validate(t))
+	assert.EqualValues(t, 1, server.refreshes.Load())
+
+	// Without a working refresh token the 401 comes back.
+	server.mu.Lock()
+	server.token, server.refresh = "revoked", "gone"
+	server.mu.Unlock()
+	assert.ErrorContains(t, validate(t), "invalid token")
+}
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	server := newFakeAuthServer(t, "access-1", "refresh-0")
+	loginForRefresh(t, server, 0)
+	// Simulate an expired token from an earlier login.
+	file, err := readConfigFile()
+	require.NoError(t, err)
+	file.ExpiresAt = time.Now().Add(-time.Minute)
+	require.NoError(t, writeConfigFile(file))
+
+	// Each client stands in for a separate tnr process: it has its own
+	// token source and only the config file and its lock in common.
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		config, err := LoadConfig()
+		require.NoError(t, err)
+		client := newAPIClient(config)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = client.ValidateToken(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, server.refreshes.Load(), "the refresh token is spent once")
+}
+
+func TestTokenRefreshUsesCredentialStore(t *testing.T) {
+	server := newFakeAuthServer(t, "access-1", "refresh-0")
+	store := useMemoryCredentialStore(t)
+	t.Setenv("TNR_CREDENTIAL_STORE", utils.CredentialStoreKeychain)
+	path := loginForRefresh(t, server, 30)
+
+	require.NoError(t, validate(t))
+
+	creds, err := store.Get(defaultProfile)
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", creds.Token)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "access-1")
+}
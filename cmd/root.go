@@ -73,6 +73,13 @@ func Execute() int {
 	}()
 
 	c, err := rootCmd.ExecuteC()
+	if err == nil {
+		err = outputErr
+	}
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
 	if err != nil {
 		if !isUserError(err) {
 			sentry.WithScope(func(scope *sentry.Scope) {
@@ -124,7 +131,8 @@ func isTransientNetworkError(err error) bool {
 }
 
 func isUserError(err error) bool {
-	if errors.Is(err, ErrUsage) || errors.Is(err, tui.ErrCancelled) || errors.Is(err, utils.ErrTransferUser) {
+	if errors.Is(err, ErrUsage) || errors.Is(err, tui.ErrCancelled) || errors.Is(err, utils.ErrTransferUser) ||
+		errors.Is(err, errWaitTimeout) {
 		return true
 	}
 	if errors.Is(err, utils.ErrSSHUnreachable) {
@@ -186,7 +194,16 @@ func init() {
 	})
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		tui.SetNonInteractive(JSONOutput)
+		format, err := parseOutputFlag(OutputFlag, JSONOutput, ColumnsFlag)
+		if err != nil {
+			return err
+		}
+		output, outputErr = format, nil
+		JSONOutput = output.structured()
+		tui.SetNonInteractive(output.kind != "")
+		if err := initProfile(); err != nil {
+			return err
+		}
 		checkIfUpdateNeeded(cmd)
 		return nil
 	}
@@ -244,7 +261,10 @@ func init() {
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.thunder-cli-draft.yaml)")
 
 	rootCmd.PersistentFlags().BoolVar(&JSONOutput, "json", false, "Output in JSON format (non-interactive)")
+	rootCmd.PersistentFlags().StringVarP(&OutputFlag, "output", "o", "", "Output format: "+outputFormats)
+	rootCmd.PersistentFlags().StringSliceVar(&ColumnsFlag, "columns", nil, "Columns to show in table, wide and csv output, e.g. id,status,address")
 	rootCmd.PersistentFlags().BoolVarP(&YesFlag, "yes", "y", false, "Skip confirmation prompts")
+	rootCmd.PersistentFlags().StringVar(&ProfileFlag, "profile", "", "Auth profile to use (overrides TNR_PROFILE)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -9,15 +9,19 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/spf13/cobra"
 
 	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
 	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
 	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
+var scpJobs int
+
 var scpCmd = &cobra.Command{
 	Use:          "scp [source...] [destination]",
 	Short:        "Copy files between local machine and Thunder Compute instances",
@@ -26,12 +30,14 @@ var scpCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sources := args[:len(args)-1]
 		destination := args[len(args)-1]
-		return runSCP(sources, destination)
+		return runSCP(sources, destination, scpJobs)
 	},
 }
 
 func init() {
 	scpCmd.SetHelpFunc(wrapHelp(helpmenus.RenderSCPHelp))
+	scpCmd.Flags().IntVarP(&scpJobs, "jobs", "j", utils.DefaultTransferWorkers,
+		fmt.Sprintf("Number of files to copy at once (1-%d)", utils.MaxTransferWorkers))
 	rootCmd.AddCommand(scpCmd)
 }
 
@@ -72,7 +78,11 @@ func isValidInstanceID(s string) bool {
 	return len(s) > 0 && len(s) <= 20 && !strings.ContainsAny(s, "/\\.")
 }
 
-func runSCP(sources []string, destination string) error {
+func runSCP(sources []string, destination string, jobs int) error {
+	if jobs < 1 || jobs > utils.MaxTransferWorkers {
+		return usageErr("--jobs must be between 1 and %d", utils.MaxTransferWorkers)
+	}
+
 	config, err := LoadConfig()
 	if err != nil || config.Token == "" {
 		return usageErr("not authenticated. Please run 'tnr login' first")
@@ -89,29 +99,98 @@ func runSCP(sources []string, destination string) error {
 		return err
 	}
 
-	client := api.NewClient(config.Token, config.APIURL)
-	instances, err := client.ListInstances()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := newAPIClient(config)
+	instances, err := client.ListInstancesCtx(ctx)
 	if err != nil {
 		return utils.WrapAPIError(err, "failed to list instances")
 	}
 
+	target, keyFile, err := prepareSCPInstance(ctx, client, instances, instanceID)
+	if err != nil {
+		return err
+	}
+	// An instance-to-instance copy also needs the destination instance.
+	var destInstance *api.Instance
+	var destKeyFile string
+	if direction == "copy" {
+		if destInstance, destKeyFile, err = prepareSCPInstance(ctx, client, instances, destPath.InstanceID); err != nil {
+			return err
+		}
+	}
+
+	transferSources, transferDest := scpTransferPaths(sourcePaths, destPath, direction)
+	var title string
+	switch direction {
+	case "upload":
+		title = scpTitle(direction, "", target.Name, transferSources, transferDest)
+	case "download":
+		title = scpTitle(direction, target.Name, "", transferSources, transferDest)
+	default:
+		title = scpTitle(direction, target.Name, destInstance.Name, transferSources, transferDest)
+	}
+
+	var report *utils.TransferReport
+	err = tui.RunWithTransferProgress(title, os.Stdout, func(progress utils.TransferProgressFunc) error {
+		var e error
+		if direction == "copy" {
+			from := utils.TransferHost{KeyFile: keyFile, IP: target.GetIP(), Port: target.Port}
+			to := utils.TransferHost{KeyFile: destKeyFile, IP: destInstance.GetIP(), Port: destInstance.Port}
+			report, e = utils.TransferBetween(ctx, from, to, transferSources, transferDest, jobs, progress)
+		} else {
+			report, e = utils.TransferMany(ctx, keyFile, target.GetIP(), target.Port, transferSources, transferDest, direction == "upload", jobs, progress)
+		}
+		return e
+	})
+	if errors.Is(err, utils.ErrTransferCancelled) {
+		fmt.Println("\nTransfer cancelled")
+		return nil
+	}
+
+	if report != nil && len(report.Files) > 0 {
+		if JSONOutput {
+			manifest := newSCPManifest(direction, target.ID, transferDest, report)
+			if destInstance != nil {
+				manifest.DestinationInstanceID = destInstance.ID
+			}
+			printResult(manifest)
+		} else {
+			printTransferReport(report)
+		}
+	}
+	if err != nil {
+		if !isUserError(err) {
+			sentry.WithScope(func(scope *sentry.Scope) {
+				scope.SetTag("operation", "scp_transfer")
+				sentry.CaptureException(err)
+			})
+		}
+		return err
+	}
+	return nil
+}
+
+// prepareSCPInstance finds the running instance id refers to and makes sure
+// there is an SSH key for it, adding one if needed. It returns the key file.
+func prepareSCPInstance(ctx context.Context, client *api.Client, instances []api.Instance, id string) (*api.Instance, string, error) {
 	var target *api.Instance
 	for i, inst := range instances {
-		if inst.ID == instanceID || inst.UUID == instanceID {
+		if inst.ID == id || inst.UUID == id {
 			target = &instances[i]
 			break
 		}
 	}
 	if target == nil {
-		return usageErr("instance '%s' not found", instanceID)
+		return nil, "", usageErr("instance '%s' not found", id)
 	}
 	if target.Status != "RUNNING" {
-		return usageErr("instance '%s' is not running (status: %s)", instanceID, target.Status)
+		return nil, "", usageErr("instance '%s' is not running (status: %s)", id, target.Status)
 	}
 
-	keyFile := utils.GetKeyFile(target.UUID)
 	if !utils.KeyExists(target.UUID) {
-		keyResp, err := client.AddSSHKey(target.ID)
+		keyResp, err := client.AddSSHKeyCtx(ctx, target.ID)
 		if err != nil {
 			if !isUserError(err) {
 				sentry.WithScope(func(scope *sentry.Scope) {
@@ -119,7 +198,7 @@ func runSCP(sources []string, destination string) error {
 					sentry.CaptureException(err)
 				})
 			}
-			return fmt.Errorf("failed to add SSH key: %w", err)
+			return nil, "", fmt.Errorf("failed to add SSH key: %w", err)
 		}
 		if keyResp.Key != nil {
 			if err := utils.SavePrivateKey(target.UUID, *keyResp.Key); err != nil {
@@ -129,59 +208,122 @@ func runSCP(sources []string, destination string) error {
 						sentry.CaptureException(err)
 					})
 				}
-				return fmt.Errorf("failed to save private key: %w", err)
+				return nil, "", fmt.Errorf("failed to save private key: %w", err)
 			}
 		}
-		keyFile = utils.GetKeyFile(target.UUID)
 	}
+	return target, utils.GetKeyFile(target.UUID), nil
+}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-	defer stop()
+// scpTransferPaths expands local ~/ paths and returns the transfer's sources
+// and destination, defaulting an empty remote destination to the home
+// directory.
+func scpTransferPaths(sources []PathInfo, dest PathInfo, direction string) ([]string, string) {
+	paths := make([]string, len(sources))
+	for i, src := range sources {
+		paths[i] = src.Path
+		if !src.IsRemote {
+			paths[i] = expandLocalHome(src.Path)
+		}
+	}
+	if !dest.IsRemote {
+		return paths, expandLocalHome(dest.Path)
+	}
+	if dest.Path == "" {
+		return paths, "./"
+	}
+	return paths, dest.Path
+}
 
-	// Transfer each source
-	for _, src := range sourcePaths {
-		localPath := src.Path
-		remotePath := destPath.Path
+func expandLocalHome(p string) string {
+	if strings.HasPrefix(p, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, p[2:])
+	}
+	return p
+}
 
-		if direction == "upload" {
-			if strings.HasPrefix(localPath, "~/") {
-				homeDir, _ := os.UserHomeDir()
-				localPath = filepath.Join(homeDir, localPath[2:])
-			}
-			if remotePath == "" {
-				remotePath = "./"
-			}
-			fmt.Printf("Uploading %s to %s:%s\n", localPath, target.Name, remotePath)
-		} else {
-			remotePath = src.Path
-			localPath = destPath.Path
-			if strings.HasPrefix(localPath, "~/") {
-				homeDir, _ := os.UserHomeDir()
-				localPath = filepath.Join(homeDir, localPath[2:])
-			}
-			fmt.Printf("Downloading %s:%s to %s\n", target.Name, remotePath, localPath)
+// scpTitle describes a transfer for its progress view. fromName and toName
+// are the source and destination instance names, empty for the local side.
+func scpTitle(direction, fromName, toName string, sources []string, dest string) string {
+	verb := map[string]string{"upload": "Uploading", "download": "Downloading", "copy": "Copying"}[direction]
+	at := func(name, p string) string {
+		if name == "" {
+			return p
 		}
+		return name + ":" + p
+	}
+	switch {
+	case len(sources) == 1:
+		return fmt.Sprintf("%s %s to %s", verb, at(fromName, sources[0]), at(toName, dest))
+	case fromName == "":
+		return fmt.Sprintf("%s %d items to %s", verb, len(sources), at(toName, dest))
+	default:
+		return fmt.Sprintf("%s %d items from %s to %s", verb, len(sources), fromName, at(toName, dest))
+	}
+}
 
-		err := utils.Transfer(ctx, keyFile, target.GetIP(), target.Port, localPath, remotePath, direction == "upload")
-		if err != nil {
-			if errors.Is(err, utils.ErrTransferCancelled) {
-				fmt.Println("\nTransfer cancelled")
-				return nil
-			}
-			if !isUserError(err) {
-				sentry.WithScope(func(scope *sentry.Scope) {
-					scope.SetTag("operation", "scp_transfer")
-					sentry.CaptureException(err)
-				})
-			}
-			return err
+// scpManifest is the --json output of a transfer.
+type scpManifest struct {
+	Direction  string `json:"direction"`
+	InstanceID string `json:"instance_id"`
+	// DestinationInstanceID is set for instance-to-instance copies, where
+	// InstanceID is the source instance.
+	DestinationInstanceID string                     `json:"destination_instance_id,omitempty"`
+	Destination           string                     `json:"destination"`
+	Copied                int                        `json:"copied"`
+	Skipped               int                        `json:"skipped"`
+	Failed                int                        `json:"failed"`
+	BytesSent             int64                      `json:"bytes_sent"`
+	DurationSeconds       float64                    `json:"duration_seconds"`
+	Files                 []utils.TransferFileResult `json:"files"`
+}
+
+func newSCPManifest(direction, instanceID, dest string, report *utils.TransferReport) scpManifest {
+	return scpManifest{
+		Direction:       direction,
+		InstanceID:      instanceID,
+		Destination:     dest,
+		Copied:          report.Count(utils.TransferCopied),
+		Skipped:         report.Count(utils.TransferSkipped),
+		Failed:          report.Count(utils.TransferFailed),
+		BytesSent:       report.Sent,
+		DurationSeconds: report.Duration.Seconds(),
+		Files:           report.Files,
+	}
+}
+
+// printTransferReport prints the outcome of a transfer, listing the files
+// that failed.
+func printTransferReport(report *utils.TransferReport) {
+	for _, f := range report.Files {
+		if f.Status == utils.TransferFailed {
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %s\n", f.Source, f.Error)
 		}
 	}
+	summary := formatTransferReport(report)
+	if report.Count(utils.TransferFailed) > 0 {
+		PrintWarningSimple("Transfer incomplete: " + summary)
+		return
+	}
+	PrintSuccessSimple("Transfer complete: " + summary)
+}
 
-	fmt.Println("Transfer complete")
-	return nil
+// formatTransferReport renders e.g.
+// "3 copied, 1 skipped, 0 failed (1.2 GB sent in 12s, 100.0 MB/s)".
+func formatTransferReport(report *utils.TransferReport) string {
+	summary := fmt.Sprintf("%d copied, %d skipped, %d failed (%s sent in %s",
+		report.Count(utils.TransferCopied), report.Count(utils.TransferSkipped), report.Count(utils.TransferFailed),
+		tui.FormatBytes(report.Sent), report.Duration.Round(100*time.Millisecond))
+	if secs := report.Duration.Seconds(); secs > 0 && report.Sent > 0 {
+		summary += fmt.Sprintf(", %s/s", tui.FormatBytes(int64(float64(report.Sent)/secs)))
+	}
+	return summary + ")"
 }
 
+// determineTransferDirection returns "upload", "download" or, when both sides
+// are remote, "copy", along with the instance the sources are on (the
+// destination instance for an upload).
 func determineTransferDirection(sources []PathInfo, dest PathInfo) (string, string, error) {
 	remoteCount := 0
 	var remoteInstanceID string
@@ -192,14 +334,17 @@ func determineTransferDirection(sources []PathInfo, dest PathInfo) (string, stri
 			if remoteInstanceID == "" {
 				remoteInstanceID = src.InstanceID
 			} else if remoteInstanceID != src.InstanceID {
-				return "", "", usageErr("cannot transfer between multiple instances")
+				return "", "", usageErr("cannot transfer from multiple instances at once")
 			}
 		}
 	}
+	if remoteCount > 0 && remoteCount < len(sources) {
+		return "", "", usageErr("cannot mix local and remote sources")
+	}
 
 	if dest.IsRemote {
 		if remoteCount > 0 {
-			return "", "", usageErr("cannot transfer from remote to remote")
+			return "copy", remoteInstanceID, nil
 		}
 		return "upload", dest.InstanceID, nil
 	}
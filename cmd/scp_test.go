@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
 func TestParsePathWithOS(t *testing.T) {
@@ -238,18 +242,25 @@ func TestDetermineTransferDirection(t *testing.T) {
 			wantID:  "inst1",
 		},
 		{
-			name:          "error: remote to remote",
-			sources:       []PathInfo{remote("inst1", "/a.txt")},
-			dest:          remote("inst2", "/b/"),
-			expectError:   true,
-			errorContains: "cannot transfer from remote to remote",
+			name:    "copy: remote to another instance",
+			sources: []PathInfo{remote("inst1", "/a.txt"), remote("inst1", "/b.txt")},
+			dest:    remote("inst2", "/b/"),
+			wantDir: "copy",
+			wantID:  "inst1",
 		},
 		{
 			name:          "error: multiple different instances",
 			sources:       []PathInfo{remote("inst1", "/a.txt"), remote("inst2", "/b.txt")},
 			dest:          local("./"),
 			expectError:   true,
-			errorContains: "cannot transfer between multiple instances",
+			errorContains: "cannot transfer from multiple instances",
+		},
+		{
+			name:          "error: local and remote sources",
+			sources:       []PathInfo{local("a.txt"), remote("inst1", "/b.txt")},
+			dest:          remote("inst2", "/b/"),
+			expectError:   true,
+			errorContains: "cannot mix local and remote sources",
 		},
 		{
 			name:          "error: no remote path at all",
@@ -285,3 +296,53 @@ func TestDetermineTransferDirection_WindowsNumericID(t *testing.T) {
 	assert.Equal(t, "0", instanceID)
 }
 
+func TestSCPTransferPaths(t *testing.T) {
+	sources := []PathInfo{parsePathWithOS("./a", "linux"), parsePathWithOS("./b", "linux")}
+	got, dest := scpTransferPaths(sources, parsePathWithOS("0:", "linux"), "upload")
+	assert.Equal(t, []string{"./a", "./b"}, got)
+	assert.Equal(t, "./", dest)
+
+	sources = []PathInfo{parsePathWithOS("0:/data/a", "linux"), parsePathWithOS("0:~/b", "linux")}
+	got, dest = scpTransferPaths(sources, parsePathWithOS("./out", "linux"), "download")
+	assert.Equal(t, []string{"/data/a", "~/b"}, got)
+	assert.Equal(t, "./out", dest)
+
+	got, dest = scpTransferPaths(sources, parsePathWithOS("1:", "linux"), "copy")
+	assert.Equal(t, []string{"/data/a", "~/b"}, got)
+	assert.Equal(t, "./", dest)
+
+	assert.Equal(t, "Uploading 2 items to gpu:./", scpTitle("upload", "", "gpu", []string{"a", "b"}, "./"))
+	assert.Equal(t, "Downloading gpu:/data/a to ./out", scpTitle("download", "gpu", "", []string{"/data/a"}, "./out"))
+	assert.Equal(t, "Copying proto:/ckpt to prod:/ckpt", scpTitle("copy", "proto", "prod", []string{"/ckpt"}, "/ckpt"))
+	assert.Equal(t, "Copying 2 items from proto to prod:./", scpTitle("copy", "proto", "prod", []string{"a", "b"}, "./"))
+}
+
+func TestRunSCPRejectsInvalidJobs(t *testing.T) {
+	for _, jobs := range []int{0, utils.MaxTransferWorkers + 1} {
+		err := runSCP([]string{"./a"}, "0:/tmp", jobs)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUsage))
+		assert.Contains(t, err.Error(), "--jobs must be between 1 and")
+	}
+}
+
+func TestTransferReportOutput(t *testing.T) {
+	report := &utils.TransferReport{
+		Files: []utils.TransferFileResult{
+			{Source: "a", Destination: "/x/a", Bytes: 1_000_000, Status: utils.TransferCopied},
+			{Source: "b", Destination: "/x/b", Bytes: 500, Status: utils.TransferSkipped},
+			{Source: "c", Destination: "/x/c", Bytes: 10, Status: utils.TransferFailed, Error: "permission denied"},
+		},
+		Sent:     2_000_000,
+		Duration: 2 * time.Second,
+	}
+	assert.Equal(t, "1 copied, 1 skipped, 1 failed (2.0 MB sent in 2s, 1.0 MB/s)", formatTransferReport(report))
+
+	m := newSCPManifest("upload", "0", "/x", report)
+	assert.Equal(t, 1, m.Copied)
+	assert.Equal(t, 1, m.Skipped)
+	assert.Equal(t, 1, m.Failed)
+	assert.Equal(t, int64(2_000_000), m.BytesSent)
+	assert.Equal(t, 2.0, m.DurationSeconds)
+	assert.Len(t, m.Files, 3)
+}
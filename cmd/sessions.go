@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// sessionsCmd represents the sessions parent command
+var sessionsCmd = &cobra.Command{
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Manage named tmux sessions on an instance",
+	Long:    "List and kill the persistent sessions created with 'tnr connect --session <name>'.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Show help when parent command is called without subcommand
+		_ = cmd.Help()
+	},
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:     "list <instance_id>",
+	Aliases: []string{"ls"},
+	Short:   "List tmux sessions on an instance",
+	Args:    wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSessionsList(args[0], defaultSessionsOptions())
+	},
+}
+
+var sessionsKillCmd = &cobra.Command{
+	Use:   "kill <instance_id> <session...>",
+	Short: "Kill tmux sessions on an instance",
+	Args:  wrapArgs(cobra.MinimumNArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSessionsKill(args[0], args[1:], defaultSessionsOptions())
+	},
+}
+
+func init() {
+	sessionsCmd.SetHelpFunc(wrapHelp(helpmenus.RenderSessionsHelp))
+	sessionsListCmd.SetHelpFunc(wrapHelp(helpmenus.RenderSessionsHelp))
+	sessionsKillCmd.SetHelpFunc(wrapHelp(helpmenus.RenderSessionsHelp))
+
+	sessionsCmd.AddCommand(sessionsListCmd, sessionsKillCmd)
+	rootCmd.AddCommand(sessionsCmd)
+}
+
+// mocks for testing
+type sessionsOptions struct {
+	client       api.ConnectClient
+	configLoader func() (*Config, error)
+	dialer       func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error)
+	runner       func(client *utils.SSHClient, command string) (string, error)
+}
+
+func defaultSessionsOptions() *sessionsOptions {
+	return &sessionsOptions{
+		configLoader: LoadConfig,
+		dialer:       dialInstance,
+		runner:       utils.ExecuteSSHCommandStdoutOnly,
+	}
+}
+
+// withSessionsInstance resolves a running instance, connects to it and calls
+// fn with the open connection.
+func withSessionsInstance(instanceID string, opts *sessionsOptions, fn func(instance *api.Instance, sshClient *utils.SSHClient) error) error {
+	config, err := opts.configLoader()
+	if err != nil {
+		return usageErr("not authenticated. Please run 'tnr login' first")
+	}
+	if config.Token == "" {
+		return usageErr("no authentication token found. Please run 'tnr login'")
+	}
+
+	client := opts.client
+	if client == nil {
+		client = newAPIClient(config)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	txn := sentry.StartTransaction(ctx, "cli.sessions",
+		sentry.WithOpName("cli.command"),
+	)
+	defer txn.Finish()
+	ctx = txn.Context()
+
+	var instances []api.Instance
+	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		instances, e = client.ListInstancesCtx(ctx)
+		return e
+	}); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	instance, err := findRunningInstance(instances, instanceID)
+	if err != nil {
+		return err
+	}
+
+	var sshClient *utils.SSHClient
+	if err := tui.RunWithBusySpinner("Connecting...", os.Stdout, func() error {
+		var e error
+		sshClient, e = opts.dialer(ctx, client, instance)
+		return e
+	}); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer sshClient.Close()
+
+	return fn(instance, sshClient)
+}
+
+func runSessionsList(instanceID string, opts *sessionsOptions) error {
+	return withSessionsInstance(instanceID, opts, func(instance *api.Instance, sshClient *utils.SSHClient) error {
+		out, err := opts.runner(sshClient, utils.ListTmuxSessionsCommand)
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		sessions := utils.ParseTmuxSessions(out)
+
+		if JSONOutput {
+			return printList(sessions, sessionColumns)
+		}
+
+		if len(sessions) == 0 {
+			PrintWarningSimple(fmt.Sprintf("No sessions on instance %s. Start one with 'tnr connect %s --session <name>'.", instance.ID, instance.ID))
+			return nil
+		}
+		return printList(sessions, sessionColumns)
+	})
+}
+
+// sessionColumns are the columns of tnr sessions list in table, wide and csv
+// output.
+var sessionColumns = []column[utils.RemoteSession]{
+	{name: "NAME", value: func(s utils.RemoteSession) string { return s.Name }},
+	{name: "WINDOWS", value: func(s utils.RemoteSession) string { return strconv.Itoa(s.Windows) }},
+	{name: "ATTACHED", value: func(s utils.RemoteSession) string { return yesNo(s.Attached) }},
+	{name: "AGE", value: func(s utils.RemoteSession) string { return time.Since(s.Created).Round(time.Second).String() }},
+	{name: "CREATED", wide: true, value: func(s utils.RemoteSession) string { return s.Created.Format(time.RFC3339) }},
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func runSessionsKill(instanceID string, names []string, opts *sessionsOptions) error {
+	for _, name := range names {
+		if err := utils.ValidateSessionName(name); err != nil {
+			return usageErr("%v", err)
+		}
+	}
+
+	return withSessionsInstance(instanceID, opts, func(instance *api.Instance, sshClient *utils.SSHClient) error {
+		killed := []string{}
+		var errs []error
+		for _, name := range names {
+			out, err := opts.runner(sshClient, utils.KillTmuxSessionCommand(name))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to kill session '%s': %w", name, err))
+				continue
+			}
+			if !utils.TmuxSessionKilled(out) {
+				errs = append(errs, usageErr("no session '%s' on instance %s", name, instance.ID))
+				continue
+			}
+			killed = append(killed, name)
+		}
+
+		if JSONOutput {
+			printResult(map[string]any{"killed": killed})
+		} else {
+			for _, name := range killed {
+				PrintSuccessSimple(fmt.Sprintf("Killed session '%s' on instance %s", name, instance.ID))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
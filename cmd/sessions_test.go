@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func newTestSessionsOptions(output map[string]string) (*sessionsOptions, *[]string) {
+	var commands []string
+	opts := &sessionsOptions{
+		client: &mockAPIClient{
+			instances: []api.Instance{
+				createTestInstance("0", "uuid-0", "box", "10.0.0.1", "RUNNING", "base", "prototyping", 22),
+				createTestInstance("1", "uuid-1", "stopped", "10.0.0.2", "STOPPED", "base", "prototyping", 22),
+			},
+		},
+		configLoader: mockConfigLoader("test-token"),
+		dialer: func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error) {
+			return &utils.SSHClient{}, nil
+		},
+		runner: func(client *utils.SSHClient, command string) (string, error) {
+			commands = append(commands, command)
+			return output[command], nil
+		},
+	}
+	return opts, &commands
+}
+
+func TestRunSessionsList(t *testing.T) {
+	opts, commands := newTestSessionsOptions(map[string]string{
+		utils.ListTmuxSessionsCommand: "train\t1\t0\t1700000000\n",
+	})
+
+	require.NoError(t, runSessionsList("0", opts))
+	assert.Equal(t, []string{utils.ListTmuxSessionsCommand}, *commands)
+}
+
+func TestRunSessionsList_InstanceNotRunning(t *testing.T) {
+	opts, commands := newTestSessionsOptions(nil)
+
+	err := runSessionsList("1", opts)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	assert.Contains(t, err.Error(), "not running")
+	assert.Empty(t, *commands)
+}
+
+func TestRunSessionsKill(t *testing.T) {
+	opts, commands := newTestSessionsOptions(map[string]string{
+		utils.KillTmuxSessionCommand("train"): "tnr:killed\n",
+	})
+
+	err := runSessionsKill("0", []string{"train", "missing"}, opts)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	assert.Contains(t, err.Error(), "no session 'missing'")
+	assert.Equal(t, []string{
+		utils.KillTmuxSessionCommand("train"),
+		utils.KillTmuxSessionCommand("missing"),
+	}, *commands)
+}
+
+func TestRunSessionsKill_InvalidName(t *testing.T) {
+	opts, commands := newTestSessionsOptions(nil)
+
+	err := runSessionsKill("0", []string{"$(reboot)"}, opts)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUsage))
+	assert.Empty(t, *commands)
+}
+
+func TestSessionFlagsRemoteCommand(t *testing.T) {
+	assert.Empty(t, sessionFlags{}.remoteCommand())
+	assert.Equal(t, utils.PersistentShellCommand(persistentSessionName), sessionFlags{reconnect: true}.remoteCommand())
+	assert.Equal(t, utils.TmuxSessionCommand("train"), sessionFlags{name: "train", reconnect: true}.remoteCommand())
+	assert.Equal(t, "tnr connect 0 --session train --reconnect", sessionFlags{name: "train", reconnect: true}.reattachHint("0"))
+}
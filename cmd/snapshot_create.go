@@ -19,6 +19,7 @@ import (
 var (
 	snapshotInstanceID string
 	snapshotName       string
+	snapshotWait       bool
 )
 
 var snapshotCreateCmd = &cobra.Command{
@@ -36,6 +37,7 @@ func init() {
 
 	snapshotCreateCmd.Flags().StringVar(&snapshotInstanceID, "instance-id", "", "Instance ID or UUID to snapshot")
 	snapshotCreateCmd.Flags().StringVar(&snapshotName, "name", "", "Name for the snapshot")
+	snapshotCreateCmd.Flags().BoolVar(&snapshotWait, "wait", false, "Wait until the snapshot is ready")
 }
 
 func createSnapshotCmd(client *api.Client, req api.CreateSnapshotRequest, resp **api.CreateSnapshotResponse) tea.Cmd {
@@ -145,15 +147,21 @@ func runSnapshotCreate(cmd *cobra.Command) error {
 		if err != nil {
 			return fmt.Errorf("failed to create snapshot: %w", err)
 		}
-		if JSONOutput {
-			printJSON(snapshotResp)
-		} else {
+		if !JSONOutput {
 			msg := "Snapshot created"
 			if snapshotResp != nil && snapshotResp.Message != "" {
 				msg = snapshotResp.Message
 			}
 			fmt.Println(msg)
 		}
+		if snapshotWait {
+			if err := waitForSnapshotWithSpinner(client, name, defaultWaitTimeout); err != nil {
+				return err
+			}
+		}
+		if JSONOutput {
+			printResult(snapshotResp)
+		}
 		return nil
 	}
 
@@ -188,5 +196,11 @@ func runSnapshotCreate(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to create snapshot: %w", result.Err())
 	}
 
+	if snapshotWait {
+		if err := waitForSnapshotWithSpinner(client, name, defaultWaitTimeout); err != nil {
+			return err
+		}
+		PrintSuccessSimple(fmt.Sprintf("Snapshot %s is ready", name))
+	}
 	return nil
 }
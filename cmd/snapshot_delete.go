@@ -129,7 +129,7 @@ func runSnapshotDelete(args []string) error {
 			return fmt.Errorf("failed to delete snapshot: %w", deleteErr)
 		}
 		if JSONOutput {
-			printJSON(map[string]string{"snapshot": selectedSnapshot.Name, "status": "deleted"})
+			printResult(map[string]string{"snapshot": selectedSnapshot.Name, "status": "deleted"})
 		} else {
 			fmt.Printf("Deleted snapshot '%s'\n", selectedSnapshot.Name)
 		}
@@ -51,13 +51,11 @@ func runSnapshotList() error {
 	}
 
 	if JSONOutput {
-		printJSON(snapshots)
-		return nil
+		return printList(snapshots, snapshotColumns)
 	}
 
 	if !interactive {
-		renderPlainSnapshotTable(snapshots)
-		return nil
+		return renderPlainSnapshotTable(snapshots)
 	}
 
 	return tui.RunSnapshotList(client, monitoring, snapshots)
@@ -54,13 +54,11 @@ func RunStatus() error {
 	}
 
 	if JSONOutput {
-		printJSON(instances)
-		return nil
+		return printList(instances, statusColumns)
 	}
 
 	if !interactive {
-		renderPlainStatusTable(instances, verboseStatus)
-		return nil
+		return renderPlainStatusTable(instances, verboseStatus)
 	}
 
 	return tui.RunStatus(client, monitoring, instances, verboseStatus)
@@ -3,42 +3,44 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
-	"text/tabwriter"
 	"time"
 
 	"github.com/Thunder-Compute/thunder-cli/api"
 	"github.com/Thunder-Compute/thunder-cli/utils"
 )
 
+// statusColumns are the columns of tnr status in table, wide and csv output.
+var statusColumns = []column[api.Instance]{
+	{name: "ID", value: func(inst api.Instance) string { return inst.ID }},
+	{name: "NAME", wide: true, value: func(inst api.Instance) string { return inst.Name }},
+	{name: "UUID", value: func(inst api.Instance) string { return inst.UUID }},
+	{name: "STATUS", value: func(inst api.Instance) string { return inst.Status }},
+	{name: "ADDRESS", value: func(inst api.Instance) string { return inst.GetIP() }},
+	{name: "PORT", wide: true, value: func(inst api.Instance) string { return formatOptionalInt(inst.Port) }},
+	{name: "MODE", value: func(inst api.Instance) string { return strings.ToLower(inst.Mode) }},
+	{name: "DISK", value: func(inst api.Instance) string { return fmt.Sprintf("%dGB", inst.Storage+inst.EphemeralDiskGB) }},
+	{name: "GPU", value: func(inst api.Instance) string {
+		return fmt.Sprintf("%sx%s", inst.NumGPUs, utils.FormatGPUType(inst.GPUType))
+	}},
+	{name: "vCPUs", value: func(inst api.Instance) string { return inst.CPUCores }},
+	{name: "RAM", value: func(inst api.Instance) string { return fmt.Sprintf("%sGB", inst.Memory) }},
+	{name: "TEMPLATE", value: func(inst api.Instance) string { return inst.Template }},
+	{name: "HTTP_PORTS", wide: true, value: func(inst api.Instance) string { return utils.FormatPorts(inst.HTTPPorts) }},
+	{name: "CREATED", wide: true, value: func(inst api.Instance) string { return inst.CreatedAt }},
+}
+
 // renderPlainStatusTable prints a plain-text tab-aligned table of instances to stdout.
-func renderPlainStatusTable(instances []api.Instance, verbose bool) {
+func renderPlainStatusTable(instances []api.Instance, verbose bool) error {
 	if len(instances) == 0 {
 		fmt.Fprintln(os.Stderr, "No instances found.")
-		return
+		return nil
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tUUID\tSTATUS\tADDRESS\tMODE\tDISK\tGPU\tvCPUs\tRAM\tTEMPLATE")
-
-	for _, inst := range instances {
-		gpu := fmt.Sprintf("%sx%s", inst.NumGPUs, utils.FormatGPUType(inst.GPUType))
-		disk := fmt.Sprintf("%dGB", inst.Storage+inst.EphemeralDiskGB)
-		ram := fmt.Sprintf("%sGB", inst.Memory)
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			inst.ID,
-			inst.UUID,
-			inst.Status,
-			inst.GetIP(),
-			strings.ToLower(inst.Mode),
-			disk,
-			gpu,
-			inst.CPUCores,
-			ram,
-			inst.Template,
-		)
+	if err := printList(instances, statusColumns); err != nil {
+		return err
 	}
-	w.Flush()
 
 	// Print recent events if any
 	var hasEvents bool
@@ -57,25 +59,36 @@ func renderPlainStatusTable(instances []api.Instance, verbose bool) {
 		fmt.Fprintf(os.Stdout, "  %s\n    [%s]: %s — %s\n",
 			inst.Name, ts, inst.LastRestart.Reason, inst.LastRestart.Message)
 	}
+	return nil
 }
 
-// renderPlainSnapshotTable prints a plain-text tab-aligned table of snapshots to stdout.
-func renderPlainSnapshotTable(snapshots []api.Snapshot) {
-	if len(snapshots) == 0 {
-		fmt.Fprintln(os.Stderr, "No snapshots found.")
-		return
+func formatOptionalInt(n int) string {
+	if n == 0 {
+		return ""
 	}
+	return strconv.Itoa(n)
+}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tNAME\tSTATUS\tDISK_GB")
+// snapshotColumns are the columns of tnr snapshot list in table, wide and
+// csv output.
+var snapshotColumns = []column[api.Snapshot]{
+	{name: "ID", value: func(snap api.Snapshot) string { return snap.ID }},
+	{name: "NAME", value: func(snap api.Snapshot) string { return snap.Name }},
+	{name: "STATUS", value: func(snap api.Snapshot) string { return snap.Status }},
+	{name: "DISK_GB", value: func(snap api.Snapshot) string { return strconv.Itoa(snap.MinimumDiskSizeGB) }},
+	{name: "CREATED", wide: true, value: func(snap api.Snapshot) string {
+		if snap.CreatedAt <= 0 {
+			return ""
+		}
+		return time.Unix(snap.CreatedAt, 0).UTC().Format(time.RFC3339)
+	}},
+}
 
-	for _, snap := range snapshots {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n",
-			snap.ID,
-			snap.Name,
-			snap.Status,
-			snap.MinimumDiskSizeGB,
-		)
+// renderPlainSnapshotTable prints a plain-text tab-aligned table of snapshots to stdout.
+func renderPlainSnapshotTable(snapshots []api.Snapshot) error {
+	if len(snapshots) == 0 {
+		fmt.Fprintln(os.Stderr, "No snapshots found.")
+		return nil
 	}
-	w.Flush()
+	return printList(snapshots, snapshotColumns)
 }
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var (
+	syncWatch  bool
+	syncDelete bool
+	syncDryRun bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync <source> <destination>",
+	Short: "Mirror a directory between your machine and an instance",
+	Args:  wrapArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSync(args[0], args[1], syncFlags{watch: syncWatch, delete: syncDelete, dryRun: syncDryRun}, defaultSyncOptions())
+	},
+}
+
+func init() {
+	syncCmd.SetHelpFunc(wrapHelp(helpmenus.RenderSyncHelp))
+	syncCmd.Flags().BoolVarP(&syncWatch, "watch", "w", false, "Keep running and push local changes as they happen")
+	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "Delete destination files that no longer exist in the source")
+	syncCmd.Flags().BoolVarP(&syncDryRun, "dry-run", "n", false, "Show what would be added, changed and deleted without copying")
+	rootCmd.AddCommand(syncCmd)
+}
+
+type syncFlags struct {
+	watch  bool
+	delete bool
+	dryRun bool
+}
+
+// mocks for testing
+type syncOptions struct {
+	client       api.ConnectClient
+	configLoader func() (*Config, error)
+	dialer       func(ctx context.Context, client api.ConnectClient, instance *api.Instance) (*utils.SSHClient, error)
+}
+
+func defaultSyncOptions() *syncOptions {
+	return &syncOptions{
+		configLoader: LoadConfig,
+		dialer:       dialInstance,
+	}
+}
+
+// syncTarget is a validated sync invocation.
+type syncTarget struct {
+	instanceID string
+	localPath  string
+	remotePath string
+	upload     bool
+}
+
+// parseSyncTarget checks the arguments and flags before anything touches the
+// network.
+func parseSyncTarget(source, destination string, flags syncFlags) (*syncTarget, error) {
+	src, dst := parsePath(source), parsePath(destination)
+	direction, instanceID, err := determineTransferDirection([]PathInfo{src}, dst)
+	if err != nil {
+		return nil, err
+	}
+	if direction == "copy" {
+		return nil, usageErr("cannot sync from remote to remote; sync between your machine and an instance")
+	}
+
+	target := &syncTarget{instanceID: instanceID, upload: direction == "upload"}
+	if target.upload {
+		target.localPath, target.remotePath = src.Path, dst.Path
+	} else {
+		target.localPath, target.remotePath = dst.Path, src.Path
+	}
+	if strings.HasPrefix(target.localPath, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		target.localPath = filepath.Join(homeDir, target.localPath[2:])
+	}
+
+	// Mirroring into the home or root directory, especially with --delete,
+	// would clobber everything else there.
+	switch strings.TrimRight(target.remotePath, "/") {
+	case "", "~", ".":
+		return nil, usageErr("name a remote directory to sync, e.g. %s:~/project", instanceID)
+	}
+	if flags.watch && !target.upload {
+		return nil, usageErr("--watch only pushes local changes; put the instance path last (tnr sync ./project %s:~/project --watch)", instanceID)
+	}
+	if flags.watch && flags.dryRun {
+		return nil, usageErr("cannot combine --watch with --dry-run")
+	}
+	return target, nil
+}
+
+func runSync(source, destination string, flags syncFlags, opts *syncOptions) error {
+	target, err := parseSyncTarget(source, destination, flags)
+	if err != nil {
+		return err
+	}
+
+	config, err := opts.configLoader()
+	if err != nil {
+		return usageErr("not authenticated. Please run 'tnr login' first")
+	}
+	if config.Token == "" {
+		return usageErr("no authentication token found. Please run 'tnr login'")
+	}
+	client := opts.client
+	if client == nil {
+		client = newAPIClient(config)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var instances []api.Instance
+	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		instances, e = client.ListInstancesCtx(ctx)
+		return e
+	}); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("failed to list instances: %w", err)
+	}
+	instance, err := findRunningInstance(instances, target.instanceID)
+	if err != nil {
+		return err
+	}
+
+	if flags.watch {
+		err = runSyncWatch(ctx, client, instance, target, flags, opts)
+	} else {
+		err = runSyncOnce(ctx, client, instance, target, flags, opts)
+	}
+	if err != nil && !isUserError(err) {
+		sentry.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("operation", "sync")
+			sentry.CaptureException(err)
+		})
+	}
+	return err
+}
+
+func runSyncOnce(ctx context.Context, client api.ConnectClient, instance *api.Instance, target *syncTarget, flags syncFlags, opts *syncOptions) error {
+	var sshClient *utils.SSHClient
+	if err := tui.RunWithBusySpinner("Connecting...", os.Stdout, func() error {
+		var e error
+		sshClient, e = opts.dialer(ctx, client, instance)
+		return e
+	}); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer sshClient.Close()
+
+	syncOpts := utils.SyncOptions{Upload: target.upload, Delete: flags.delete, DryRun: flags.dryRun}
+	var plan *utils.SyncPlan
+	var err error
+	if flags.dryRun {
+		err = tui.RunWithBusySpinner("Comparing files...", os.Stdout, func() error {
+			var e error
+			plan, e = utils.SFTPSync(ctx, sshClient, target.localPath, target.remotePath, syncOpts)
+			return e
+		})
+	} else {
+		err = tui.RunWithTransferProgress(syncTitle(instance, target), os.Stdout, func(report utils.TransferProgressFunc) error {
+			syncOpts.Progress = report
+			var e error
+			plan, e = utils.SFTPSync(ctx, sshClient, target.localPath, target.remotePath, syncOpts)
+			return e
+		})
+	}
+	if err != nil {
+		if errors.Is(err, utils.ErrTransferCancelled) {
+			fmt.Println("\nSync cancelled")
+			return nil
+		}
+		return err
+	}
+
+	if JSONOutput {
+		printResult(plan)
+		return nil
+	}
+	if flags.dryRun {
+		printSyncPlan(plan)
+		return nil
+	}
+	if plan.Empty() {
+		PrintSuccessSimple("Already in sync")
+		return nil
+	}
+	PrintSuccessSimple("Synced: " + formatSyncSummary(plan))
+	return nil
+}
+
+func runSyncWatch(ctx context.Context, client api.ConnectClient, instance *api.Instance, target *syncTarget, flags syncFlags, opts *syncOptions) error {
+	if !JSONOutput {
+		fmt.Printf("Watching %s → %s:%s (Ctrl+C to stop)\n", target.localPath, instance.ID, target.remotePath)
+	}
+
+	err := utils.WatchSync(ctx, utils.SyncWatchConfig{
+		LocalRoot:  target.localPath,
+		RemoteRoot: target.remotePath,
+		Delete:     flags.delete,
+		Dial: func(ctx context.Context) (*utils.SSHClient, error) {
+			return opts.dialer(ctx, client, instance)
+		},
+		OnSync: func(plan *utils.SyncPlan) {
+			if JSONOutput {
+				printResult(plan)
+				return
+			}
+			if plan.Empty() {
+				fmt.Printf("[%s] Already in sync\n", time.Now().Format(time.TimeOnly))
+				return
+			}
+			fmt.Printf("[%s] Synced: %s\n", time.Now().Format(time.TimeOnly), formatSyncSummary(plan))
+		},
+		Logf: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, "[%s] %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, args...))
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if !JSONOutput {
+		fmt.Println("\nStopped syncing")
+	}
+	return nil
+}
+
+func syncTitle(instance *api.Instance, target *syncTarget) string {
+	if target.upload {
+		return fmt.Sprintf("Syncing %s to %s:%s", target.localPath, instance.Name, target.remotePath)
+	}
+	return fmt.Sprintf("Syncing %s:%s to %s", instance.Name, target.remotePath, target.localPath)
+}
+
+// formatSyncSummary renders e.g. "2 added, 1 changed, 0 deleted (14.2 MB)",
+// listing the files when there are only a few.
+func formatSyncSummary(plan *utils.SyncPlan) string {
+	summary := fmt.Sprintf("%d added, %d changed, %d deleted (%s)",
+		len(plan.Add), len(plan.Change), len(plan.Delete), tui.FormatBytes(plan.Bytes))
+	files := append(append(append([]string{}, plan.Add...), plan.Change...), plan.Delete...)
+	if len(files) > 0 && len(files) <= 3 {
+		summary += ": " + strings.Join(files, ", ")
+	}
+	return summary
+}
+
+// printSyncPlan lists a dry run's changes, rsync-style.
+func printSyncPlan(plan *utils.SyncPlan) {
+	for _, rel := range plan.Add {
+		fmt.Printf("+ %s\n", rel)
+	}
+	for _, rel := range plan.Change {
+		fmt.Printf("~ %s\n", rel)
+	}
+	for _, rel := range plan.Delete {
+		fmt.Printf("- %s\n", rel)
+	}
+	if plan.Empty() {
+		PrintSuccessSimple("Already in sync")
+		return
+	}
+	PrintWarningSimple(fmt.Sprintf("Dry run, nothing copied: %d to add, %d to change, %d to delete (%s)",
+		len(plan.Add), len(plan.Change), len(plan.Delete), tui.FormatBytes(plan.Bytes)))
+}
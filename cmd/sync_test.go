@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func TestParseSyncTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		dst     string
+		flags   syncFlags
+		want    *syncTarget
+		wantErr string
+	}{
+		{
+			name: "upload",
+			src:  "./project", dst: "0:/home/ubuntu/project",
+			want: &syncTarget{instanceID: "0", localPath: "./project", remotePath: "/home/ubuntu/project", upload: true},
+		},
+		{
+			name: "download",
+			src:  "0:~/results", dst: "./results",
+			want: &syncTarget{instanceID: "0", localPath: "./results", remotePath: "~/results"},
+		},
+		{
+			name: "watch upload",
+			src:  "./project", dst: "0:project", flags: syncFlags{watch: true, delete: true},
+			want: &syncTarget{instanceID: "0", localPath: "./project", remotePath: "project", upload: true},
+		},
+		{name: "no remote", src: "./a", dst: "./b", wantErr: "no remote path"},
+		{name: "remote to remote", src: "0:a", dst: "1:b", wantErr: "remote to remote"},
+		{name: "home dir", src: "./project", dst: "0:~/", wantErr: "name a remote directory"},
+		{name: "empty remote", src: "./project", dst: "0:", wantErr: "name a remote directory"},
+		{name: "watch download", src: "0:~/project", dst: "./project", flags: syncFlags{watch: true}, wantErr: "--watch only pushes local changes"},
+		{name: "watch dry run", src: "./project", dst: "0:project", flags: syncFlags{watch: true, dryRun: true}, wantErr: "cannot combine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSyncTarget(tt.src, tt.dst, tt.flags)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrUsage))
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatSyncSummary(t *testing.T) {
+	plan := &utils.SyncPlan{Add: []string{"a.py"}, Change: []string{"b.py"}, Bytes: 2_500_000}
+	assert.Equal(t, "1 added, 1 changed, 0 deleted (2.5 MB): a.py, b.py", formatSyncSummary(plan))
+
+	plan.Delete = []string{"c/", "c/d.py", "e.py"}
+	assert.Equal(t, "1 added, 1 changed, 3 deleted (2.5 MB)", formatSyncSummary(plan))
+}
@@ -0,0 +1,453 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+var (
+	tunnelPortFlags   []string
+	tunnelLocalFlags  []string
+	tunnelRemoteFlags []string
+	tunnelSocksPort   int
+	tunnelStopAll     bool
+)
+
+// tunnelStartTimeout bounds how long `tnr tunnel start` waits for the daemon
+// to bind its ports and connect. The daemon itself waits up to 120s for SSH
+// to come up and may regenerate the key once, so this leaves some headroom.
+const tunnelStartTimeout = 5 * time.Minute
+
+// tunnelStopTimeout bounds how long `tnr tunnel stop` waits for a daemon to
+// exit after signalling it.
+const tunnelStopTimeout = 10 * time.Second
+
+// tunnelCmd represents the tunnel parent command
+var tunnelCmd = &cobra.Command{
+	Use:     "tunnel",
+	Aliases: []string{"tunnels"},
+	Short:   "Manage background port-forward tunnels",
+	Long:    "Start, list and stop port forwards that keep running after the terminal is closed.",
+	Run: func(cmd *cobra.Command, args []string) {
+		// Show help when parent command is called without subcommand
+		_ = cmd.Help()
+	},
+}
+
+var tunnelStartCmd = &cobra.Command{
+	Use:   "start <instance_id> -p <port>...",
+	Short: "Start a background tunnel to an instance",
+	Args:  wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTunnelStart(args[0], tunnelForwardFlags())
+	},
+}
+
+var tunnelStopCmd = &cobra.Command{
+	Use:   "stop <instance_id...>",
+	Short: "Stop background tunnels",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTunnelStop(args, tunnelStopAll)
+	},
+}
+
+var tunnelListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List active background tunnels",
+	Args:    wrapArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTunnelList()
+	},
+}
+
+// tunnelRunCmd is the daemon process spawned by `tnr tunnel start`.
+var tunnelRunCmd = &cobra.Command{
+	Use:    "run <instance_id>",
+	Hidden: true,
+	Args:   wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTunnelDaemon(args[0], tunnelForwardFlags())
+	},
+}
+
+func init() {
+	tunnelCmd.SetHelpFunc(wrapHelp(helpmenus.RenderTunnelHelp))
+	tunnelStartCmd.SetHelpFunc(wrapHelp(helpmenus.RenderTunnelHelp))
+	tunnelStopCmd.SetHelpFunc(wrapHelp(helpmenus.RenderTunnelHelp))
+	tunnelListCmd.SetHelpFunc(wrapHelp(helpmenus.RenderTunnelHelp))
+
+	for _, c := range []*cobra.Command{tunnelStartCmd, tunnelRunCmd} {
+		c.Flags().StringSliceVarP(&tunnelPortFlags, "port", "p", []string{}, "Port to forward (can specify multiple times: -p 8888 -p 6006)")
+		c.Flags().StringArrayVarP(&tunnelLocalFlags, "local", "L", []string{}, "Local forward [bind_port:]host:port, reached from the instance (can specify multiple times)")
+		c.Flags().StringArrayVarP(&tunnelRemoteFlags, "remote", "R", []string{}, "Reverse forward bind_port[:host:port] on the instance to this machine (can specify multiple times)")
+		c.Flags().IntVar(&tunnelSocksPort, "socks", 0, "Run a local SOCKS5 proxy on this port that connects from the instance")
+	}
+	tunnelStopCmd.Flags().BoolVar(&tunnelStopAll, "all", false, "Stop every active tunnel")
+
+	tunnelCmd.AddCommand(tunnelStartCmd, tunnelStopCmd, tunnelListCmd, tunnelRunCmd)
+	rootCmd.AddCommand(tunnelCmd)
+}
+
+func tunnelForwardFlags() forwardFlags {
+	return forwardFlags{ports: tunnelPortFlags, local: tunnelLocalFlags, remote: tunnelRemoteFlags, socks: tunnelSocksPort}
+}
+
+// parseTunnelForwards parses -p/-L/-R and requires at least one forward.
+func parseTunnelForwards(flags forwardFlags) ([]utils.ForwardSpec, error) {
+	forwards, err := flags.parse()
+	if err != nil {
+		return nil, err
+	}
+	if len(forwards) == 0 {
+		return nil, usageErr("at least one port or --socks is required (use: tnr tunnel start <instance_id> -p <port>)")
+	}
+	return forwards, nil
+}
+
+func runTunnelStart(instanceID string, flags forwardFlags) error {
+	forwards, err := parseTunnelForwards(flags)
+	if err != nil {
+		return err
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	// Resolve the instance up front so obvious mistakes are reported here
+	// rather than in the daemon's log file.
+	var instances []api.Instance
+	if err := tui.RunWithBusySpinner("Fetching instances...", os.Stdout, func() error {
+		var e error
+		instances, e = client.ListInstances()
+		return e
+	}); err != nil {
+		return fmt.Errorf("failed to fetch instances: %w", err)
+	}
+	instance, err := findRunningInstance(instances, instanceID)
+	if err != nil {
+		return err
+	}
+
+	existing, err := utils.ReadTunnelState(instance.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.Alive() {
+		return usageErr("a tunnel is already running for instance %s (pid %d); stop it first with 'tnr tunnel stop %s'",
+			instance.ID, existing.PID, instance.ID)
+	}
+
+	logPath, err := utils.GetTunnelLogPath(instance.ID)
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create tunnel log: %w", err)
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate tnr executable: %w", err)
+	}
+
+	daemon := exec.Command(exe, tunnelDaemonArgs(instance.ID, forwards)...)
+	daemon.Stdout = logFile
+	daemon.Stderr = logFile
+	detachProcess(daemon)
+	if err := daemon.Start(); err != nil {
+		return fmt.Errorf("failed to start tunnel process: %w", err)
+	}
+
+	sentry.AddBreadcrumb(&sentry.Breadcrumb{
+		Category: "tunnel",
+		Message:  "tunnel daemon started",
+		Data: map[string]interface{}{
+			"instance_id":   instance.ID,
+			"forward_count": len(forwards),
+		},
+		Level: sentry.LevelInfo,
+	})
+
+	var state *utils.TunnelState
+	err = tui.RunWithBusySpinner("Starting tunnel...", os.Stdout, func() error {
+		var e error
+		state, e = waitForTunnelDaemon(daemon, instance.ID, logPath, tunnelStartTimeout)
+		return e
+	})
+	if err != nil {
+		return err
+	}
+	_ = daemon.Process.Release()
+
+	if JSONOutput {
+		printResult(state)
+		return nil
+	}
+	PrintSuccessSimple(fmt.Sprintf("Tunnel to instance %s running in the background (pid %d)", instance.ID, state.PID))
+	for _, f := range state.Forwards {
+		fmt.Printf("  %s\n", f.Describe())
+	}
+	fmt.Printf("Stop it with 'tnr tunnel stop %s'. Logs: %s\n", instance.ID, state.LogFile)
+	return nil
+}
+
+// tunnelDaemonArgs returns the arguments that start the `tnr tunnel run`
+// daemon for an instance. The daemon runs as the active profile, so that it
+// uses the same token and keys and finds the instance in the same organization.
+func tunnelDaemonArgs(instanceID string, forwards []utils.ForwardSpec) []string {
+	args := []string{"--profile", utils.Profile(), "tunnel", "run", instanceID}
+	for _, f := range forwards {
+		flag := "--local"
+		switch f.Direction {
+		case utils.ForwardRemote:
+			flag = "--remote"
+		case utils.ForwardDynamic:
+			flag = "--socks"
+		}
+		args = append(args, flag, f.String())
+	}
+	return args
+}
+
+// waitForTunnelDaemon waits until the daemon records its state (meaning its
+// ports are bound and the first SSH connection is up), or until it exits.
+func waitForTunnelDaemon(daemon *exec.Cmd, instanceID, logPath string, timeout time.Duration) (*utils.TunnelState, error) {
+	exited := make(chan error, 1)
+	go func() { exited <- daemon.Wait() }()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-exited:
+			if reason := lastLogLine(logPath); reason != "" {
+				return nil, fmt.Errorf("tunnel failed to start: %s", reason)
+			}
+			return nil, fmt.Errorf("tunnel failed to start, see %s", logPath)
+		case <-deadline:
+			_ = daemon.Process.Kill()
+			return nil, fmt.Errorf("timed out waiting for tunnel to start, see %s", logPath)
+		case <-ticker.C:
+			state, err := utils.ReadTunnelState(instanceID)
+			if err == nil && state != nil && state.PID == daemon.Process.Pid {
+				return state, nil
+			}
+		}
+	}
+}
+
+// lastLogLine returns the last non-empty line of a log file.
+func lastLogLine(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var last string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			last = line
+		}
+	}
+	return last
+}
+
+// runTunnelDaemon is the body of the detached `tnr tunnel run` process. It
+// keeps the forwards up until it receives SIGTERM/SIGINT, redialing whenever
+// the connection drops. Output goes to the log file set up by runTunnelStart.
+func runTunnelDaemon(instanceID string, flags forwardFlags) error {
+	forwards, err := parseTunnelForwards(flags)
+	if err != nil {
+		return err
+	}
+
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	logPath, err := utils.GetTunnelLogPath(instanceID)
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	release, err := utils.LockTunnel(instanceID)
+	if err != nil {
+		logger.Printf("error: %v", err)
+		return err
+	}
+	defer release()
+	// Any state recorded before the lock was taken belongs to a daemon that
+	// has exited.
+	_ = utils.RemoveTunnelState(instanceID)
+
+	pid := os.Getpid()
+	defer func() {
+		// Only clean up state that still belongs to this process.
+		if state, err := utils.ReadTunnelState(instanceID); err == nil && state != nil && state.PID == pid {
+			_ = utils.RemoveTunnelState(instanceID)
+		}
+	}()
+
+	err = utils.RunTunnel(ctx, utils.TunnelConfig{
+		Forwards: forwards,
+		// Look the instance up again on every dial so a restarted instance
+		// with a new address is picked up.
+		Dial: func(ctx context.Context) (*utils.SSHClient, error) {
+			instances, err := client.ListInstancesCtx(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list instances: %w", err)
+			}
+			instance, err := findRunningInstance(instances, instanceID)
+			if err != nil {
+				return nil, err
+			}
+			return dialInstance(ctx, client, instance)
+		},
+		Ready: func() {
+			if err := utils.WriteTunnelState(&utils.TunnelState{
+				InstanceID: instanceID,
+				PID:        pid,
+				Forwards:   forwards,
+				LogFile:    logPath,
+				StartedAt:  time.Now(),
+			}); err != nil {
+				logger.Printf("warning: %v", err)
+			}
+		},
+		Logf: logger.Printf,
+	})
+	if err != nil {
+		logger.Printf("error: %v", err)
+		return err
+	}
+	logger.Printf("stopped")
+	return nil
+}
+
+func runTunnelStop(instanceIDs []string, all bool) error {
+	if all && len(instanceIDs) > 0 {
+		return usageErr("cannot combine instance IDs with --all")
+	}
+	if !all && len(instanceIDs) == 0 {
+		return usageErr("instance ID required (or use --all)")
+	}
+
+	var targets []utils.TunnelState
+	if all {
+		states, err := utils.ListTunnelStates()
+		if err != nil {
+			return err
+		}
+		targets = states
+	} else {
+		for _, id := range instanceIDs {
+			state, err := utils.ReadTunnelState(id)
+			if err != nil {
+				return err
+			}
+			if state == nil || !state.Alive() {
+				_ = utils.RemoveTunnelState(id)
+				return usageErr("no active tunnel for instance %s", id)
+			}
+			targets = append(targets, *state)
+		}
+	}
+
+	stopped := []string{}
+	var errs []error
+	for i := range targets {
+		if err := utils.StopTunnel(&targets[i], tunnelStopTimeout); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		stopped = append(stopped, targets[i].InstanceID)
+	}
+
+	if JSONOutput {
+		printResult(map[string]any{"stopped": stopped})
+	} else if len(targets) == 0 {
+		PrintWarningSimple("No active tunnels.")
+	} else {
+		for _, id := range stopped {
+			PrintSuccessSimple(fmt.Sprintf("Stopped tunnel to instance %s", id))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func runTunnelList() error {
+	states, err := utils.ListTunnelStates()
+	if err != nil {
+		return err
+	}
+
+	if JSONOutput {
+		return printList(states, tunnelColumns)
+	}
+
+	if len(states) == 0 {
+		PrintWarningSimple("No active tunnels. Start one with 'tnr tunnel start <instance_id> -p <port>'.")
+		return nil
+	}
+	return printList(states, tunnelColumns)
+}
+
+// tunnelColumns are the columns of tnr tunnel list in table, wide and csv
+// output.
+var tunnelColumns = []column[utils.TunnelState]{
+	{name: "INSTANCE", value: func(s utils.TunnelState) string { return s.InstanceID }},
+	{name: "PID", value: func(s utils.TunnelState) string { return strconv.Itoa(s.PID) }},
+	{name: "FORWARDS", value: func(s utils.TunnelState) string { return formatForwards(s.Forwards) }},
+	{name: "UPTIME", value: func(s utils.TunnelState) string { return time.Since(s.StartedAt).Round(time.Second).String() }},
+	{name: "LOG", value: func(s utils.TunnelState) string { return s.LogFile }},
+	{name: "STARTED", wide: true, value: func(s utils.TunnelState) string { return s.StartedAt.Format(time.RFC3339) }},
+}
+
+// formatForwards renders forwards compactly, e.g. "8888, socks:1080, R:9000:localhost:9000".
+func formatForwards(forwards []utils.ForwardSpec) string {
+	parts := make([]string, 0, len(forwards))
+	for _, f := range forwards {
+		switch {
+		case f.Direction == utils.ForwardRemote:
+			parts = append(parts, "R:"+f.String())
+		case f.Direction == utils.ForwardDynamic:
+			parts = append(parts, "socks:"+f.String())
+		case f == utils.LocalForward(f.BindPort):
+			parts = append(parts, strconv.Itoa(f.BindPort))
+		default:
+			parts = append(parts, f.String())
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+func TestParseTunnelForwards(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   forwardFlags
+		want    []utils.ForwardSpec
+		wantErr string
+	}{
+		{name: "repeated", flags: forwardFlags{ports: []string{"8888", "6006"}}, want: []utils.ForwardSpec{utils.LocalForward(8888), utils.LocalForward(6006)}},
+		{name: "comma and range", flags: forwardFlags{ports: []string{"8000-8001,9000"}}, want: []utils.ForwardSpec{utils.LocalForward(8000), utils.LocalForward(8001), utils.LocalForward(9000)}},
+		{name: "duplicates", flags: forwardFlags{ports: []string{"8888"}, local: []string{"8888:localhost:8888"}}, want: []utils.ForwardSpec{utils.LocalForward(8888)}},
+		{name: "remote", flags: forwardFlags{remote: []string{"9000:cache.lan:80"}}, want: []utils.ForwardSpec{{Direction: utils.ForwardRemote, BindPort: 9000, TargetHost: "cache.lan", TargetPort: 80}}},
+		{name: "socks", flags: forwardFlags{socks: 1080}, want: []utils.ForwardSpec{utils.DynamicForward(1080)}},
+		{name: "socks conflict", flags: forwardFlags{ports: []string{"1080"}, socks: 1080}, wantErr: "forwarded twice"},
+		{name: "none", flags: forwardFlags{}, wantErr: "at least one port"},
+		{name: "invalid", flags: forwardFlags{ports: []string{"abc"}}, wantErr: "invalid port"},
+		{name: "ssh", flags: forwardFlags{ports: []string{"22"}}, wantErr: "reserved for SSH"},
+		{name: "conflict", flags: forwardFlags{ports: []string{"8080"}, local: []string{"8080:localhost:80"}}, wantErr: "forwarded twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTunnelForwards(tt.flags)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, ErrUsage))
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestTunnelDaemonHelperProcess is not a real test: it stands in for a tunnel
+// daemon when the test binary is re-executed by TestRunTunnelStop.
+func TestTunnelDaemonHelperProcess(t *testing.T) {
+	id := os.Getenv("TNR_TEST_TUNNEL_DAEMON")
+	if id == "" {
+		t.Skip("helper process")
+	}
+	if _, err := utils.LockTunnel(id); err != nil {
+		os.Exit(1)
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+func TestRunTunnelStop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on SIGTERM")
+	}
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestTunnelDaemonHelperProcess$")
+	proc.Env = append(os.Environ(), "TNR_TEST_TUNNEL_DAEMON=0")
+	require.NoError(t, proc.Start())
+	exited := make(chan struct{})
+	go func() {
+		_ = proc.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() { _ = proc.Process.Kill() })
+
+	state := &utils.TunnelState{
+		InstanceID: "0",
+		PID:        proc.Process.Pid,
+		Forwards:   []utils.ForwardSpec{utils.LocalForward(8888)},
+		StartedAt:  time.Now(),
+	}
+	require.NoError(t, utils.WriteTunnelState(state))
+	require.Eventually(t, state.Alive, 10*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, runTunnelStop([]string{"0"}, false))
+
+	// Stop waits for the daemon to exit before returning.
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("tunnel process was not terminated")
+	}
+	got, err := utils.ReadTunnelState("0")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestTunnelDaemonArgsKeepProfile(t *testing.T) {
+	utils.SetProfile("team")
+	t.Cleanup(func() { utils.SetProfile(utils.DefaultProfile) })
+
+	forwards := []utils.ForwardSpec{
+		utils.LocalForward(8888),
+		{Direction: utils.ForwardRemote, BindPort: 9000, TargetHost: "cache.lan", TargetPort: 80},
+		utils.DynamicForward(1080),
+	}
+	assert.Equal(t, []string{
+		"--profile", "team", "tunnel", "run", "0",
+		"--local", forwards[0].String(),
+		"--remote", forwards[1].String(),
+		"--socks", forwards[2].String(),
+	}, tunnelDaemonArgs("0", forwards))
+}
+
+func TestRunTunnelStopIgnoresReusedPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep(1)")
+	}
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	// The recorded PID now belongs to a process that is not a tunnel daemon.
+	proc := exec.Command("sleep", "30")
+	require.NoError(t, proc.Start())
+	t.Cleanup(func() { _ = proc.Process.Kill() })
+	require.NoError(t, utils.WriteTunnelState(&utils.TunnelState{InstanceID: "6", PID: proc.Process.Pid}))
+
+	err := runTunnelStop([]string{"6"}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no active tunnel for instance 6")
+	assert.NoError(t, proc.Process.Signal(syscall.Signal(0)), "unrelated process was signalled")
+}
+
+func TestRunTunnelStopValidation(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		ids     []string
+		all     bool
+		wantErr string
+	}{
+		{name: "no target", wantErr: "instance ID required"},
+		{name: "ids with all", ids: []string{"0"}, all: true, wantErr: "cannot combine"},
+		{name: "no tunnel", ids: []string{"7"}, wantErr: "no active tunnel for instance 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runTunnelStop(tt.ids, tt.all)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrUsage))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	// A state file whose process has exited is treated as no tunnel and removed.
+	require.NoError(t, utils.WriteTunnelState(&utils.TunnelState{InstanceID: "8", PID: deadPID(t)}))
+	err := runTunnelStop([]string{"8"}, false)
+	require.Error(t, err)
+	state, err := utils.ReadTunnelState("8")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+// deadPID returns the PID of a process that has already exited.
+func deadPID(t *testing.T) int {
+	proc := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, proc.Run())
+	return proc.Process.Pid
+}
+
+func TestLastLogLine(t *testing.T) {
+	path := t.TempDir() + "/tunnel.log"
+	require.NoError(t, os.WriteFile(path, []byte("2026/01/01 connected\n2026/01/01 error: listen on port 8888: address already in use\n\n"), 0600))
+	assert.Equal(t, "2026/01/01 error: listen on port 8888: address already in use", lastLogLine(path))
+	assert.Equal(t, "", lastLogLine(path+".missing"))
+}
+
+func TestFormatForwards(t *testing.T) {
+	forwards := []utils.ForwardSpec{
+		utils.LocalForward(8888),
+		{Direction: utils.ForwardLocal, BindPort: 5433, TargetHost: "db", TargetPort: 5432},
+		{Direction: utils.ForwardRemote, BindPort: 9000, TargetHost: "localhost", TargetPort: 9000},
+		utils.DynamicForward(1080),
+	}
+	assert.Equal(t, "8888, 5433:db:5432, R:9000:localhost:9000, socks:1080", formatForwards(forwards))
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/tui"
+	helpmenus "github.com/Thunder-Compute/thunder-cli/tui/help-menus"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+const (
+	waitRunning  = "running"
+	waitStopped  = "stopped"
+	waitDeleted  = "deleted"
+	waitSSHReady = "ssh-ready"
+
+	defaultWaitTimeout = 20 * time.Minute
+
+	// waitSettle is how long a --wait right after a create or modify allows
+	// the API to catch up: it may not list a new instance yet, or keep
+	// reporting RUNNING for a moment after accepting a modification.
+	waitSettle = 30 * time.Second
+)
+
+var waitConditions = []string{waitRunning, waitStopped, waitDeleted, waitSSHReady}
+
+// errWaitTimeout marks a wait that ran out of time. Scripts expect it now and
+// then, so it is a user error rather than something to report.
+var errWaitTimeout = errors.New("timed out")
+
+// instancePollDelay is the backoff between polls; tests shorten it.
+var instancePollDelay = tui.NextInstancePollDelay
+
+var (
+	waitFor     string
+	waitTimeout time.Duration
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait <instance_id>",
+	Short: "Wait for an instance to reach a state",
+	Args:  wrapArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWait(args[0], waitFor, waitTimeout)
+	},
+}
+
+func init() {
+	waitCmd.SetHelpFunc(wrapHelp(helpmenus.RenderWaitHelp))
+	waitCmd.Flags().StringVar(&waitFor, "for", waitRunning, "State to wait for: running, stopped, deleted or ssh-ready")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", defaultWaitTimeout, "How long to wait before giving up")
+	rootCmd.AddCommand(waitCmd)
+}
+
+type waitResult struct {
+	InstanceID     string  `json:"instance_id"`
+	Condition      string  `json:"condition"`
+	Status         string  `json:"status"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+func runWait(identifier, condition string, timeout time.Duration) error {
+	condition = strings.ToLower(condition)
+	if !slices.Contains(waitConditions, condition) {
+		return usageErr("--for must be one of: %s", strings.Join(waitConditions, ", "))
+	}
+	if timeout <= 0 {
+		return usageErr("--timeout must be positive")
+	}
+	client, err := getAuthenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	inst, err := waitWithSpinner(client, identifier, condition, timeout, 0)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+	if JSONOutput {
+		printResult(waitResult{InstanceID: inst.ID, Condition: condition, Status: inst.Status, ElapsedSeconds: elapsed.Seconds()})
+		return nil
+	}
+	PrintSuccessSimple(fmt.Sprintf("Instance %s is %s (waited %s)", inst.ID, condition, elapsed.Round(time.Second)))
+	return nil
+}
+
+// waitWithSpinner runs waitForInstance behind a spinner, cancelling on
+// Ctrl+C. It backs both tnr wait and the --wait flag of mutating commands.
+func waitWithSpinner(client api.ConnectClient, identifier, condition string, timeout, settle time.Duration) (*api.Instance, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var inst *api.Instance
+	err := tui.RunWithBusySpinner(fmt.Sprintf("Waiting for instance %s to be %s...", identifier, condition), os.Stdout, func() error {
+		var e error
+		inst, e = waitForInstance(ctx, client, identifier, condition, settle)
+		return e
+	})
+	return inst, err
+}
+
+// waitForInstance polls until the instance identified by identifier meets
+// condition, backing off the same way tnr status does. A deleted instance is
+// reported as an instance with status DELETED.
+//
+// With settle > 0 the wait follows a request the API may not reflect yet: an
+// instance that is missing, or already meets the condition without having
+// been seen in any other state, is only trusted once settle has passed.
+func waitForInstance(ctx context.Context, client api.ConnectClient, identifier, condition string, settle time.Duration) (*api.Instance, error) {
+	start := time.Now()
+	label, id := "instance "+identifier, identifier
+	seen, transitioned := false, false
+	lastStatus := ""
+	for {
+		settled := settle == 0 || time.Since(start) >= settle
+		instances, err := client.ListInstancesWithIPUpdateCtx(ctx)
+		if err != nil && ctx.Err() == nil && !errors.Is(err, api.ErrTransport) {
+			return nil, fmt.Errorf("failed to list instances: %w", err)
+		}
+
+		if err == nil {
+			inst := findInstance(instances, identifier)
+			switch {
+			case inst == nil && condition == waitDeleted && (seen || settled):
+				return &api.Instance{ID: id, Status: "DELETED"}, nil
+			case inst == nil && seen:
+				return nil, fmt.Errorf("%s was deleted while waiting for it to be %s", label, condition)
+			case inst == nil && settled:
+				return nil, usageErr("instance '%s' not found", identifier)
+			case inst != nil:
+				if !seen {
+					// Pin the instance so a name reused later can't match.
+					identifier, id, seen = inst.UUID, inst.ID, true
+				}
+				if lastStatus != "" && inst.Status != lastStatus {
+					transitioned = true
+				}
+				lastStatus = inst.Status
+
+				met, err := instanceConditionMet(inst, condition)
+				if err != nil {
+					return nil, err
+				}
+				if met && (settled || transitioned) {
+					if condition != waitSSHReady {
+						return inst, nil
+					}
+					if err := checkSSHReady(ctx, client, inst); err == nil {
+						return inst, nil
+					}
+				}
+			}
+		}
+
+		timer := time.NewTimer(instancePollDelay(time.Since(start)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waitInterrupted(ctx, start, label, condition, lastStatus)
+		case <-timer.C:
+		}
+	}
+}
+
+// instanceConditionMet reports whether inst is in the state condition asks
+// for, and fails once it is clear the state will never be reached.
+func instanceConditionMet(inst *api.Instance, condition string) (bool, error) {
+	if inst.Status == "DELETING" && condition != waitDeleted {
+		return false, fmt.Errorf("instance %s is being deleted", inst.ID)
+	}
+	switch condition {
+	case waitRunning:
+		return inst.Status == "RUNNING", nil
+	case waitSSHReady:
+		return inst.Status == "RUNNING" && inst.GetIP() != "", nil
+	case waitStopped:
+		return inst.Status == "STOPPED", nil
+	}
+	return false, nil
+}
+
+// checkSSHReady confirms a running instance accepts SSH logins, fetching a
+// key first if there isn't one yet. The port check is capped so a slow boot
+// falls back to polling the instance's status.
+func checkSSHReady(ctx context.Context, client api.ConnectClient, inst *api.Instance) error {
+	ip, port := inst.GetIP(), instancePort(inst)
+	portTimeout := 2 * time.Minute
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < portTimeout {
+		portTimeout = time.Until(deadline)
+	}
+	if err := utils.WaitForTCPPort(ctx, ip, port, portTimeout); err != nil {
+		return err
+	}
+	if !utils.KeyExists(inst.UUID) {
+		keyResp, err := client.AddSSHKeyCtx(ctx, inst.ID)
+		if err != nil {
+			return fmt.Errorf("failed to add SSH key: %w", err)
+		}
+		if keyResp.Key != nil {
+			if err := utils.SavePrivateKey(inst.UUID, *keyResp.Key); err != nil {
+				return fmt.Errorf("failed to save private key: %w", err)
+			}
+		}
+	}
+	return utils.VerifySSHConnectionCtx(ctx, ip, utils.GetKeyFile(inst.UUID), port)
+}
+
+func waitInterrupted(ctx context.Context, start time.Time, what, condition, lastStatus string) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("stopped waiting for %s: %w", what, tui.ErrCancelled)
+	}
+	if lastStatus == "" {
+		lastStatus = "unknown"
+	}
+	return fmt.Errorf("%w after %s waiting for %s to be %s (last status: %s)",
+		errWaitTimeout, time.Since(start).Round(time.Second), what, condition, lastStatus)
+}
+
+// snapshotLister is the part of api.Client snapshot waits need.
+type snapshotLister interface {
+	ListSnapshotsCtx(ctx context.Context) (api.ListSnapshotsResponse, error)
+}
+
+// waitForSnapshot polls until the named snapshot is READY. A snapshot that
+// has not been listed yet is waited for, since creation is asynchronous.
+func waitForSnapshot(ctx context.Context, client snapshotLister, name string) (*api.Snapshot, error) {
+	start := time.Now()
+	lastStatus := ""
+	for {
+		snapshots, err := client.ListSnapshotsCtx(ctx)
+		if err != nil && ctx.Err() == nil && !errors.Is(err, api.ErrTransport) {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		for i := range snapshots {
+			if snapshots[i].Name != name {
+				continue
+			}
+			lastStatus = snapshots[i].Status
+			switch lastStatus {
+			case "READY":
+				return &snapshots[i], nil
+			case "FAILED":
+				return nil, fmt.Errorf("snapshot '%s' failed", name)
+			}
+		}
+
+		timer := time.NewTimer(instancePollDelay(time.Since(start)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waitInterrupted(ctx, start, "snapshot "+name, "ready", lastStatus)
+		case <-timer.C:
+		}
+	}
+}
+
+// waitForSnapshotWithSpinner is the --wait counterpart of waitWithSpinner
+// for tnr snapshot create.
+func waitForSnapshotWithSpinner(client snapshotLister, name string, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return tui.RunWithBusySpinner(fmt.Sprintf("Waiting for snapshot %s to be ready...", name), os.Stdout, func() error {
+		_, err := waitForSnapshot(ctx, client, name)
+		return err
+	})
+}
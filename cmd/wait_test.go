@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// pollingClient serves one instance list per poll, repeating the last.
+type pollingClient struct {
+	mockAPIClient
+	polls [][]api.Instance
+	n     int
+}
+
+func (c *pollingClient) ListInstancesWithIPUpdateCtx(ctx context.Context) ([]api.Instance, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := min(c.n, len(c.polls)-1)
+	c.n++
+	return c.polls[i], nil
+}
+
+func fastPolling(t *testing.T) {
+	t.Helper()
+	orig := instancePollDelay
+	instancePollDelay = func(time.Duration) time.Duration { return time.Millisecond }
+	t.Cleanup(func() { instancePollDelay = orig })
+}
+
+func withStatus(status string) []api.Instance {
+	return []api.Instance{{ID: "3", UUID: "uuid-3", Name: "trainer", Status: status}}
+}
+
+func TestWaitForInstance(t *testing.T) {
+	fastPolling(t)
+	ctx := context.Background()
+
+	t.Run("running", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("PROVISIONING"), withStatus("STARTING"), withStatus("RUNNING")}}
+		inst, err := waitForInstance(ctx, client, "trainer", waitRunning, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "RUNNING", inst.Status)
+		assert.Equal(t, 3, client.n)
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("RUNNING")}}
+		_, err := waitForInstance(ctx, client, "3", waitRunning, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, client.n)
+	})
+
+	t.Run("settle waits for the transition", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("RUNNING"), withStatus("RUNNING"), withStatus("MODIFYING"), withStatus("RUNNING")}}
+		_, err := waitForInstance(ctx, client, "3", waitRunning, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, 4, client.n)
+	})
+
+	t.Run("settle tolerates a new instance not being listed yet", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{nil, withStatus("PROVISIONING"), withStatus("RUNNING")}}
+		_, err := waitForInstance(ctx, client, "uuid-3", waitRunning, time.Hour)
+		require.NoError(t, err)
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("STOPPING"), withStatus("STOPPED")}}
+		inst, err := waitForInstance(ctx, client, "3", waitStopped, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "STOPPED", inst.Status)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("DELETING"), nil}}
+		inst, err := waitForInstance(ctx, client, "trainer", waitDeleted, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "3", inst.ID)
+		assert.Equal(t, "DELETED", inst.Status)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{nil}}
+		_, err := waitForInstance(ctx, client, "9", waitRunning, 0)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUsage))
+	})
+
+	t.Run("deleted while waiting", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("STARTING"), nil}}
+		_, err := waitForInstance(ctx, client, "3", waitRunning, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "instance 3 was deleted while waiting for it to be running")
+	})
+
+	t.Run("being deleted", func(t *testing.T) {
+		client := &pollingClient{polls: [][]api.Instance{withStatus("DELETING")}}
+		_, err := waitForInstance(ctx, client, "3", waitStopped, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is being deleted")
+	})
+}
+
+func TestWaitForInstanceTimesOut(t *testing.T) {
+	fastPolling(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := &pollingClient{polls: [][]api.Instance{withStatus("STARTING")}}
+	_, err := waitForInstance(ctx, client, "3", waitRunning, 0)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, errWaitTimeout))
+	assert.True(t, isUserError(err))
+	assert.Contains(t, err.Error(), "waiting for instance 3 to be running (last status: STARTING)")
+}
+
+type fakeSnapshotLister struct {
+	mu    sync.Mutex
+	polls []api.ListSnapshotsResponse
+	n     int
+	block bool
+}
+
+func (f *fakeSnapshotLister) ListSnapshotsCtx(ctx context.Context) (api.ListSnapshotsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.block {
+		// Like a request in flight, only the context ends it.
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	i := min(f.n, len(f.polls)-1)
+	f.n++
+	return f.polls[i], nil
+}
+
+func TestWaitForSnapshot(t *testing.T) {
+	fastPolling(t)
+	ctx := context.Background()
+	other := api.Snapshot{Name: "other", Status: "READY"}
+
+	client := &fakeSnapshotLister{polls: []api.ListSnapshotsResponse{
+		{other},
+		{other, {Name: "ckpt", Status: "CREATING"}},
+		{other, {Name: "ckpt", Status: "READY"}},
+	}}
+	snap, err := waitForSnapshot(ctx, client, "ckpt")
+	require.NoError(t, err)
+	assert.Equal(t, "READY", snap.Status)
+	assert.Equal(t, 3, client.n)
+
+	client = &fakeSnapshotLister{polls: []api.ListSnapshotsResponse{{{Name: "ckpt", Status: "FAILED"}}}}
+	_, err = waitForSnapshot(ctx, client, "ckpt")
+	assert.EqualError(t, err, "snapshot 'ckpt' failed")
+
+	// The timeout ends a list call in flight.
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = waitForSnapshot(ctx, &fakeSnapshotLister{block: true}, "ckpt")
+	assert.ErrorIs(t, err, errWaitTimeout)
+}
+
+func TestModifyOnlyChangesPorts(t *testing.T) {
+	assert.True(t, modifyOnlyChangesPorts(api.InstanceModifyRequest{AddPorts: []int{8080}}))
+	gpu := "h100"
+	assert.False(t, modifyOnlyChangesPorts(api.InstanceModifyRequest{GPUType: &gpu, AddPorts: []int{8080}}))
+}
@@ -0,0 +1,76 @@
+package mockapi
+
+import (
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/pkg/types"
+)
+
+// The catalog mirrors the shape of production's; the prices are made up.
+
+func defaultSpecs() map[string]api.GpuSpecConfig {
+	proto := func(name string, vram, count int, vcpus []int, maxDisk int) api.GpuSpecConfig {
+		return api.GpuSpecConfig{DisplayName: name, VramGB: vram, GpuCount: count, Mode: "prototyping", VcpuOptions: vcpus, RamPerVCPUGiB: 8,
+			StorageGB: api.StorageRange{Min: 100, Max: maxDisk}, EphemeralStorageGB: api.StorageRange{Min: 0, Max: 500}}
+	}
+	prod := func(name string, vram, count int) api.GpuSpecConfig {
+		return api.GpuSpecConfig{DisplayName: name, VramGB: vram, GpuCount: count, Mode: "production", VcpuOptions: []int{18 * count}, RamPerVCPUGiB: 5,
+			StorageGB: api.StorageRange{Min: 100, Max: 1000}, EphemeralStorageGB: api.StorageRange{Min: 0, Max: 2000}}
+	}
+	return map[string]api.GpuSpecConfig{
+		"a6000_x1_prototyping":  proto("RTX A6000", 48, 1, []int{4, 8}, 300),
+		"a100xl_x1_prototyping": proto("NVIDIA A100 (80GB)", 80, 1, []int{4, 8, 12}, 500),
+		"a100xl_x2_prototyping": proto("NVIDIA A100 (80GB)", 80, 2, []int{8, 12, 16, 20, 24}, 1000),
+		"h100_x1_prototyping":   proto("NVIDIA H100", 80, 1, []int{4, 8, 12, 16}, 500),
+		"a100xl_x1_production":  prod("NVIDIA A100 (80GB)", 80, 1),
+		"a100xl_x2_production":  prod("NVIDIA A100 (80GB)", 80, 2),
+		"a100xl_x4_production":  prod("NVIDIA A100 (80GB)", 80, 4),
+		"h100_x1_production":    prod("NVIDIA H100", 80, 1),
+		"h100_x2_production":    prod("NVIDIA H100", 80, 2),
+		"h100_x4_production":    prod("NVIDIA H100", 80, 4),
+	}
+}
+
+func defaultPricing() map[string]float64 {
+	return map[string]float64{
+		"a6000_x1_prototyping":  0.27,
+		"a100xl_x1_prototyping": 0.78,
+		"a100xl_x2_prototyping": 1.56,
+		"h100_x1_prototyping":   1.47,
+		"a100xl_x1_production":  1.79,
+		"a100xl_x2_production":  3.58,
+		"a100xl_x4_production":  7.16,
+		"h100_x1_production":    2.49,
+		"h100_x2_production":    4.98,
+		"h100_x4_production":    9.96,
+		"additional_vcpus":      0.09,
+		"disk_gb":               0.0002,
+		"ephemeral_disk_gb":     0.0001,
+	}
+}
+
+func defaultTemplates() types.ThunderTemplatesResponse {
+	isDefault := true
+	return types.ThunderTemplatesResponse{
+		"base": {
+			DisplayName:         "Ubuntu",
+			ExtendedDescription: "Ubuntu with CUDA and Python",
+			Default:             &isDefault,
+		},
+		"ollama": {
+			DisplayName: "Ollama",
+			OpenPorts:   []int{11434},
+		},
+		"comfy-ui": {
+			DisplayName: "ComfyUI",
+			OpenPorts:   []int{8188},
+		},
+	}
+}
+
+func defaultAvailability() api.GPUAvailabilityResponse {
+	specs := make(map[string]string)
+	for key := range defaultSpecs() {
+		specs[key] = "available"
+	}
+	return api.GPUAvailabilityResponse{Specs: specs}
+}
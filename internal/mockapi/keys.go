@@ -0,0 +1,51 @@
+package mockapi
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// issuedKey is a key pair handed to the CLI, which keeps the private half
+// while the server authorizes the public one for SSH.
+type issuedKey struct {
+	private    string
+	authorized string
+}
+
+func newKey() (*issuedKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	return &issuedKey{private: string(pem.EncodeToMemory(block)), authorized: string(sshPub.Marshal())}, nil
+}
+
+// authorize returns the instance key was issued for, if that instance is
+// running and so accepts SSH connections.
+func (s *Server) authorize(key ssh.PublicKey) (*instance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advance()
+	uuid, ok := s.authorized[string(key.Marshal())]
+	if !ok {
+		return nil, false
+	}
+	for _, inst := range s.instances {
+		if inst.UUID == uuid && inst.Status == "RUNNING" {
+			return inst, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,177 @@
+package mockapi
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// remoteHome is the home directory of the instance user.
+const remoteHome = "/home/ubuntu"
+
+// sandbox is the scratch directory standing in for an instance's
+// filesystem.
+type sandbox struct {
+	root string
+}
+
+func (sb *sandbox) home() string {
+	return filepath.Join(sb.root, filepath.FromSlash(remoteHome))
+}
+
+// rewrite points the instance paths in a command at the sandbox.
+func (sb *sandbox) rewrite(command string) string {
+	return strings.ReplaceAll(command, remoteHome, filepath.ToSlash(sb.home()))
+}
+
+// sftpHandler serves SFTP requests from an os.Root, so that no path can
+// leave the sandbox.
+type sftpHandler struct {
+	root *os.Root
+}
+
+// rel turns an absolute SFTP path into one relative to the root.
+func rel(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func (h *sftpHandler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	return h.root.Open(rel(r.Filepath))
+}
+
+func (h *sftpHandler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	return h.openFile(r)
+}
+
+func (h *sftpHandler) OpenFile(r *sftp.Request) (sftp.WriterAtReaderAt, error) {
+	return h.openFile(r)
+}
+
+func (h *sftpHandler) openFile(r *sftp.Request) (*os.File, error) {
+	pflags := r.Pflags()
+	flags := os.O_RDONLY
+	switch {
+	case pflags.Read && pflags.Write:
+		flags = os.O_RDWR
+	case pflags.Write:
+		flags = os.O_WRONLY
+	}
+	// O_APPEND is left out: the server writes at explicit offsets.
+	if pflags.Creat {
+		flags |= os.O_CREATE
+	}
+	if pflags.Trunc {
+		flags |= os.O_TRUNC
+	}
+	if pflags.Excl {
+		flags |= os.O_EXCL
+	}
+	return h.root.OpenFile(rel(r.Filepath), flags, 0o644)
+}
+
+func (h *sftpHandler) Filecmd(r *sftp.Request) error {
+	name := rel(r.Filepath)
+	switch r.Method {
+	case "Setstat":
+		return h.setstat(name, r)
+	case "Rename", "PosixRename":
+		return h.root.Rename(name, rel(r.Target))
+	case "Rmdir", "Remove":
+		return h.root.Remove(name)
+	case "Mkdir":
+		return h.root.Mkdir(name, 0o755)
+	case "Symlink":
+		return h.root.Symlink(r.Target, name)
+	case "Link":
+		return h.root.Link(rel(r.Target), name)
+	}
+	return sftp.ErrSSHFxOpUnsupported
+}
+
+func (h *sftpHandler) setstat(name string, r *sftp.Request) error {
+	flags, attrs := r.AttrFlags(), r.Attributes()
+	if flags.Size {
+		f, err := h.root.OpenFile(name, os.O_WRONLY, 0)
+		if err != nil {
+			return err
+		}
+		err = f.Truncate(int64(attrs.Size))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if flags.Permissions {
+		if err := h.root.Chmod(name, attrs.FileMode().Perm()); err != nil {
+			return err
+		}
+	}
+	if flags.Acmodtime {
+		if err := h.root.Chtimes(name, time.Unix(int64(attrs.Atime), 0), time.Unix(int64(attrs.Mtime), 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *sftpHandler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	name := rel(r.Filepath)
+	switch r.Method {
+	case "List":
+		entries, err := fs.ReadDir(h.root.FS(), name)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]os.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			infos = append(infos, info)
+		}
+		return listerAt(infos), nil
+	case "Stat":
+		info, err := h.root.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	case "Lstat":
+		info, err := h.root.Lstat(name)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt{info}, nil
+	}
+	return nil, sftp.ErrSSHFxOpUnsupported
+}
+
+func (h *sftpHandler) Readlink(p string) (string, error) {
+	return h.root.Readlink(rel(p))
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(dst []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(dst, l[offset:])
+	if n < len(dst) || offset+int64(n) == int64(len(l)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,538 @@
+// Package mockapi is a fake Thunder Compute API for offline development and
+// end-to-end tests. It keeps instances and snapshots in memory and moves
+// them through the same transient statuses as the real service, e.g.
+// PROVISIONING to RUNNING, STOPPING to STOPPED or SNAPPING back to RUNNING.
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/pkg/types"
+)
+
+// Config tunes a Server. The zero value answers at once, never fails and
+// finishes every transition immediately.
+type Config struct {
+	// Token, if set, is the only bearer token accepted. Otherwise any
+	// non-empty token is.
+	Token string
+	// Latency delays every response.
+	Latency time.Duration
+	// FailureRate is the fraction of requests, from 0 to 1, that fail with
+	// a 503 before reaching the handler.
+	FailureRate float64
+	// TransitionTime is how long instances and snapshots stay in a
+	// transient status such as PROVISIONING, STOPPING or CREATING.
+	TransitionTime time.Duration
+}
+
+// Server is an http.Handler serving the /v1 API.
+type Server struct {
+	cfg  Config
+	mux  *http.ServeMux
+	now  func() time.Time
+	rand func() float64
+
+	mu           sync.Mutex
+	instances    map[string]*instance
+	snapshots    []*snapshot
+	nextID       int
+	nextSnapshot int
+	// authorized maps an SSH public key, in wire format, to the UUID of the
+	// instance it was issued for.
+	authorized map[string]string
+	// replies are the responses already sent per Idempotency-Key.
+	replies map[string]reply
+	sshPort int
+
+	specs        map[string]api.GpuSpecConfig
+	pricing      map[string]float64
+	templates    types.ThunderTemplatesResponse
+	availability api.GPUAvailabilityResponse
+}
+
+type instance struct {
+	api.Instance
+	// next is the status the instance settles in at until, if it is in a
+	// transient one.
+	next  string
+	until time.Time
+}
+
+type snapshot struct {
+	api.Snapshot
+	until time.Time
+}
+
+type reply struct {
+	status int
+	body   any
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+}
+
+// New returns a server with an empty account and the default catalog of
+// GPU specs, prices and templates.
+func New(cfg Config) *Server {
+	s := &Server{
+		cfg:          cfg,
+		mux:          http.NewServeMux(),
+		now:          time.Now,
+		rand:         rand.Float64,
+		instances:    make(map[string]*instance),
+		authorized:   make(map[string]string),
+		replies:      make(map[string]reply),
+		specs:        defaultSpecs(),
+		pricing:      defaultPricing(),
+		templates:    defaultTemplates(),
+		availability: defaultAvailability(),
+	}
+
+	s.handle("GET /v1/auth/validate", s.validate)
+	s.handle("GET /v1/instances/list", s.listInstances)
+	s.handle("POST /v1/instances/create", s.createInstance)
+	s.handle("POST /v1/instances/{id}/delete", s.deleteInstance)
+	s.handle("POST /v1/instances/{id}/start", s.startInstance)
+	s.handle("POST /v1/instances/{id}/stop", s.stopInstance)
+	s.handle("POST /v1/instances/{id}/modify", s.modifyInstance)
+	s.handle("POST /v1/instances/{id}/add_key", s.addKey)
+	s.handle("GET /v1/snapshots/list", s.listSnapshots)
+	s.handle("POST /v1/snapshots/create", s.createSnapshot)
+	s.handle("DELETE /v1/snapshots/{id}", s.deleteSnapshot)
+	s.handle("GET /v1/specs", s.getSpecs)
+	s.handle("GET /v1/pricing", s.getPricing)
+	s.handle("GET /v1/status", s.getAvailability)
+	s.handle("GET /v1/thunder-templates", s.listTemplates)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetSSHPort makes instances report port as their SSH port, for use with
+// an SSHServer on 127.0.0.1.
+func (s *Server) SetSSHPort(port int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sshPort = port
+}
+
+// AddInstance seeds the account with a settled instance and returns its ID.
+// Unset fields get the values a default create would have given them.
+func (s *Server) AddInstance(inst api.Instance) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inst.ID == "" {
+		inst.ID = s.newID()
+	}
+	if inst.UUID == "" {
+		inst.UUID = "mock-" + inst.ID
+	}
+	if inst.Name == "" {
+		inst.Name = "instance-" + inst.ID
+	}
+	if inst.Status == "" {
+		inst.Status = string(types.InstanceStatus_Running)
+	}
+	s.instances[inst.ID] = &instance{Instance: inst}
+	return inst.ID
+}
+
+// handlerFunc answers a request with a status and a JSON body. Handlers run
+// with s.mu held and every transition due already applied.
+type handlerFunc func(r *http.Request) (int, any)
+
+func (s *Server) handle(pattern string, h handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		status, body := s.serve(r, h)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if body != nil {
+			_ = json.NewEncoder(w).Encode(body)
+		}
+	})
+}
+
+func (s *Server) serve(r *http.Request, h handlerFunc) (int, any) {
+	if s.cfg.Latency > 0 {
+		select {
+		case <-time.After(s.cfg.Latency):
+		case <-r.Context().Done():
+			return http.StatusServiceUnavailable, errorBody{Message: "request cancelled"}
+		}
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" || (s.cfg.Token != "" && token != s.cfg.Token) {
+		return http.StatusUnauthorized, errorBody{Message: "invalid token"}
+	}
+	if s.cfg.FailureRate > 0 && s.rand() < s.cfg.FailureRate {
+		return http.StatusServiceUnavailable, errorBody{Message: "injected failure"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := r.Header.Get("Idempotency-Key")
+	if prev, ok := s.replies[key]; ok && key != "" {
+		return prev.status, prev.body
+	}
+	s.advance()
+	status, body := h(r)
+	if key != "" && status < 500 {
+		s.replies[key] = reply{status: status, body: body}
+	}
+	return status, body
+}
+
+// advance settles every instance and snapshot whose transition is over.
+func (s *Server) advance() {
+	now := s.now()
+	for _, inst := range s.instances {
+		if inst.next != "" && !now.Before(inst.until) {
+			inst.Status, inst.next = inst.next, ""
+		}
+	}
+	for _, snap := range s.snapshots {
+		if snap.Status == "CREATING" && !now.Before(snap.until) {
+			snap.Status = "READY"
+		}
+	}
+}
+
+// transition puts inst in the transient status, to settle in final after
+// the configured TransitionTime.
+func (s *Server) transition(inst *instance, transient, final types.InstanceStatus) {
+	if s.cfg.TransitionTime <= 0 {
+		inst.Status, inst.next = string(final), ""
+		return
+	}
+	inst.Status, inst.next = string(transient), string(final)
+	inst.until = s.now().Add(s.cfg.TransitionTime)
+}
+
+func (s *Server) newID() string {
+	for {
+		id := strconv.Itoa(s.nextID)
+		s.nextID++
+		if _, taken := s.instances[id]; !taken {
+			return id
+		}
+	}
+}
+
+// view is inst as the API reports it.
+func (s *Server) view(inst *instance) api.Instance {
+	v := inst.Instance
+	ip := "127.0.0.1"
+	v.IP = &ip
+	v.Port = 22
+	if s.sshPort != 0 {
+		v.Port = s.sshPort
+	}
+	return v
+}
+
+func (s *Server) lookup(r *http.Request) (*instance, int, any) {
+	inst, ok := s.instances[r.PathValue("id")]
+	if !ok {
+		return nil, http.StatusNotFound, errorBody{Message: "instance not found"}
+	}
+	return inst, 0, nil
+}
+
+func badRequest(format string, args ...any) (int, any) {
+	return http.StatusBadRequest, errorBody{Message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...any) (int, any) {
+	return http.StatusConflict, errorBody{Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) validate(r *http.Request) (int, any) {
+	return http.StatusOK, api.ValidateTokenResult{Valid: true, Email: "dev@localhost", OrgName: "Mock"}
+}
+
+func (s *Server) listInstances(r *http.Request) (int, any) {
+	list := make(map[string]api.Instance, len(s.instances))
+	for id, inst := range s.instances {
+		list[id] = s.view(inst)
+	}
+	return http.StatusOK, list
+}
+
+func (s *Server) createInstance(r *http.Request) (int, any) {
+	var req api.CreateInstanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	if !types.IsValidInstanceMode(req.Mode) {
+		return badRequest("invalid mode %q", req.Mode)
+	}
+	if req.NumGPUs <= 0 {
+		req.NumGPUs = 1
+	}
+	spec, ok := s.specs[fmt.Sprintf("%s_x%d_%s", req.GPUType, req.NumGPUs, req.Mode)]
+	if !ok {
+		return badRequest("no %s configuration with %d x %s", req.Mode, req.NumGPUs, req.GPUType)
+	}
+	if req.CPUCores == 0 && len(spec.VcpuOptions) > 0 {
+		req.CPUCores = spec.VcpuOptions[0]
+	}
+	if req.DiskSizeGB == 0 {
+		req.DiskSizeGB = spec.StorageGB.Min
+	}
+
+	transient := types.InstanceStatus_Provisioning
+	if _, isTemplate := s.templates[req.Template]; !isTemplate {
+		snap := s.findSnapshot(req.Template)
+		if snap == nil || snap.Status != "READY" {
+			return badRequest("unknown template or snapshot %q", req.Template)
+		}
+		if req.DiskSizeGB < snap.MinimumDiskSizeGB {
+			return badRequest("snapshot %q needs a disk of at least %d GB", snap.Name, snap.MinimumDiskSizeGB)
+		}
+		transient = types.InstanceStatus_Restoring
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return http.StatusInternalServerError, errorBody{Message: err.Error()}
+	}
+	id := s.newID()
+	name := req.Name
+	if name == "" {
+		name = "instance-" + id
+	}
+	inst := &instance{Instance: api.Instance{
+		ID:              id,
+		Name:            name,
+		CreatedAt:       s.now().UTC().Format(time.RFC3339),
+		UUID:            fmt.Sprintf("mock-%s-%d", id, s.now().UnixNano()),
+		Storage:         req.DiskSizeGB,
+		EphemeralDiskGB: req.EphemeralDiskGB,
+		CPUCores:        strconv.Itoa(req.CPUCores),
+		Template:        req.Template,
+		GPUType:         req.GPUType,
+		NumGPUs:         strconv.Itoa(req.NumGPUs),
+		Memory:          strconv.Itoa(req.CPUCores * spec.RamPerVCPUGiB),
+		Mode:            string(req.Mode),
+	}}
+	s.authorized[key.authorized] = inst.UUID
+	s.instances[id] = inst
+	s.transition(inst, transient, types.InstanceStatus_Running)
+
+	n, _ := strconv.Atoi(id)
+	return http.StatusOK, api.CreateInstanceResponse{UUID: inst.UUID, Key: key.private, Identifier: n}
+}
+
+func (s *Server) deleteInstance(r *http.Request) (int, any) {
+	inst, status, body := s.lookup(r)
+	if inst == nil {
+		return status, body
+	}
+	delete(s.instances, inst.ID)
+	for key, uuid := range s.authorized {
+		if uuid == inst.UUID {
+			delete(s.authorized, key)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func (s *Server) startInstance(r *http.Request) (int, any) {
+	inst, status, body := s.lookup(r)
+	if inst == nil {
+		return status, body
+	}
+	if inst.Status != string(types.InstanceStatus_Stopped) {
+		return conflict("instance %s is %s, not STOPPED", inst.ID, inst.Status)
+	}
+	s.transition(inst, types.InstanceStatus_Starting, types.InstanceStatus_Running)
+	return http.StatusOK, nil
+}
+
+func (s *Server) stopInstance(r *http.Request) (int, any) {
+	inst, status, body := s.lookup(r)
+	if inst == nil {
+		return status, body
+	}
+	if inst.Status != string(types.InstanceStatus_Running) {
+		return conflict("instance %s is %s, not RUNNING", inst.ID, inst.Status)
+	}
+	s.transition(inst, types.InstanceStatus_Stopping, types.InstanceStatus_Stopped)
+	return http.StatusOK, nil
+}
+
+func (s *Server) modifyInstance(r *http.Request) (int, any) {
+	inst, status, body := s.lookup(r)
+	if inst == nil {
+		return status, body
+	}
+	var req api.InstanceModifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	if inst.Status != string(types.InstanceStatus_Running) {
+		return conflict("instance %s is %s, not RUNNING", inst.ID, inst.Status)
+	}
+	if req.DiskSizeGB != nil && *req.DiskSizeGB < inst.Storage {
+		return badRequest("disk size cannot shrink from %d GB", inst.Storage)
+	}
+	if req.CPUCores != nil {
+		inst.CPUCores = strconv.Itoa(*req.CPUCores)
+	}
+	if req.GPUType != nil {
+		inst.GPUType = *req.GPUType
+	}
+	if req.NumGPUs != nil {
+		inst.NumGPUs = strconv.Itoa(*req.NumGPUs)
+	}
+	if req.DiskSizeGB != nil {
+		inst.Storage = *req.DiskSizeGB
+	}
+	if req.EphemeralDiskGB != nil {
+		inst.EphemeralDiskGB = *req.EphemeralDiskGB
+	}
+	if req.Mode != nil {
+		inst.Mode = string(*req.Mode)
+	}
+	inst.HTTPPorts = modifyPorts(inst.HTTPPorts, req.AddPorts, req.RemovePorts)
+
+	// Port changes apply live; anything else restarts the instance.
+	if req.CPUCores != nil || req.GPUType != nil || req.NumGPUs != nil || req.DiskSizeGB != nil || req.EphemeralDiskGB != nil || req.Mode != nil {
+		s.transition(inst, types.InstanceStatus_Modifying, types.InstanceStatus_Running)
+	}
+	numGPUs, _ := strconv.Atoi(inst.NumGPUs)
+	return http.StatusOK, api.InstanceModifyResponse{
+		Identifier:   inst.ID,
+		InstanceName: inst.Name,
+		Mode:         &inst.Mode,
+		GPUType:      &inst.GPUType,
+		NumGPUs:      &numGPUs,
+		HTTPPorts:    inst.HTTPPorts,
+	}
+}
+
+func modifyPorts(ports, add, remove []int) []int {
+	removed := make(map[int]bool, len(remove))
+	for _, p := range remove {
+		removed[p] = true
+	}
+	seen := make(map[int]bool)
+	var result []int
+	for _, p := range append(append([]int(nil), ports...), add...) {
+		if !removed[p] && !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+func (s *Server) addKey(r *http.Request) (int, any) {
+	inst, status, body := s.lookup(r)
+	if inst == nil {
+		return status, body
+	}
+	if inst.Status != string(types.InstanceStatus_Running) {
+		return conflict("instance %s is %s, not RUNNING", inst.ID, inst.Status)
+	}
+	key, err := newKey()
+	if err != nil {
+		return http.StatusInternalServerError, errorBody{Message: err.Error()}
+	}
+	s.authorized[key.authorized] = inst.UUID
+	return http.StatusOK, api.AddSSHKeyResponse{UUID: inst.UUID, Key: &key.private, Success: true}
+}
+
+func (s *Server) listSnapshots(r *http.Request) (int, any) {
+	list := make(api.ListSnapshotsResponse, 0, len(s.snapshots))
+	for _, snap := range s.snapshots {
+		list = append(list, snap.Snapshot)
+	}
+	return http.StatusOK, list
+}
+
+func (s *Server) findSnapshot(name string) *snapshot {
+	for _, snap := range s.snapshots {
+		if snap.Name == name {
+			return snap
+		}
+	}
+	return nil
+}
+
+func (s *Server) createSnapshot(r *http.Request) (int, any) {
+	var req api.CreateSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	if req.Name == "" {
+		return badRequest("snapshot name is required")
+	}
+	inst, ok := s.instances[req.InstanceID]
+	if !ok {
+		return http.StatusNotFound, errorBody{Message: "instance not found"}
+	}
+	if inst.Status != string(types.InstanceStatus_Running) {
+		return conflict("instance %s is %s, not RUNNING", inst.ID, inst.Status)
+	}
+	if s.findSnapshot(req.Name) != nil {
+		return conflict("a snapshot named %q already exists", req.Name)
+	}
+
+	s.nextSnapshot++
+	snap := &snapshot{Snapshot: api.Snapshot{
+		ID:                fmt.Sprintf("snap-%d", s.nextSnapshot),
+		Name:              req.Name,
+		MinimumDiskSizeGB: inst.Storage,
+		Status:            "READY",
+		CreatedAt:         s.now().Unix(),
+	}}
+	if s.cfg.TransitionTime > 0 {
+		snap.Status = "CREATING"
+		snap.until = s.now().Add(s.cfg.TransitionTime)
+	}
+	s.snapshots = append(s.snapshots, snap)
+	s.transition(inst, types.InstanceStatus_Snapshotting, types.InstanceStatus_Running)
+	return http.StatusOK, api.CreateSnapshotResponse{Message: "Snapshot creation started"}
+}
+
+func (s *Server) deleteSnapshot(r *http.Request) (int, any) {
+	id := r.PathValue("id")
+	for i, snap := range s.snapshots {
+		if snap.ID == id {
+			s.snapshots = append(s.snapshots[:i], s.snapshots[i+1:]...)
+			return http.StatusOK, nil
+		}
+	}
+	return http.StatusNotFound, errorBody{Message: "snapshot not found"}
+}
+
+func (s *Server) getSpecs(r *http.Request) (int, any) {
+	return http.StatusOK, map[string]any{"specs": s.specs}
+}
+
+func (s *Server) getPricing(r *http.Request) (int, any) {
+	return http.StatusOK, map[string]any{"pricing": s.pricing}
+}
+
+func (s *Server) getAvailability(r *http.Request) (int, any) {
+	return http.StatusOK, s.availability
+}
+
+func (s *Server) listTemplates(r *http.Request) (int, any) {
+	return http.StatusOK, s.templates
+}
@@ -0,0 +1,143 @@
+package mockapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/pkg/thunder"
+)
+
+// fakeClock lets tests step through transitions.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestServer(t *testing.T, cfg Config) (*Server, *fakeClock, thunder.Client) {
+	t.Helper()
+	s := New(cfg)
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s.now = clock.now
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	client := thunder.New("token", thunder.WithBaseURL(ts.URL), thunder.WithRetryPolicy(api.NoRetries))
+	return s, clock, client
+}
+
+func status(t *testing.T, client thunder.Client, id string) string {
+	t.Helper()
+	instances, err := client.ListInstances(context.Background())
+	require.NoError(t, err)
+	for _, inst := range instances {
+		if inst.ID == id {
+			return inst.Status
+		}
+	}
+	t.Fatalf("instance %s not listed", id)
+	return ""
+}
+
+func TestInstanceStateMachine(t *testing.T) {
+	ctx := context.Background()
+	_, clock, client := newTestServer(t, Config{TransitionTime: time.Minute})
+
+	resp, err := client.CreateInstance(ctx, thunder.CreateInstanceRequest{Mode: "prototyping", GPUType: "a100xl", NumGPUs: 1, Template: "base"})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Key, "PRIVATE KEY")
+	id := "0"
+	assert.Equal(t, "PROVISIONING", status(t, client, id))
+	assert.True(t, thunder.IsConflict(client.StopInstance(ctx, id)))
+
+	clock.advance(time.Minute)
+	assert.Equal(t, "RUNNING", status(t, client, id))
+
+	require.NoError(t, client.StopInstance(ctx, id))
+	assert.Equal(t, "STOPPING", status(t, client, id))
+	clock.advance(time.Minute)
+	assert.Equal(t, "STOPPED", status(t, client, id))
+
+	require.NoError(t, client.StartInstance(ctx, id))
+	clock.advance(time.Minute)
+	assert.Equal(t, "RUNNING", status(t, client, id))
+
+	_, err = client.CreateSnapshot(ctx, thunder.CreateSnapshotRequest{InstanceID: id, Name: "snap"})
+	require.NoError(t, err)
+	assert.Equal(t, "SNAPPING", status(t, client, id))
+	snapshots, err := client.ListSnapshots(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "CREATING", snapshots[0].Status)
+
+	// A snapshot cannot be restored before it is ready.
+	_, err = client.CreateInstance(ctx, thunder.CreateInstanceRequest{Mode: "prototyping", GPUType: "a100xl", NumGPUs: 1, Template: "snap"})
+	require.Error(t, err)
+
+	clock.advance(time.Minute)
+	assert.Equal(t, "RUNNING", status(t, client, id))
+	_, err = client.CreateInstance(ctx, thunder.CreateInstanceRequest{Mode: "prototyping", GPUType: "a100xl", NumGPUs: 1, Template: "snap"})
+	require.NoError(t, err)
+	assert.Equal(t, "RESTORING", status(t, client, "1"))
+
+	require.NoError(t, client.DeleteInstance(ctx, id))
+	assert.True(t, thunder.IsNotFound(client.DeleteInstance(ctx, id)))
+}
+
+func TestModifyInstance(t *testing.T) {
+	ctx := context.Background()
+	s, _, client := newTestServer(t, Config{})
+	id := s.AddInstance(api.Instance{Storage: 100})
+
+	modified, err := client.ModifyInstance(ctx, id, thunder.InstanceModifyRequest{AddPorts: []int{8080, 443}, RemovePorts: []int{443}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{8080}, modified.HTTPPorts)
+
+	smaller := 50
+	_, err = client.ModifyInstance(ctx, id, thunder.InstanceModifyRequest{DiskSizeGB: &smaller})
+	require.Error(t, err)
+}
+
+func TestAuthAndFailureInjection(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestServer(t, Config{Token: "secret", FailureRate: 0.5})
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	wrong := thunder.New("other", thunder.WithBaseURL(ts.URL), thunder.WithRetryPolicy(api.NoRetries))
+	_, err := wrong.ListInstances(ctx)
+	assert.True(t, thunder.IsUnauthorized(err))
+
+	client := thunder.New("secret", thunder.WithBaseURL(ts.URL), thunder.WithRetryPolicy(api.NoRetries))
+	s.rand = func() float64 { return 0.4 }
+	_, err = client.ListInstances(ctx)
+	var apiErr *thunder.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	s.rand = func() float64 { return 0.6 }
+	_, err = client.ListInstances(ctx)
+	require.NoError(t, err)
+}
+
+func TestIdempotencyKeyReplaysReply(t *testing.T) {
+	s, _, _ := newTestServer(t, Config{})
+	id := s.AddInstance(api.Instance{})
+
+	stop := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/instances/"+id+"/stop", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Idempotency-Key", "retry-1")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, stop())
+	// Without the key the second stop would conflict with the first.
+	assert.Equal(t, http.StatusOK, stop())
+}
@@ -0,0 +1,277 @@
+package mockapi
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHServer lets tnr connect, exec and scp to the mock instances of a
+// Server. It accepts the keys the Server issued, while their instance is
+// RUNNING.
+//
+// Each instance gets a scratch directory standing in for its filesystem.
+// SFTP is confined to it, with / at its root. Commands run on this machine
+// under sh, in the instance's /home/ubuntu, with that path in the command
+// rewritten to the scratch copy and sudo running commands unprivileged.
+type SSHServer struct {
+	api    *Server
+	root   string
+	config *ssh.ServerConfig
+	shims  string
+}
+
+// NewSSHServer returns an SSH server for the instances of api, keeping
+// their files under root.
+func NewSSHServer(api *Server, root string) (*SSHServer, error) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate host key: %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate host key: %w", err)
+	}
+
+	s := &SSHServer{api: api, root: root, shims: filepath.Join(root, ".bin")}
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			inst, ok := api.authorize(key)
+			if !ok {
+				return nil, fmt.Errorf("unknown key for %s", conn.User())
+			}
+			return &ssh.Permissions{Extensions: map[string]string{"uuid": inst.UUID}}, nil
+		},
+	}
+	s.config.AddHostKey(signer)
+
+	if err := os.MkdirAll(s.shims, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", s.shims, err)
+	}
+	sudo := "#!/bin/sh\nwhile [ \"${1#-}\" != \"$1\" ]; do shift; done\nexec \"$@\"\n"
+	if err := os.WriteFile(filepath.Join(s.shims, "sudo"), []byte(sudo), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to write sudo shim: %w", err)
+	}
+	return s, nil
+}
+
+// Serve accepts connections on ln until it is closed.
+func (s *SSHServer) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *SSHServer) handleConn(conn net.Conn) {
+	defer conn.Close()
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	home, err := s.instanceHome(sconn.Permissions.Extensions["uuid"])
+	if err != nil {
+		return
+	}
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go s.handleSession(channel, requests, home)
+		case "direct-tcpip":
+			go handleDirectTCPIP(newChannel)
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+// instanceHome returns the scratch directory of an instance and creates its
+// /home/ubuntu.
+func (s *SSHServer) instanceHome(uuid string) (*sandbox, error) {
+	if uuid == "" || strings.ContainsAny(uuid, `/\`) || uuid == "." || uuid == ".." {
+		return nil, fmt.Errorf("invalid instance %q", uuid)
+	}
+	sb := &sandbox{root: filepath.Join(s.root, uuid)}
+	if err := os.MkdirAll(sb.home(), 0o755); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+func (s *SSHServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request, sb *sandbox) {
+	defer channel.Close()
+	var env []string
+	pty := false
+	for req := range requests {
+		switch req.Type {
+		case "env":
+			var kv struct{ Name, Value string }
+			if ssh.Unmarshal(req.Payload, &kv) == nil {
+				env = append(env, kv.Name+"="+kv.Value)
+			}
+			_ = req.Reply(true, nil)
+		case "pty-req":
+			pty = true
+			_ = req.Reply(true, nil)
+		case "window-change":
+			_ = req.Reply(true, nil)
+		case "exec":
+			var payload struct{ Command string }
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			s.run(channel, sb, env, pty, "-c", sb.rewrite(payload.Command))
+			return
+		case "shell":
+			_ = req.Reply(true, nil)
+			s.run(channel, sb, env, pty, "-i")
+			return
+		case "subsystem":
+			var payload struct{ Name string }
+			if ssh.Unmarshal(req.Payload, &payload) != nil || payload.Name != "sftp" {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			_ = req.Reply(true, nil)
+			sb.serveSFTP(channel)
+			return
+		default:
+			if req.WantReply {
+				_ = req.Reply(false, nil)
+			}
+		}
+	}
+}
+
+// run runs sh with args in the sandbox and reports its exit status. With
+// pty set, input and output pass through copyLines and crlfWriter.
+func (s *SSHServer) run(channel ssh.Channel, sb *sandbox, env []string, pty bool, args ...string) {
+	cmd := exec.Command("sh", args...)
+	cmd.Dir = sb.home()
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Env = append(cmd.Env, "HOME="+sb.home(), "USER=ubuntu", "PATH="+s.shims+string(os.PathListSeparator)+os.Getenv("PATH"))
+	var stdout, stderr io.Writer = channel, channel.Stderr()
+	if pty {
+		stdout, stderr = crlfWriter{stdout}, crlfWriter{stderr}
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// Copy stdin separately: exec would otherwise wait for the client to
+	// close it before returning.
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "mock-api: %v\n", err)
+		sendExitStatus(channel, 127)
+		return
+	}
+	go func() {
+		if pty {
+			copyLines(stdin, channel, channel)
+		} else {
+			_, _ = io.Copy(stdin, channel)
+		}
+		stdin.Close()
+	}()
+
+	status := 0
+	if err := cmd.Wait(); err != nil {
+		status = 1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() >= 0 {
+			status = exitErr.ExitCode()
+		}
+	}
+	sendExitStatus(channel, uint32(status))
+}
+
+func sendExitStatus(channel ssh.Channel, status uint32) {
+	_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+}
+
+// handleDirectTCPIP serves a port forward by dialing its destination from
+// this machine.
+func handleDirectTCPIP(newChannel ssh.NewChannel) {
+	var payload struct {
+		DestAddr string
+		DestPort uint32
+		OrigAddr string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, "invalid payload")
+		return
+	}
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.DestAddr, fmt.Sprint(payload.DestPort)))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(channel, target)
+		_ = channel.CloseWrite()
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = io.Copy(target, channel)
+		if tc, ok := target.(*net.TCPConn); ok {
+			_ = tc.CloseWrite()
+		}
+	}()
+	wg.Wait()
+	channel.Close()
+	target.Close()
+}
+
+// serveSFTP serves the sandbox over SFTP until the client disconnects.
+func (sb *sandbox) serveSFTP(channel ssh.Channel) {
+	root, err := os.OpenRoot(sb.root)
+	if err != nil {
+		return
+	}
+	defer root.Close()
+	h := &sftpHandler{root: root}
+	handlers := sftp.Handlers{FileGet: h, FilePut: h, FileCmd: h, FileList: h}
+	server := sftp.NewRequestServer(channel, handlers, sftp.WithStartDirectory(remoteHome))
+	_ = server.Serve()
+	server.Close()
+}
@@ -0,0 +1,113 @@
+package mockapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/Thunder-Compute/thunder-cli/pkg/thunder"
+)
+
+func TestSSHServer(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	ctx := context.Background()
+	s, _, client := newTestServer(t, Config{})
+
+	sshServer, err := NewSSHServer(s, t.TempDir())
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() { _ = sshServer.Serve(ln) }()
+
+	resp, err := client.CreateInstance(ctx, thunder.CreateInstanceRequest{Mode: "prototyping", GPUType: "a100xl", NumGPUs: 1, Template: "base"})
+	require.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey([]byte(resp.Key))
+	require.NoError(t, err)
+
+	conn, err := ssh.Dial("tcp", ln.Addr().String(), &ssh.ClientConfig{
+		User:            "ubuntu",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// sudo and /home/ubuntu both work, as SetupToken relies on them.
+	session, err := conn.NewSession()
+	require.NoError(t, err)
+	out, err := session.CombinedOutput("sudo -E mkdir -p /home/ubuntu/.thunder && echo hi > /home/ubuntu/.thunder/token && pwd")
+	session.Close()
+	require.NoError(t, err, string(out))
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(string(out)), "/home/ubuntu"))
+
+	session, err = conn.NewSession()
+	require.NoError(t, err)
+	err = session.Run("exit 3")
+	session.Close()
+	var exitErr *ssh.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitStatus())
+
+	// Keystrokes from a terminal end lines with CR.
+	session, err = conn.NewSession()
+	require.NoError(t, err)
+	require.NoError(t, session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}))
+	session.Stdin = strings.NewReader("echo $((6*7))\rexit\r")
+	var shellOut strings.Builder
+	session.Stdout = &shellOut
+	require.NoError(t, session.Shell())
+	require.NoError(t, session.Wait())
+	session.Close()
+	assert.Contains(t, shellOut.String(), "42\r\n")
+
+	sftpClient, err := sftp.NewClient(conn)
+	require.NoError(t, err)
+	defer sftpClient.Close()
+
+	f, err := sftpClient.Open("/home/ubuntu/.thunder/token")
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	f.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hi\n", string(data))
+
+	wd, err := sftpClient.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, "/home/ubuntu", wd)
+
+	// Paths cannot climb out of the instance's directory.
+	_, err = sftpClient.Stat("/../../../../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestSSHServerRejectsUnknownKeys(t *testing.T) {
+	s := New(Config{})
+	sshServer, err := NewSSHServer(s, t.TempDir())
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() { _ = sshServer.Serve(ln) }()
+
+	key, err := newKey()
+	require.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey([]byte(key.private))
+	require.NoError(t, err)
+	_, err = ssh.Dial("tcp", ln.Addr().String(), &ssh.ClientConfig{
+		User:            "ubuntu",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	assert.Error(t, err)
+}
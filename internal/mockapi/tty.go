@@ -0,0 +1,61 @@
+package mockapi
+
+import (
+	"bytes"
+	"io"
+)
+
+// The SSH server has no real terminal to give clients that request a PTY,
+// so it does the little a terminal's line discipline must for a remote
+// shell to be usable: it echoes input, edits it a line at a time and turns
+// the newlines of the output into CRLF.
+
+// copyLines copies keystrokes from src to dst a line at a time, echoing
+// them to echo. It stops at Ctrl+D on an empty line or when src ends.
+func copyLines(dst io.Writer, src io.Reader, echo io.Writer) {
+	var line []byte
+	buf := make([]byte, 1024)
+	for {
+		n, err := src.Read(buf)
+		for _, b := range buf[:n] {
+			switch b {
+			case '\r', '\n':
+				_, _ = echo.Write([]byte("\r\n"))
+				if _, err := dst.Write(append(line, '\n')); err != nil {
+					return
+				}
+				line = line[:0]
+			case 0x7f, '\b':
+				if len(line) > 0 {
+					line = line[:len(line)-1]
+					_, _ = echo.Write([]byte("\b \b"))
+				}
+			case 0x03: // Ctrl+C discards the line.
+				line = line[:0]
+				_, _ = echo.Write([]byte("^C\r\n"))
+			case 0x04: // Ctrl+D ends input on an empty line.
+				if len(line) == 0 {
+					return
+				}
+			default:
+				line = append(line, b)
+				_, _ = echo.Write([]byte{b})
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// crlfWriter writes to w with every "\n" turned into "\r\n".
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
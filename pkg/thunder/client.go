@@ -0,0 +1,167 @@
+package thunder
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// DefaultUserAgent is sent unless WithUserAgent names another.
+const DefaultUserAgent = "thunder-go"
+
+// DefaultTimeout bounds each HTTP request unless WithTimeout or
+// WithHTTPClient says otherwise.
+const DefaultTimeout = 30 * time.Second
+
+type options struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userAgent  string
+	retry      *RetryPolicy
+	tokens     TokenSource
+}
+
+// Option configures a client made by New.
+type Option func(*options)
+
+// WithBaseURL points the client at another API, such as a local mock.
+func WithBaseURL(url string) Option {
+	return func(o *options) { o.baseURL = url }
+}
+
+// WithHTTPClient sends requests through hc, e.g. to add a proxy or
+// tracing transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(o *options) { o.httpClient = hc }
+}
+
+// WithTimeout bounds each HTTP request, overriding the timeout of a client
+// given to WithHTTPClient. Retries get a fresh timeout each.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(o *options) { o.userAgent = ua }
+}
+
+// WithRetryPolicy replaces api.DefaultRetryPolicy. Use api.NoRetries to
+// send every request exactly once.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) { o.retry = &p }
+}
+
+// WithTokenSource takes the bearer token from ts instead of the fixed token
+// given to New, refreshing it when the server rejects it.
+func WithTokenSource(ts TokenSource) Option {
+	return func(o *options) { o.tokens = ts }
+}
+
+// HTTPClient is the Client that talks to the API over HTTP.
+type HTTPClient struct {
+	c *api.Client
+}
+
+var _ Client = (*HTTPClient)(nil)
+
+// New returns a client that authenticates with token. Unlike the tnr CLI,
+// it reports nothing to Sentry.
+func New(token string, opts ...Option) *HTTPClient {
+	o := options{baseURL: DefaultBaseURL, userAgent: DefaultUserAgent, timeout: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	hc := o.httpClient
+	if hc == nil {
+		hc = &http.Client{Timeout: DefaultTimeout}
+	}
+	if o.timeout >= 0 {
+		copied := *hc
+		copied.Timeout = o.timeout
+		hc = &copied
+	}
+
+	c := api.NewClient(token, o.baseURL)
+	c.SetHTTPClient(hc)
+	c.SetUserAgent(o.userAgent)
+	c.SetErrorReporting(false)
+	if o.retry != nil {
+		c.SetRetryPolicy(*o.retry)
+	}
+	if o.tokens != nil {
+		c.SetTokenSource(o.tokens)
+	}
+	return &HTTPClient{c: c}
+}
+
+func (h *HTTPClient) ValidateToken(ctx context.Context) (*ValidateTokenResult, error) {
+	return h.c.ValidateToken(ctx)
+}
+
+func (h *HTTPClient) ListInstances(ctx context.Context) ([]Instance, error) {
+	return h.c.ListInstancesCtx(ctx)
+}
+
+func (h *HTTPClient) ListInstancesWithIPUpdate(ctx context.Context) ([]Instance, error) {
+	return h.c.ListInstancesWithIPUpdateCtx(ctx)
+}
+
+func (h *HTTPClient) CreateInstance(ctx context.Context, req CreateInstanceRequest) (*CreateInstanceResponse, error) {
+	return h.c.CreateInstanceCtx(ctx, req)
+}
+
+func (h *HTTPClient) ModifyInstance(ctx context.Context, instanceID string, req InstanceModifyRequest) (*InstanceModifyResponse, error) {
+	return h.c.ModifyInstanceCtx(ctx, instanceID, req)
+}
+
+func (h *HTTPClient) DeleteInstance(ctx context.Context, instanceID string) error {
+	_, err := h.c.DeleteInstanceCtx(ctx, instanceID)
+	return err
+}
+
+func (h *HTTPClient) StartInstance(ctx context.Context, instanceID string) error {
+	_, err := h.c.StartInstanceCtx(ctx, instanceID)
+	return err
+}
+
+func (h *HTTPClient) StopInstance(ctx context.Context, instanceID string) error {
+	_, err := h.c.StopInstanceCtx(ctx, instanceID)
+	return err
+}
+
+func (h *HTTPClient) AddSSHKey(ctx context.Context, instanceID string) (*AddSSHKeyResponse, error) {
+	return h.c.AddSSHKeyCtx(ctx, instanceID)
+}
+
+func (h *HTTPClient) ListSnapshots(ctx context.Context) ([]Snapshot, error) {
+	return h.c.ListSnapshotsCtx(ctx)
+}
+
+func (h *HTTPClient) CreateSnapshot(ctx context.Context, req CreateSnapshotRequest) (*CreateSnapshotResponse, error) {
+	return h.c.CreateSnapshotCtx(ctx, req)
+}
+
+func (h *HTTPClient) DeleteSnapshot(ctx context.Context, snapshotID string) error {
+	return h.c.DeleteSnapshotCtx(ctx, snapshotID)
+}
+
+func (h *HTTPClient) ListTemplates(ctx context.Context) ([]TemplateEntry, error) {
+	return h.c.ListTemplatesCtx(ctx)
+}
+
+func (h *HTTPClient) GetSpecs(ctx context.Context) (map[string]GpuSpecConfig, error) {
+	return h.c.GetSpecsCtx(ctx)
+}
+
+func (h *HTTPClient) GetPricing(ctx context.Context) (map[string]float64, error) {
+	return h.c.FetchPricingCtx(ctx)
+}
+
+func (h *HTTPClient) GetAvailability(ctx context.Context) (*GPUAvailabilityResponse, error) {
+	return h.c.GetAvailabilityCtx(ctx)
+}
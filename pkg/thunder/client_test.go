@@ -0,0 +1,86 @@
+package thunder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+func TestNewAppliesOptions(t *testing.T) {
+	var userAgent, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, auth = r.UserAgent(), r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"1":{"name":"one","status":"RUNNING"},"0":{"name":"zero","status":"STOPPED"}}`))
+	}))
+	defer server.Close()
+
+	client := New("tok", WithBaseURL(server.URL), WithUserAgent("orchestrator/1.0"))
+	instances, err := client.ListInstances(context.Background())
+	require.NoError(t, err)
+	require.Len(t, instances, 2)
+	assert.Equal(t, "0", instances[0].ID)
+	assert.Equal(t, "orchestrator/1.0", userAgent)
+	assert.Equal(t, "Bearer tok", auth)
+
+	_, err = New("tok", WithBaseURL(server.URL)).ListInstances(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultUserAgent, userAgent)
+}
+
+func TestWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New("tok", WithBaseURL(server.URL), WithTimeout(20*time.Millisecond), WithRetryPolicy(api.NoRetries))
+	_, err := client.ListInstances(context.Background())
+	assert.ErrorIs(t, err, ErrTransport)
+
+	custom := &http.Client{Timeout: time.Hour}
+	New("tok", WithHTTPClient(custom), WithTimeout(time.Second))
+	assert.Equal(t, time.Hour, custom.Timeout, "the caller's client is not modified")
+}
+
+func TestTypedErrors(t *testing.T) {
+	statuses := map[string]int{
+		"/v1/instances/1/stop":   http.StatusConflict,
+		"/v1/instances/2/stop":   http.StatusNotFound,
+		"/v1/snapshots/s":        http.StatusNotFound,
+		"/v1/instances/3/modify": http.StatusConflict,
+		"/v1/auth/validate":      http.StatusUnauthorized,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[r.URL.Path])
+	}))
+	defer server.Close()
+	client := New("tok", WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	err := client.StopInstance(ctx, "1")
+	assert.True(t, IsConflict(err))
+	assert.EqualError(t, err, "instance cannot be stopped (must be RUNNING)")
+
+	err = client.StopInstance(ctx, "2")
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsConflict(err))
+
+	assert.True(t, IsNotFound(client.DeleteSnapshot(ctx, "s")))
+
+	_, err = client.ModifyInstance(ctx, "3", InstanceModifyRequest{})
+	assert.True(t, IsConflict(err))
+
+	_, err = client.ValidateToken(ctx)
+	assert.True(t, IsUnauthorized(err))
+
+	assert.False(t, IsNotFound(nil))
+}
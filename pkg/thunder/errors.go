@@ -0,0 +1,37 @@
+package thunder
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// APIError is a response with status 400 or above.
+type APIError = api.APIError
+
+// ErrTransport wraps failures to reach the API at all, such as DNS errors
+// or dropped connections.
+var ErrTransport = api.ErrTransport
+
+// IsNotFound reports whether err is a 404, e.g. for an unknown instance.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a 409, e.g. for stopping an instance
+// that is not running.
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized reports whether err is a 401: the token is missing,
+// invalid or expired.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
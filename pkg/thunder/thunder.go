@@ -0,0 +1,74 @@
+// Package thunder is a Go client for the Thunder Compute API.
+//
+// New returns a Client that talks to the API over HTTP:
+//
+//	client := thunder.New(os.Getenv("TNR_API_TOKEN"))
+//	instances, err := client.ListInstances(ctx)
+//
+// Programs that drive Thunder Compute should depend on the Client interface,
+// so that their tests can use the in-memory fake in package thunderfake.
+package thunder
+
+import (
+	"context"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// DefaultBaseURL is the production API.
+const DefaultBaseURL = "https://api.thundercompute.com:8443"
+
+// Request and response types are shared with the tnr CLI.
+type (
+	Instance                = api.Instance
+	InstanceMode            = api.InstanceMode
+	CreateInstanceRequest   = api.CreateInstanceRequest
+	CreateInstanceResponse  = api.CreateInstanceResponse
+	InstanceModifyRequest   = api.InstanceModifyRequest
+	InstanceModifyResponse  = api.InstanceModifyResponse
+	AddSSHKeyResponse       = api.AddSSHKeyResponse
+	CreateSnapshotRequest   = api.CreateSnapshotRequest
+	CreateSnapshotResponse  = api.CreateSnapshotResponse
+	Snapshot                = api.Snapshot
+	TemplateEntry           = api.TemplateEntry
+	GpuSpecConfig           = api.GpuSpecConfig
+	GPUAvailabilityResponse = api.GPUAvailabilityResponse
+	ValidateTokenResult     = api.ValidateTokenResult
+	RetryPolicy             = api.RetryPolicy
+	TokenSource             = api.TokenSource
+)
+
+// Client is the Thunder Compute API. Every method may return an *APIError
+// for a response the server rejected; see IsNotFound and friends.
+type Client interface {
+	// ValidateToken reports who the client's token belongs to.
+	ValidateToken(ctx context.Context) (*ValidateTokenResult, error)
+
+	// ListInstances returns the account's instances sorted by ID.
+	ListInstances(ctx context.Context) ([]Instance, error)
+	// ListInstancesWithIPUpdate is ListInstances, but asks the server to
+	// refresh instance IPs first.
+	ListInstancesWithIPUpdate(ctx context.Context) ([]Instance, error)
+	CreateInstance(ctx context.Context, req CreateInstanceRequest) (*CreateInstanceResponse, error)
+	// ModifyInstance changes a running instance's hardware, mode or ports.
+	ModifyInstance(ctx context.Context, instanceID string, req InstanceModifyRequest) (*InstanceModifyResponse, error)
+	DeleteInstance(ctx context.Context, instanceID string) error
+	// StartInstance boots a stopped instance.
+	StartInstance(ctx context.Context, instanceID string) error
+	// StopInstance shuts a running instance down, keeping its disk.
+	StopInstance(ctx context.Context, instanceID string) error
+	// AddSSHKey authorizes a key for the instance, generating one if the
+	// account has none.
+	AddSSHKey(ctx context.Context, instanceID string) (*AddSSHKeyResponse, error)
+
+	ListSnapshots(ctx context.Context) ([]Snapshot, error)
+	CreateSnapshot(ctx context.Context, req CreateSnapshotRequest) (*CreateSnapshotResponse, error)
+	DeleteSnapshot(ctx context.Context, snapshotID string) error
+
+	ListTemplates(ctx context.Context) ([]TemplateEntry, error)
+	// GetSpecs returns the GPU configurations that can be created.
+	GetSpecs(ctx context.Context) (map[string]GpuSpecConfig, error)
+	// GetPricing returns hourly prices keyed by resource.
+	GetPricing(ctx context.Context) (map[string]float64, error)
+	GetAvailability(ctx context.Context) (*GPUAvailabilityResponse, error)
+}
@@ -0,0 +1,441 @@
+// Package thunderfake is an in-memory thunder.Client for tests.
+//
+// Operations take effect at once: a created instance is RUNNING, a stopped
+// one STOPPED, and a snapshot READY. Unknown IDs fail with a 404 and actions
+// on an instance in the wrong state with a 409, as with the real API, so
+// thunder.IsNotFound and thunder.IsConflict work on the errors.
+package thunderfake
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/pkg/thunder"
+	"github.com/Thunder-Compute/thunder-cli/pkg/types"
+)
+
+// Client is a fake Thunder Compute account. The zero value is not usable;
+// call New.
+type Client struct {
+	mu           sync.Mutex
+	instances    map[string]thunder.Instance
+	snapshots    []thunder.Snapshot
+	templates    []thunder.TemplateEntry
+	specs        map[string]thunder.GpuSpecConfig
+	pricing      map[string]float64
+	availability thunder.GPUAvailabilityResponse
+	errs         map[string]error
+	nextID       int
+	calls        []string
+	now          func() time.Time
+}
+
+var _ thunder.Client = (*Client)(nil)
+
+// New returns an empty account with a single "base" template.
+func New() *Client {
+	return &Client{
+		instances: make(map[string]thunder.Instance),
+		templates: []thunder.TemplateEntry{{
+			Key:      "base",
+			Template: types.EnvironmentTemplate{DisplayName: "Base"},
+		}},
+		specs:   make(map[string]thunder.GpuSpecConfig),
+		pricing: make(map[string]float64),
+		errs:    make(map[string]error),
+		now:     time.Now,
+	}
+}
+
+// AddInstance seeds the account with inst, assigning the next free ID if it
+// has none, and returns the ID.
+func (f *Client) AddInstance(inst thunder.Instance) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if inst.ID == "" {
+		inst.ID = f.newID()
+	}
+	if inst.Status == "" {
+		inst.Status = string(types.InstanceStatus_Running)
+	}
+	f.instances[inst.ID] = inst
+	return inst.ID
+}
+
+// SetInstanceStatus moves an instance to status, e.g. to test a command
+// waiting for it to finish STARTING.
+func (f *Client) SetInstanceStatus(instanceID, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return notFound("instance not found")
+	}
+	inst.Status = status
+	f.instances[instanceID] = inst
+	return nil
+}
+
+// AddSnapshot seeds the account with snap.
+func (f *Client) AddSnapshot(snap thunder.Snapshot) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.snapshots = append(f.snapshots, snap)
+}
+
+// SetTemplates replaces the templates returned by ListTemplates.
+func (f *Client) SetTemplates(templates []thunder.TemplateEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.templates = templates
+}
+
+// SetSpecs replaces the GPU configurations returned by GetSpecs.
+func (f *Client) SetSpecs(specs map[string]thunder.GpuSpecConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.specs = specs
+}
+
+// SetPricing replaces the prices returned by GetPricing.
+func (f *Client) SetPricing(pricing map[string]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pricing = pricing
+}
+
+// SetAvailability replaces the response of GetAvailability.
+func (f *Client) SetAvailability(availability thunder.GPUAvailabilityResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.availability = availability
+}
+
+// SetError makes every call of the named method, e.g. "CreateInstance",
+// fail with err until it is cleared with a nil err.
+func (f *Client) SetError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.errs, method)
+		return
+	}
+	f.errs[method] = err
+}
+
+// Calls returns the names of the methods called so far, in order.
+func (f *Client) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+// begin records a call and returns the error it should fail with, if any.
+// The caller must hold f.mu.
+func (f *Client) begin(ctx context.Context, method string) error {
+	f.calls = append(f.calls, method)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return f.errs[method]
+}
+
+func (f *Client) newID() string {
+	for {
+		id := strconv.Itoa(f.nextID)
+		f.nextID++
+		if _, taken := f.instances[id]; !taken {
+			return id
+		}
+	}
+}
+
+func (f *Client) ValidateToken(ctx context.Context) (*thunder.ValidateTokenResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ValidateToken"); err != nil {
+		return nil, err
+	}
+	return &thunder.ValidateTokenResult{Valid: true, Email: "fake@example.com"}, nil
+}
+
+func (f *Client) ListInstances(ctx context.Context) ([]thunder.Instance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ListInstances"); err != nil {
+		return nil, err
+	}
+	return f.sortedInstances(), nil
+}
+
+func (f *Client) ListInstancesWithIPUpdate(ctx context.Context) ([]thunder.Instance, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ListInstancesWithIPUpdate"); err != nil {
+		return nil, err
+	}
+	return f.sortedInstances(), nil
+}
+
+func (f *Client) sortedInstances() []thunder.Instance {
+	instances := make([]thunder.Instance, 0, len(f.instances))
+	for _, inst := range f.instances {
+		instances = append(instances, inst)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ID < instances[j].ID
+	})
+	return instances
+}
+
+func (f *Client) CreateInstance(ctx context.Context, req thunder.CreateInstanceRequest) (*thunder.CreateInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "CreateInstance"); err != nil {
+		return nil, err
+	}
+	id := f.newID()
+	ip := "127.0.0.1"
+	name := req.Name
+	if name == "" {
+		name = "instance-" + id
+	}
+	inst := thunder.Instance{
+		ID:              id,
+		IP:              &ip,
+		Name:            name,
+		Status:          string(types.InstanceStatus_Running),
+		CreatedAt:       f.now().UTC().Format(time.RFC3339),
+		UUID:            fmt.Sprintf("fake-%s", id),
+		Storage:         req.DiskSizeGB,
+		EphemeralDiskGB: req.EphemeralDiskGB,
+		CPUCores:        strconv.Itoa(req.CPUCores),
+		Template:        req.Template,
+		GPUType:         req.GPUType,
+		NumGPUs:         strconv.Itoa(req.NumGPUs),
+		Mode:            string(req.Mode),
+		Port:            22,
+	}
+	f.instances[id] = inst
+	n, _ := strconv.Atoi(id)
+	return &thunder.CreateInstanceResponse{UUID: inst.UUID, Identifier: n}, nil
+}
+
+func (f *Client) ModifyInstance(ctx context.Context, instanceID string, req thunder.InstanceModifyRequest) (*thunder.InstanceModifyResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ModifyInstance"); err != nil {
+		return nil, err
+	}
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return nil, notFound("instance not found")
+	}
+	if inst.Status != string(types.InstanceStatus_Running) {
+		return nil, conflict("instance cannot be modified (may not be in RUNNING state)")
+	}
+	if req.CPUCores != nil {
+		inst.CPUCores = strconv.Itoa(*req.CPUCores)
+	}
+	if req.GPUType != nil {
+		inst.GPUType = *req.GPUType
+	}
+	if req.NumGPUs != nil {
+		inst.NumGPUs = strconv.Itoa(*req.NumGPUs)
+	}
+	if req.DiskSizeGB != nil {
+		inst.Storage = *req.DiskSizeGB
+	}
+	if req.EphemeralDiskGB != nil {
+		inst.EphemeralDiskGB = *req.EphemeralDiskGB
+	}
+	if req.Mode != nil {
+		inst.Mode = string(*req.Mode)
+	}
+	inst.HTTPPorts = modifyPorts(inst.HTTPPorts, req.AddPorts, req.RemovePorts)
+	f.instances[instanceID] = inst
+
+	numGPUs, _ := strconv.Atoi(inst.NumGPUs)
+	return &thunder.InstanceModifyResponse{
+		Identifier:   instanceID,
+		InstanceName: inst.Name,
+		Mode:         &inst.Mode,
+		GPUType:      &inst.GPUType,
+		NumGPUs:      &numGPUs,
+		HTTPPorts:    inst.HTTPPorts,
+	}, nil
+}
+
+func modifyPorts(ports, add, remove []int) []int {
+	removed := make(map[int]bool, len(remove))
+	for _, p := range remove {
+		removed[p] = true
+	}
+	seen := make(map[int]bool)
+	var result []int
+	for _, p := range append(append([]int(nil), ports...), add...) {
+		if !removed[p] && !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+func (f *Client) DeleteInstance(ctx context.Context, instanceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "DeleteInstance"); err != nil {
+		return err
+	}
+	if _, ok := f.instances[instanceID]; !ok {
+		return notFound("instance not found")
+	}
+	delete(f.instances, instanceID)
+	return nil
+}
+
+func (f *Client) StartInstance(ctx context.Context, instanceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "StartInstance"); err != nil {
+		return err
+	}
+	return f.transition(instanceID, types.InstanceStatus_Stopped, types.InstanceStatus_Running, "instance cannot be started (must be STOPPED)")
+}
+
+func (f *Client) StopInstance(ctx context.Context, instanceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "StopInstance"); err != nil {
+		return err
+	}
+	return f.transition(instanceID, types.InstanceStatus_Running, types.InstanceStatus_Stopped, "instance cannot be stopped (must be RUNNING)")
+}
+
+func (f *Client) transition(instanceID string, from, to types.InstanceStatus, conflictMsg string) error {
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return notFound("instance not found")
+	}
+	if inst.Status != string(from) {
+		return conflict(conflictMsg)
+	}
+	inst.Status = string(to)
+	f.instances[instanceID] = inst
+	return nil
+}
+
+func (f *Client) AddSSHKey(ctx context.Context, instanceID string) (*thunder.AddSSHKeyResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "AddSSHKey"); err != nil {
+		return nil, err
+	}
+	inst, ok := f.instances[instanceID]
+	if !ok {
+		return nil, notFound("instance not found")
+	}
+	return &thunder.AddSSHKeyResponse{UUID: inst.UUID, Success: true}, nil
+}
+
+func (f *Client) ListSnapshots(ctx context.Context) ([]thunder.Snapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ListSnapshots"); err != nil {
+		return nil, err
+	}
+	return append([]thunder.Snapshot(nil), f.snapshots...), nil
+}
+
+func (f *Client) CreateSnapshot(ctx context.Context, req thunder.CreateSnapshotRequest) (*thunder.CreateSnapshotResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "CreateSnapshot"); err != nil {
+		return nil, err
+	}
+	inst, ok := f.instances[req.InstanceID]
+	if !ok {
+		return nil, notFound("instance not found")
+	}
+	for _, snap := range f.snapshots {
+		if snap.Name == req.Name {
+			return nil, conflict(fmt.Sprintf("a snapshot named %q already exists", req.Name))
+		}
+	}
+	f.snapshots = append(f.snapshots, thunder.Snapshot{
+		ID:                fmt.Sprintf("snap-%d", len(f.snapshots)+1),
+		Name:              req.Name,
+		MinimumDiskSizeGB: inst.Storage,
+		Status:            "READY",
+		CreatedAt:         f.now().Unix(),
+	})
+	return &thunder.CreateSnapshotResponse{Message: "Snapshot created"}, nil
+}
+
+func (f *Client) DeleteSnapshot(ctx context.Context, snapshotID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "DeleteSnapshot"); err != nil {
+		return err
+	}
+	for i, snap := range f.snapshots {
+		if snap.ID == snapshotID {
+			f.snapshots = append(f.snapshots[:i], f.snapshots[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("snapshot not found")
+}
+
+func (f *Client) ListTemplates(ctx context.Context) ([]thunder.TemplateEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "ListTemplates"); err != nil {
+		return nil, err
+	}
+	return append([]thunder.TemplateEntry(nil), f.templates...), nil
+}
+
+func (f *Client) GetSpecs(ctx context.Context) (map[string]thunder.GpuSpecConfig, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "GetSpecs"); err != nil {
+		return nil, err
+	}
+	return maps.Clone(f.specs), nil
+}
+
+func (f *Client) GetPricing(ctx context.Context) (map[string]float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "GetPricing"); err != nil {
+		return nil, err
+	}
+	return maps.Clone(f.pricing), nil
+}
+
+func (f *Client) GetAvailability(ctx context.Context) (*thunder.GPUAvailabilityResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.begin(ctx, "GetAvailability"); err != nil {
+		return nil, err
+	}
+	availability := f.availability
+	return &availability, nil
+}
+
+func notFound(message string) error {
+	return &thunder.APIError{StatusCode: http.StatusNotFound, Message: message}
+}
+
+func conflict(message string) error {
+	return &thunder.APIError{StatusCode: http.StatusConflict, Message: message}
+}
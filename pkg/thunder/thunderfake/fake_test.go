@@ -0,0 +1,74 @@
+package thunderfake
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Thunder-Compute/thunder-cli/pkg/thunder"
+)
+
+func TestInstanceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+
+	resp, err := fake.CreateInstance(ctx, thunder.CreateInstanceRequest{GPUType: "a100", NumGPUs: 1, CPUCores: 8, DiskSizeGB: 100})
+	require.NoError(t, err)
+	instances, err := fake.ListInstances(ctx)
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	id := instances[0].ID
+	assert.Equal(t, resp.UUID, instances[0].UUID)
+	assert.Equal(t, "RUNNING", instances[0].Status)
+
+	assert.True(t, thunder.IsConflict(fake.StartInstance(ctx, id)))
+	require.NoError(t, fake.StopInstance(ctx, id))
+	_, err = fake.ModifyInstance(ctx, id, thunder.InstanceModifyRequest{AddPorts: []int{8080}})
+	assert.True(t, thunder.IsConflict(err))
+	require.NoError(t, fake.StartInstance(ctx, id))
+
+	modified, err := fake.ModifyInstance(ctx, id, thunder.InstanceModifyRequest{AddPorts: []int{8080, 443}, RemovePorts: []int{443}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{8080}, modified.HTTPPorts)
+
+	_, err = fake.CreateSnapshot(ctx, thunder.CreateSnapshotRequest{InstanceID: id, Name: "snap"})
+	require.NoError(t, err)
+	_, err = fake.CreateSnapshot(ctx, thunder.CreateSnapshotRequest{InstanceID: id, Name: "snap"})
+	assert.True(t, thunder.IsConflict(err))
+	snapshots, err := fake.ListSnapshots(ctx)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "READY", snapshots[0].Status)
+	assert.Equal(t, 100, snapshots[0].MinimumDiskSizeGB)
+
+	require.NoError(t, fake.DeleteInstance(ctx, id))
+	assert.True(t, thunder.IsNotFound(fake.DeleteInstance(ctx, id)))
+	assert.True(t, thunder.IsNotFound(fake.DeleteSnapshot(ctx, "missing")))
+}
+
+func TestSeedingAndErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := New()
+	fake.AddInstance(thunder.Instance{ID: "0", Status: "STOPPED"})
+	id := fake.AddInstance(thunder.Instance{Name: "second"})
+	assert.Equal(t, "1", id, "seeded IDs are skipped")
+
+	boom := errors.New("boom")
+	fake.SetError("ListInstances", boom)
+	_, err := fake.ListInstances(ctx)
+	assert.ErrorIs(t, err, boom)
+	fake.SetError("ListInstances", nil)
+	instances, err := fake.ListInstances(ctx)
+	require.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = fake.GetSpecs(cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, []string{"ListInstances", "ListInstances", "GetSpecs"}, fake.Calls())
+}
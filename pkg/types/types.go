@@ -132,6 +132,7 @@ type InstanceCreateRequest struct {
 	NumGPUs         int          `json:"num_gpus"`
 	DiskSizeGB      int          `json:"disk_size_gb"`
 	EphemeralDiskGB int          `json:"ephemeral_disk_gb,omitempty"`
+	Name            string       `json:"name,omitempty"`
 }
 
 // InstanceCreateResponse represents the response from creating an instance.
@@ -25,6 +25,7 @@ type connectModel struct {
 	loading     bool
 	spinner     spinner.Model
 	client      *api.Client
+	ctx         context.Context
 	err         error
 	displayToID map[string]string
 	noInstances bool
@@ -63,6 +64,7 @@ func newConnectFetchModel(client *api.Client) connectModel {
 		loading:     true,
 		spinner:     s,
 		client:      client,
+		ctx:         context.Background(),
 		displayToID: make(map[string]string),
 		styles:      newConnectStyles(),
 	}
@@ -70,7 +72,7 @@ func newConnectFetchModel(client *api.Client) connectModel {
 
 func (m connectModel) Init() tea.Cmd {
 	if m.loading {
-		return tea.Batch(m.spinner.Tick, fetchConnectInstancesCmd(m.client))
+		return tea.Batch(m.spinner.Tick, fetchConnectInstancesCmd(m.ctx, m.client))
 	}
 	return nil
 }
@@ -80,9 +82,9 @@ type connectInstancesMsg struct {
 	err       error
 }
 
-func fetchConnectInstancesCmd(client *api.Client) tea.Cmd {
+func fetchConnectInstancesCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		instances, err := client.ListInstances()
+		instances, err := client.ListInstancesCtx(ctx)
 		return connectInstancesMsg{instances: instances, err: err}
 	}
 }
@@ -253,6 +255,7 @@ func RunConnectInteractiveSelect(client *api.Client) (string, error) {
 	InitCommonStyles(os.Stdout)
 
 	m := newConnectFetchModel(client)
+	m.ctx = ctx
 	p := tea.NewProgram(
 		m,
 		tea.WithContext(ctx),
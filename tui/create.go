@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"slices"
@@ -77,6 +78,7 @@ type createModel struct {
 	validationErr             error
 	quitting                  bool
 	client                    *api.Client
+	ctx                       context.Context
 	spinner                   spinner.Model
 	selectedSnapshot          *api.Snapshot
 	gpuCountPhase             bool // when true, stepCompute shows GPU count selection before vCPU selection
@@ -114,6 +116,7 @@ func NewCreateModel(client *api.Client, specs *utils.SpecStore) createModel {
 	m := createModel{
 		step:               stepMode,
 		client:             client,
+		ctx:                context.Background(),
 		spinner:            s,
 		styles:             styles,
 		skippedSteps:       make(map[createStep]bool),
@@ -426,34 +429,34 @@ func sortTemplates(templates []api.TemplateEntry) []api.TemplateEntry {
 	return rest
 }
 
-func fetchCreateTemplatesCmd(client *api.Client) tea.Cmd {
+func fetchCreateTemplatesCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		templates, err := client.ListTemplates()
+		templates, err := client.ListTemplatesCtx(ctx)
 		return createTemplatesMsg{templates: templates, err: err}
 	}
 }
 
-func fetchCreateSnapshotsCmd(client *api.Client) tea.Cmd {
+func fetchCreateSnapshotsCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		snapshots, err := client.ListSnapshots()
+		snapshots, err := client.ListSnapshotsCtx(ctx)
 		return createSnapshotsMsg{snapshots: []api.Snapshot(snapshots), err: err}
 	}
 }
 
-func fetchCreatePricingCmd(client *api.Client) tea.Cmd {
+func fetchCreatePricingCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		rates, err := client.FetchPricing()
+		rates, err := client.FetchPricingCtx(ctx)
 		return createPricingMsg{rates: rates, err: err}
 	}
 }
 
-func fetchCreateSpecsCmd(client *api.Client) tea.Cmd {
+func fetchCreateSpecsCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		specsMap, err := client.GetSpecs()
+		specsMap, err := client.GetSpecsCtx(ctx)
 		if err != nil {
 			return createSpecsMsg{err: err}
 		}
-		availability, availabilityErr := client.GetAvailability()
+		availability, availabilityErr := client.GetAvailabilityCtx(ctx)
 		var specAvailability map[string]string
 		if availabilityErr == nil && availability != nil {
 			specAvailability = availability.Specs
@@ -464,13 +467,13 @@ func fetchCreateSpecsCmd(client *api.Client) tea.Cmd {
 
 func (m createModel) Init() tea.Cmd {
 	cmds := []tea.Cmd{
-		fetchCreateTemplatesCmd(m.client),
-		fetchCreateSnapshotsCmd(m.client),
-		fetchCreatePricingCmd(m.client),
+		fetchCreateTemplatesCmd(m.ctx, m.client),
+		fetchCreateSnapshotsCmd(m.ctx, m.client),
+		fetchCreatePricingCmd(m.ctx, m.client),
 		m.spinner.Tick,
 	}
 	if !m.specsLoaded {
-		cmds = append(cmds, fetchCreateSpecsCmd(m.client))
+		cmds = append(cmds, fetchCreateSpecsCmd(m.ctx, m.client))
 	}
 	return tea.Batch(cmds...)
 }
@@ -1242,6 +1245,10 @@ func (m createModel) computePreviewPrice() float64 {
 }
 
 func runCreateModel(m createModel) (*CreateConfig, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.ctx = ctx
+
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -284,6 +285,7 @@ type deleteProgressModel struct {
 	successMsg string
 	err        error
 	client     *api.Client
+	ctx        context.Context
 	instanceID string
 }
 
@@ -291,9 +293,9 @@ type deleteResultMsg struct {
 	err error
 }
 
-func deleteInstanceCmd(client *api.Client, instanceID string) tea.Cmd {
+func deleteInstanceCmd(ctx context.Context, client *api.Client, instanceID string) tea.Cmd {
 	return func() tea.Msg {
-		_, err := client.DeleteInstance(instanceID)
+		_, err := client.DeleteInstanceCtx(ctx, instanceID)
 		return deleteResultMsg{err: err}
 	}
 }
@@ -304,12 +306,13 @@ func newDeleteProgressModel(client *api.Client, instanceID, message string) dele
 		spinner:    s,
 		message:    message,
 		client:     client,
+		ctx:        context.Background(),
 		instanceID: instanceID,
 	}
 }
 
 func (m deleteProgressModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, deleteInstanceCmd(m.client, m.instanceID))
+	return tea.Batch(m.spinner.Tick, deleteInstanceCmd(m.ctx, m.client, m.instanceID))
 }
 
 func (m deleteProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -350,7 +353,11 @@ func (m deleteProgressModel) View() string {
 func RunDeleteProgress(client *api.Client, instanceID string) (string, error) {
 	InitCommonStyles(os.Stdout)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	m := newDeleteProgressModel(client, instanceID, fmt.Sprintf("Deleting instance %s...", instanceID))
+	m.ctx = ctx
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -0,0 +1,98 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderApplyHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("APPLY COMMAND", "Converge your instances to a fleet spec")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr plan -f <fleet.yaml>     Show the changes and the hourly cost delta"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr apply -f <fleet.yaml>    Create, modify and delete instances to match"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Entries are validated like tnr create flags. Each entry runs count identical instances."))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("apply only touches instances it created, which it names <fleet>/<entry>/<n>."))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--file, -f"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Fleet spec file, YAML or JSON (- reads stdin)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--yes, -y"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Apply without confirmation (required in non-interactive mode)"))
+	output.WriteString("\n\n")
+
+	// Spec Section
+	output.WriteString(SectionStyle.Render("● SPEC"))
+	output.WriteString("\n\n")
+	for _, line := range []string{
+		"name: training            # optional, defaults to the file name",
+		"instances:",
+		"  - name: trainer",
+		"    count: 2              # default 1; 0 deletes the entry's instances",
+		"    mode: production",
+		"    gpu: h100",
+		"    num_gpus: 4",
+		"    primary_disk: 500",
+		"    template: pytorch     # or snapshot: <name>",
+		"    ports: [8888]         # omit to leave ports alone",
+		"  - name: proto",
+		"    mode: prototyping",
+		"    gpu: a6000",
+		"    vcpus: 8",
+		"    ephemeral_disk: 100",
+		"    template: base",
+	} {
+		output.WriteString("  ")
+		output.WriteString(CommandTextStyle.Render(line))
+		output.WriteString("\n")
+	}
+	output.WriteString("\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Preview the changes"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr plan -f fleet.yaml"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Apply from CI without a prompt"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr apply -f fleet.yaml --yes --json"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
@@ -63,6 +63,41 @@ func RenderConnectHelp(cmd *cobra.Command) {
 	output.WriteString(CommandTextStyle.Render("tnr connect 0 --tunnel 8080 --tunnel 3000"))
 	output.WriteString("\n\n")
 
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Reach a service on the instance's network from local port 5433"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 -L 5433:db.internal:5432"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Let the instance reach a license server on your LAN via its port 27000"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 -R 27000:license.lan:27000"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Browse any service on the instance through a SOCKS5 proxy"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 --socks 1080"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Survive network drops; the shell runs in tmux (or screen) and is reattached"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 --reconnect"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Create or reattach to a named tmux session that outlives the connection"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 --session train"))
+	output.WriteString("\n\n")
+
 	output.WriteString("  ")
 	output.WriteString(ExampleStyle.Render("# Connect with debug mode"))
 	output.WriteString("\n")
@@ -80,6 +115,36 @@ func RenderConnectHelp(cmd *cobra.Command) {
 	output.WriteString(DescStyle.Render("Port forwarding (can specify multiple times: -t 8080 -t 3000)"))
 	output.WriteString("\n")
 
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--local, -L"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Local forward [bind_port:]host:port, host resolved on the instance"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--remote, -R"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Reverse forward bind_port[:host:port], host resolved on this machine"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--socks"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Local SOCKS5 proxy port; every request is dialed from the instance"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--reconnect"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Reconnect with backoff if the link drops and reattach to a tmux/screen session"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--session"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Attach to the named tmux session, creating it if needed (see 'tnr sessions')"))
+	output.WriteString("\n")
+
 	output.WriteString("  ")
 	output.WriteString(FlagStyle.Render("--debug"))
 	output.WriteString("   ")
@@ -0,0 +1,74 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderCostHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("COST COMMAND", "Track spend across running instances")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr cost [--set-budget <usd>]"))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--set-budget"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Set the active profile's monthly budget in USD (0 removes it)"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Show the burn rate and month-to-date spend"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr cost"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Keep this month under $500"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr cost --set-budget 500"))
+	output.WriteString("\n\n")
+
+	// Important Notes Section
+	output.WriteString(SectionStyle.Render("● IMPORTANT NOTES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Month-to-date spend is an estimate: each running instance is assumed to have run in its"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("  current configuration since it was created or the month began (UTC)"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Stopped instances are not counted"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• With a budget set, tnr create and tnr modify ask before a change that would take projected"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("  spend over it, and refuse in non-interactive mode unless --yes is given"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
@@ -101,5 +101,23 @@ func RenderCreateHelp(cmd *cobra.Command) {
 	output.WriteString(DescStyle.Render("Ephemeral storage in GB, mounted at /ephemeral (default: 0)"))
 	output.WriteString("\n")
 
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--wait"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Wait until the instance is running (up to 20m)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--idle-timeout"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Act on the instance once it has been idle this long, e.g. 2h (min: 10m)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--idle-action"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("stop (default) or snapshot-and-delete; see 'tnr idle'"))
+	output.WriteString("\n")
+
 	fmt.Fprint(os.Stdout, output.String())
 }
@@ -0,0 +1,115 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderDevHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("DEV COMMAND", "Tools for developing against Thunder Compute")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr dev mock-api [flags]"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("The mock API keeps instances and snapshots in memory and forgets them when it stops."))
+	output.WriteString("\n\n")
+
+	// Commands Section
+	output.WriteString(SectionStyle.Render("● COMMANDS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("mock-api"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Run a local fake of the Thunder Compute API"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Start the mock API, then run tnr against it from another terminal"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr dev mock-api"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("export TNR_API_URL=http://127.0.0.1:8787 TNR_API_TOKEN=mock-token"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Let connect, exec and scp reach mock instances"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr dev mock-api --ssh"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Exercise retries with a slow, flaky API"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr dev mock-api --latency 500ms --fail-rate 0.2"))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--addr"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Address to serve the API on (default 127.0.0.1:8787)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--token"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Bearer token the API accepts (default mock-token)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--latency"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Delay added to every response, e.g. 200ms"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--fail-rate"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Fraction of requests, from 0 to 1, that fail with a 503"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--transition"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("How long instances stay PROVISIONING, STOPPING, etc. (default 5s)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--ssh"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Also serve SSH; commands run on this machine in a scratch home directory"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--ssh-addr"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Address to serve SSH on (default a free port on 127.0.0.1)"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
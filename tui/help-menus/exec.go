@@ -0,0 +1,95 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderExecHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("EXEC COMMAND", "Run a command on an instance")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr exec <instance_id> -- <command...>"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr exec <instance_id...> -- <command...>"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr exec --all-running -- <command...>"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Runs without a terminal, streams stdout/stderr separately and exits with the remote exit status."))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("With several instances, output is prefixed per host and a summary is printed; exits 1 if any host failed."))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# List GPUs on instance 0"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr exec 0 -- nvidia-smi -L"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Use shell syntax by quoting the command"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr exec 0 -- 'cd ~/project && make test'"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Capture stdout, stderr, exit code and duration as JSON"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr exec 0 --json -- python train.py --epochs 1"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Run on several instances at once"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr exec 0 1 2 -- nvidia-smi"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Run on every running instance whose name matches a glob"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr exec 'sweep-*' -- ./run.sh"))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--all-running"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Run the command on every running instance"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--parallel"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Maximum number of instances to run on concurrently (default: all)"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
@@ -0,0 +1,84 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderIdleHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("IDLE COMMAND", "Inspect idle auto-shutdown policies")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr idle                 List every idle policy"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr idle <instance_id>   Show an instance's policy and how long it has been idle"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Policies are set with --idle-timeout and --idle-action on tnr create and tnr modify."))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Stop instance 0 after two idle hours"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr modify 0 --idle-timeout 2h"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Snapshot and delete it instead, keeping only the snapshot"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr modify 0 --idle-timeout 2h --idle-action snapshot-and-delete"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Check how long until it acts"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr idle 0"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Remove the policy"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr modify 0 --idle-timeout 0"))
+	output.WriteString("\n\n")
+
+	// Important Notes Section
+	output.WriteString(SectionStyle.Render("● IMPORTANT NOTES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• An instance is idle while its GPUs are under 5% utilisation and nobody is logged in over SSH"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• A watcher on the instance checks every minute and calls the API itself, so tnr need not be running"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Policies set while an instance is not running are installed by the next tnr connect"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• The watcher uses the token from the last tnr connect; 'tnr idle <instance_id>' reports when it has expired"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Checking with 'tnr idle <instance_id>' opens an SSH session, which counts as activity"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
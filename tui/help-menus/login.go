@@ -30,6 +30,18 @@ func RenderLoginHelp(cmd *cobra.Command) {
 	output.WriteString(CommandStyle.Render("Token"))
 	output.WriteString("   ")
 	output.WriteString(DescStyle.Render("tnr login --token <your_token_id>"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Device"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("tnr login --device"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Profile"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("tnr login --profile <name>"))
 	output.WriteString("\n\n")
 
 	// Examples Section
@@ -49,6 +61,27 @@ func RenderLoginHelp(cmd *cobra.Command) {
 	output.WriteString(CommandTextStyle.Render("tnr login --token abc123xyz789"))
 	output.WriteString("\n\n")
 
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# On a remote machine or container without a browser"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr login --device"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Log in to a second organization as a named profile"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr login --profile team"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Keep the token in a passphrase-encrypted file"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr login --credential-store encrypted"))
+	output.WriteString("\n\n")
+
 	// Flags Section
 	output.WriteString(SectionStyle.Render("● FLAGS"))
 	output.WriteString("\n\n")
@@ -57,6 +90,43 @@ func RenderLoginHelp(cmd *cobra.Command) {
 	output.WriteString(FlagStyle.Render("--token"))
 	output.WriteString("   ")
 	output.WriteString(DescStyle.Render("Authenticate directly with a token instead of opening browser"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--device"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Show a code to enter in a browser on any device instead of opening one here"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--profile"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Save the credentials under this profile name instead of the active one"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--credential-store"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Where to keep the token: keychain, encrypted or plaintext"))
+	output.WriteString("\n\n")
+
+	// Important Notes Section
+	output.WriteString(SectionStyle.Render("● IMPORTANT NOTES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Tokens go to the OS keychain (macOS Keychain, or Secret Service via secret-tool on Linux)"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("  when one is available, and otherwise to ~/.thunder/cli_config.json in plaintext"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• encrypted keeps them in ~/.thunder/credentials.enc under a passphrase, read from"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("  TNR_CREDENTIAL_PASSPHRASE or asked for when needed"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• TNR_CREDENTIAL_STORE sets the store when --credential-store is not given"))
 	output.WriteString("\n\n")
 
 	fmt.Fprint(os.Stdout, output.String())
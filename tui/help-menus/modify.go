@@ -88,6 +88,24 @@ func RenderModifyHelp(cmd *cobra.Command) {
 	output.WriteString(DescStyle.Render("Ephemeral storage in GB, mounted at /ephemeral (0 to disable)"))
 	output.WriteString("\n")
 
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--wait"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Wait until the instance is running again (up to 20m)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--idle-timeout"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Act on the instance once it has been idle this long, e.g. 2h (0 removes the policy)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--idle-action"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("stop (default) or snapshot-and-delete; see 'tnr idle'"))
+	output.WriteString("\n")
+
 	// Important Notes Section
 	output.WriteString(SectionStyle.Render("● IMPORTANT NOTES"))
 	output.WriteString("\n\n")
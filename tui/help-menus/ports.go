@@ -203,5 +203,15 @@ func RenderPortsListHelp(cmd *cobra.Command) {
 	output.WriteString(DescStyle.Render("3          STOPPED    -               5000"))
 	output.WriteString("\n\n")
 
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Print each instance's forwarded ports as JSON"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr ports list -o template='{{.ID}} {{json .HTTPPorts}}'"))
+	output.WriteString("\n\n")
+
 	fmt.Fprint(os.Stdout, output.String())
 }
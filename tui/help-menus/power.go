@@ -0,0 +1,94 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderStartHelp(cmd *cobra.Command) {
+	renderPowerHelp("START COMMAND", "Boot a stopped instance", "start", "running", []string{
+		"• Only STOPPED instances can be started",
+		"• The instance may get a new IP address; tnr connect picks it up automatically",
+	})
+}
+
+func RenderStopHelp(cmd *cobra.Command) {
+	renderPowerHelp("STOP COMMAND", "Shut an instance down without losing its disk", "stop", "stopped", []string{
+		"• Only RUNNING instances can be stopped",
+		"• The primary disk is kept; ephemeral storage and running processes are not",
+		"• Run tnr start to boot the instance again",
+	})
+}
+
+func renderPowerHelp(title, subtitle, verb, state string, notes []string) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader(title, subtitle)
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Interactive"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("tnr " + verb))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Non-interactive"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("tnr " + verb + " <instance_id> --yes"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Select an instance interactively"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr " + verb))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# " + strings.ToUpper(verb[:1]) + verb[1:] + " instance 0 and block until it is " + state))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr " + verb + " 0 --yes --wait"))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--wait"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Wait until the instance is " + state + " (up to 20m)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--yes, -y"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Skip confirmation (required in non-interactive mode)"))
+	output.WriteString("\n\n")
+
+	// Important Notes Section
+	output.WriteString(SectionStyle.Render("● IMPORTANT"))
+	output.WriteString("\n\n")
+	for _, note := range notes {
+		output.WriteString("  ")
+		output.WriteString(DescStyle.Render(note))
+		output.WriteString("\n")
+	}
+	output.WriteString("\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
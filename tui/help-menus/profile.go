@@ -0,0 +1,82 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderProfileHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("PROFILE COMMAND", "Switch between Thunder Compute accounts")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("List"))
+	output.WriteString("     ")
+	output.WriteString(DescStyle.Render("tnr profile list"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Use"))
+	output.WriteString("      ")
+	output.WriteString(DescStyle.Render("tnr profile use <name>"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("Delete"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("tnr profile delete <name>"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Log in to a second organization"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr login --profile team"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Run one command against it"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr status --profile team"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Make it the default for later commands"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr profile use team"))
+	output.WriteString("\n\n")
+
+	// Important Notes Section
+	output.WriteString(SectionStyle.Render("● IMPORTANT NOTES"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• The profile is chosen by --profile, then TNR_PROFILE, then tnr profile use"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• Profiles other than default keep their SSH keys in ~/.thunder/keys/<profile> and their"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("  SSH host aliases are named tnr-<profile>-<instance_id>"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("• tnr logout only removes the active profile"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
@@ -75,9 +75,9 @@ func RenderRootHelp(cmd *cobra.Command) {
 		commands []string
 	}
 	sections := []section{
-		{"CORE", []string{"create", "status", "connect", "modify", "delete"}},
-		{"UTILS", []string{"scp", "ports", "snapshot"}},
-		{"SETTINGS", []string{"login", "logout", "update"}},
+		{"CORE", []string{"create", "status", "connect", "modify", "start", "stop", "delete", "wait", "apply", "plan"}},
+		{"UTILS", []string{"scp", "sync", "exec", "sessions", "ports", "tunnel", "snapshot", "idle", "cost", "dev"}},
+		{"SETTINGS", []string{"login", "logout", "profile", "update"}},
 	}
 
 	output.WriteString(SectionStyle.Render("● COMMANDS"))
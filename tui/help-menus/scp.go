@@ -21,10 +21,26 @@ func RenderSCPHelp(cmd *cobra.Command) {
 	output.WriteString(SectionStyle.Render("● USAGE"))
 	output.WriteString("\n\n")
 	output.WriteString("  ")
-	output.WriteString(DescStyle.Render("tnr scp <source> <destination>"))
+	output.WriteString(DescStyle.Render("tnr scp <source>... <destination> [flags]"))
 	output.WriteString("\n\n")
 	output.WriteString("  ")
 	output.WriteString(DescStyle.Render("Prefix a path with instance_id: to indicate it's remote."))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Directories are copied recursively; re-running an interrupted copy skips finished files and resumes the rest."))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Several files are copied at once; a file that fails does not stop the others."))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--jobs, -j"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Number of files to copy at once, 1-8 (default 4)"))
 	output.WriteString("\n\n")
 
 	// Examples Section
@@ -45,5 +61,19 @@ func RenderSCPHelp(cmd *cobra.Command) {
 	output.WriteString(CommandTextStyle.Render("tnr scp 0:/home/user/results.csv ./"))
 	output.WriteString("\n\n")
 
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Instance → instance, streamed through this machine"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr scp 0:/home/user/ckpt 1:/home/user/"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Upload several checkpoints, 8 files at a time"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr scp ./ckpt-1000 ./ckpt-2000 0:/home/user/ckpts/ -j 8"))
+	output.WriteString("\n\n")
+
 	fmt.Fprint(os.Stdout, output.String())
 }
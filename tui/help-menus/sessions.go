@@ -0,0 +1,72 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderSessionsHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("SESSIONS COMMAND", "Manage named tmux sessions on an instance")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr sessions <command>"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Sessions are created with 'tnr connect <instance_id> --session <name>' and keep running after you disconnect."))
+	output.WriteString("\n\n")
+
+	// Commands Section
+	output.WriteString(SectionStyle.Render("● COMMANDS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("list, ls <instance_id>"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("List tmux sessions on an instance"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("kill <instance_id> <session...>"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Kill sessions and every process running in them"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Start (or reattach to) a training session"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr connect 0 --session train"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# See which sessions are running on instance 0"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr sessions list 0"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Kill a finished session"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr sessions kill 0 train"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
@@ -63,6 +63,12 @@ func RenderSnapshotCreateHelp(cmd *cobra.Command) {
 	output.WriteString(FlagStyle.Render("--name"))
 	output.WriteString("   ")
 	output.WriteString(DescStyle.Render("Name for the snapshot (required)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--wait"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Wait until the snapshot is ready (up to 20m)"))
 	output.WriteString("\n\n")
 
 	// Important Notes Section
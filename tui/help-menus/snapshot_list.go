@@ -44,6 +44,13 @@ func RenderSnapshotListHelp(cmd *cobra.Command) {
 	output.WriteString(CommandTextStyle.Render("tnr snapshot list"))
 	output.WriteString("\n\n")
 
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Print the names of ready snapshots"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr snapshot list -o template='{{if eq .Status \"READY\"}}{{.Name}}{{end}}'"))
+	output.WriteString("\n\n")
+
 	// Output Section
 	output.WriteString(SectionStyle.Render("● OUTPUT"))
 	output.WriteString("\n\n")
@@ -64,6 +71,9 @@ func RenderSnapshotListHelp(cmd *cobra.Command) {
 	output.WriteString("\n\n")
 	output.WriteString("  ")
 	output.WriteString(DescStyle.Render("When monitoring, press 'Q' to stop watching."))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Use -o json, yaml, csv, wide, template=<tmpl> or jsonpath=<expr> for scripts."))
 	output.WriteString("\n\n")
 
 	fmt.Fprint(os.Stdout, output.String())
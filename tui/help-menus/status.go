@@ -49,6 +49,20 @@ func RenderStatusHelp(cmd *cobra.Command) {
 	output.WriteString(CommandTextStyle.Render("tnr status --no-wait"))
 	output.WriteString("\n\n")
 
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Print the ID and IP address of each instance"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr status -o template='{{.ID}} {{.GetIP}}'"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Export selected columns as CSV"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr status -o csv --columns id,status,gpu"))
+	output.WriteString("\n\n")
+
 	// Flags Section
 	output.WriteString(SectionStyle.Render("● FLAGS"))
 	output.WriteString("\n\n")
@@ -57,6 +71,18 @@ func RenderStatusHelp(cmd *cobra.Command) {
 	output.WriteString(FlagStyle.Render("--no-wait"))
 	output.WriteString("   ")
 	output.WriteString(DescStyle.Render("Display status once and exit without monitoring"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("-o, --output"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("json, yaml, csv, table, wide, template=<tmpl> or jsonpath=<expr>"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--columns"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Columns for table, wide and csv output; -o wide lists them all"))
 	output.WriteString("\n\n")
 
 	fmt.Fprint(os.Stdout, output.String())
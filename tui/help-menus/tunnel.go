@@ -0,0 +1,119 @@
+package helpmenus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func RenderTunnelHelp(cmd *cobra.Command) {
+	InitHelpStyles(os.Stdout)
+
+	var output strings.Builder
+
+	header := HelpHeader("TUNNEL COMMAND", "Manage background port-forward tunnels")
+
+	output.WriteString(HeaderStyle.Render(header))
+
+	// Usage Section
+	output.WriteString(SectionStyle.Render("● USAGE"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("tnr tunnel <command>"))
+	output.WriteString("\n\n")
+	output.WriteString("  ")
+	output.WriteString(DescStyle.Render("Tunnels run detached from the terminal and reconnect automatically if the connection drops."))
+	output.WriteString("\n\n")
+
+	// Commands Section
+	output.WriteString(SectionStyle.Render("● COMMANDS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("start <instance_id> -p <port>"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Forward ports from an instance in the background"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("stop <instance_id...>"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Stop tunnels (use --all to stop every tunnel)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(CommandStyle.Render("list, ls"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("List active tunnels"))
+	output.WriteString("\n\n")
+
+	// Examples Section
+	output.WriteString(SectionStyle.Render("● EXAMPLES"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Keep Jupyter and TensorBoard reachable after closing the terminal"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr tunnel start 0 -p 8888 -p 6006"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Give the instance access to a dataset cache on your LAN"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr tunnel start 0 -R 9000:cache.lan:9000"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Show active tunnels"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr tunnel list"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(ExampleStyle.Render("# Stop the tunnel to instance 0"))
+	output.WriteString("\n")
+	output.WriteString("  ")
+	output.WriteString(CommandTextStyle.Render("tnr tunnel stop 0"))
+	output.WriteString("\n\n")
+
+	// Flags Section
+	output.WriteString(SectionStyle.Render("● FLAGS"))
+	output.WriteString("\n\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("-p, --port"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Port to forward; repeat or use commas and ranges (start only)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("-L, --local"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Local forward [bind_port:]host:port, host resolved on the instance (start only)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("-R, --remote"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Reverse forward bind_port[:host:port], host resolved on this machine (start only)"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--socks"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Local SOCKS5 proxy port; every request is dialed from the instance"))
+	output.WriteString("\n")
+
+	output.WriteString("  ")
+	output.WriteString(FlagStyle.Render("--all"))
+	output.WriteString("   ")
+	output.WriteString(DescStyle.Render("Stop every active tunnel (stop only)"))
+	output.WriteString("\n\n")
+
+	fmt.Fprint(os.Stdout, output.String())
+}
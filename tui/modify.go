@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"slices"
@@ -65,6 +66,7 @@ type modifyModel struct {
 	config           ModifyConfig
 	currentInstance  *api.Instance
 	client           *api.Client
+	ctx              context.Context
 	diskInput               textinput.Model
 	diskInputTouched        bool
 	ephemeralDiskInput        textinput.Model
@@ -107,6 +109,7 @@ func NewModifyModel(client *api.Client, instance *api.Instance, specs *utils.Spe
 		config:                 ModifyConfig{},
 		currentInstance:        instance,
 		client:                 client,
+		ctx:                    context.Background(),
 		diskInput:              ti,
 		diskInputTouched:       false,
 		ephemeralDiskInput:       sti,
@@ -324,15 +327,15 @@ type modifyPricingMsg struct {
 	err   error
 }
 
-func fetchModifyPricingCmd(client *api.Client) tea.Cmd {
+func fetchModifyPricingCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		rates, err := client.FetchPricing()
+		rates, err := client.FetchPricingCtx(ctx)
 		return modifyPricingMsg{rates: rates, err: err}
 	}
 }
 
 func (m modifyModel) Init() tea.Cmd {
-	return fetchModifyPricingCmd(m.client)
+	return fetchModifyPricingCmd(m.ctx, m.client)
 }
 
 func (m modifyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -985,7 +988,11 @@ func (m modifyModel) renderConfirmationStep() string {
 	return s.String()
 }
 
-func runModifyModel(m tea.Model) (*ModifyConfig, error) {
+func runModifyModel(m modifyModel) (*ModifyConfig, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.ctx = ctx
+
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -1010,7 +1017,7 @@ func runModifyModel(m tea.Model) (*ModifyConfig, error) {
 
 // RunModifyInteractive starts the interactive modify flow
 func RunModifyInteractive(client *api.Client, instance *api.Instance, specs *utils.SpecStore) (*ModifyConfig, error) {
-	m := NewModifyModel(client, instance, specs)
+	m := NewModifyModel(client, instance, specs).(modifyModel)
 	return runModifyModel(m)
 }
 
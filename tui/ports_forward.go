@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -31,6 +32,7 @@ type portsForwardModel struct {
 	instances        []api.Instance
 	selectedInstance *api.Instance
 	client           *api.Client
+	ctx              context.Context
 	portInput        textinput.Model
 	currentPorts     []int
 	addPorts         []int
@@ -64,6 +66,7 @@ func NewPortsForwardModel(client *api.Client, instances []api.Instance) tea.Mode
 		cursor:    0,
 		instances: instances,
 		client:    client,
+		ctx:       context.Background(),
 		portInput: ti,
 		spinner:   s,
 		styles:    styles,
@@ -201,7 +204,7 @@ func (m portsForwardModel) handleEnter() (tea.Model, tea.Cmd) {
 			m.step = portsForwardStepApplying
 			return m, tea.Batch(
 				m.spinner.Tick,
-				portsForwardApiCmd(m.client, m.selectedInstance.ID, m.addPorts, m.removePorts),
+				portsForwardApiCmd(m.ctx, m.client, m.selectedInstance.ID, m.addPorts, m.removePorts),
 			)
 		}
 		// Cancel
@@ -246,13 +249,13 @@ type portsForwardApiResultMsg struct {
 	err  error
 }
 
-func portsForwardApiCmd(client *api.Client, instanceID string, addPorts, removePorts []int) tea.Cmd {
+func portsForwardApiCmd(ctx context.Context, client *api.Client, instanceID string, addPorts, removePorts []int) tea.Cmd {
 	return func() tea.Msg {
 		req := api.InstanceModifyRequest{
 			AddPorts:    addPorts,
 			RemovePorts: removePorts,
 		}
-		resp, err := client.ModifyInstance(instanceID, req)
+		resp, err := client.ModifyInstanceCtx(ctx, instanceID, req)
 		return portsForwardApiResultMsg{
 			resp: resp,
 			err:  err,
@@ -438,7 +441,11 @@ func (m portsForwardModel) renderCompleteStep() string {
 
 // RunPortsForwardInteractive starts the interactive port forwarding flow
 func RunPortsForwardInteractive(client *api.Client, instances []api.Instance) error {
-	m := NewPortsForwardModel(client, instances)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewPortsForwardModel(client, instances).(portsForwardModel)
+	m.ctx = ctx
 	p := tea.NewProgram(m)
 
 	finalModel, err := p.Run()
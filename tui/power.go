@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// PowerAction describes tnr start or tnr stop for the shared selector and
+// progress views.
+type PowerAction struct {
+	Verb       string // "start" or "stop"
+	Past       string // "Started" or "Stopped"
+	Title      string // selector title
+	FromStatus string // status the action applies to
+	Transition string // status while the action is under way
+	ToStatus   string // status once the action has finished
+	Progress   string // e.g. "Stopping instance %s..."
+	Done       func(instanceID string) string
+}
+
+// Do sends the start or stop request.
+func (a PowerAction) Do(ctx context.Context, client *api.Client, instanceID string) (*api.InstanceActionResponse, error) {
+	if a.Verb == StartAction.Verb {
+		return client.StartInstanceCtx(ctx, instanceID)
+	}
+	return client.StopInstanceCtx(ctx, instanceID)
+}
+
+var (
+	StartAction = PowerAction{
+		Verb:       "start",
+		Past:       "Started",
+		Title:      "▶ Start Thunder Compute Instance",
+		FromStatus: "STOPPED",
+		Transition: "STARTING",
+		ToStatus:   "RUNNING",
+		Progress:   "Starting instance %s...",
+		Done: func(id string) string {
+			return fmt.Sprintf("Instance %s is starting. Run 'tnr wait %s' to block until it is running", id, id)
+		},
+	}
+	StopAction = PowerAction{
+		Verb:       "stop",
+		Past:       "Stopped",
+		Title:      "■ Stop Thunder Compute Instance",
+		FromStatus: "RUNNING",
+		Transition: "STOPPING",
+		ToStatus:   "STOPPED",
+		Progress:   "Stopping instance %s...",
+		Done: func(id string) string {
+			return fmt.Sprintf("Instance %s is stopping. Its disk is kept; run 'tnr start %s' to boot it again", id, id)
+		},
+	}
+)
+
+type powerSelectorModel struct {
+	action    PowerAction
+	cursor    int
+	instances []api.Instance
+	selected  *api.Instance
+	cancelled bool
+	quitting  bool
+	styles    PanelStyles
+}
+
+// RunPowerSelector lets the user pick one of instances to start or stop.
+func RunPowerSelector(action PowerAction, instances []api.Instance) (*api.Instance, error) {
+	InitCommonStyles(os.Stdout)
+
+	m := powerSelectorModel{action: action, instances: instances, styles: NewPanelStyles()}
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, fmt.Errorf("error running instance selector: %w", err)
+	}
+	result, ok := finalModel.(powerSelectorModel)
+	if !ok {
+		return nil, fmt.Errorf("unexpected model type")
+	}
+	if result.cancelled || result.selected == nil {
+		return nil, ErrCancelled
+	}
+	return result.selected, nil
+}
+
+func (m powerSelectorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m powerSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "q", "Q", "esc":
+			m.cancelled = true
+			m.quitting = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.instances)-1 {
+				m.cursor++
+			}
+		case "enter":
+			m.selected = &m.instances[m.cursor]
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m powerSelectorModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString(m.styles.Title.Render(m.action.Title))
+	s.WriteString("\n")
+	s.WriteString(fmt.Sprintf("Select an instance to %s:\n\n", m.action.Verb))
+
+	for i, instance := range m.instances {
+		cursor := "  "
+		idAndName := fmt.Sprintf("(%s) %s", instance.ID, instance.Name)
+		if m.cursor == i {
+			cursor = m.styles.Cursor.Render("▶ ")
+			idAndName = m.styles.Selected.Render(idAndName)
+		}
+		s.WriteString(fmt.Sprintf("%s%s - %sx%s - %s\n", cursor, idAndName,
+			instance.NumGPUs, utils.FormatGPUType(instance.GPUType), utils.Capitalize(instance.Mode)))
+	}
+
+	s.WriteString("\n")
+	s.WriteString(m.styles.Help.Render("↑/↓: Navigate  Enter: Select  Esc/Q: Quit\n"))
+	return s.String()
+}
+
+type powerProgressModel struct {
+	spinner  spinner.Model
+	message  string
+	quitting bool
+	success  bool
+	err      error
+	run      func() error
+}
+
+type powerResultMsg struct {
+	err error
+}
+
+func (m powerProgressModel) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		return powerResultMsg{err: m.run()}
+	})
+}
+
+func (m powerProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case powerResultMsg:
+		m.err = msg.err
+		m.success = msg.err == nil
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyMsg:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.QuitMsg:
+		m.quitting = true
+		return m, nil
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+}
+
+func (m powerProgressModel) View() string {
+	if m.success || m.quitting {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", m.spinner.View(), m.message)
+}
+
+// RunPowerProgress starts or stops an instance behind a spinner, like
+// RunDeleteProgress, returning the message to print on success.
+func RunPowerProgress(client *api.Client, action PowerAction, instanceID string) (string, error) {
+	InitCommonStyles(os.Stdout)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := powerProgressModel{
+		spinner: NewPrimarySpinner(),
+		message: fmt.Sprintf(action.Progress, instanceID),
+		run: func() error {
+			_, err := action.Do(ctx, client, instanceID)
+			return err
+		},
+	}
+	finalModel, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return "", fmt.Errorf("error running %s: %w", action.Verb, err)
+	}
+	result, ok := finalModel.(powerProgressModel)
+	if !ok {
+		return "", fmt.Errorf("unexpected model type")
+	}
+	if result.err != nil {
+		return "", result.err
+	}
+	if result.success {
+		return action.Done(instanceID), nil
+	}
+	return "", nil
+}
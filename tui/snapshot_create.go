@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -43,6 +44,7 @@ type snapshotCreateModel struct {
 	validationErr    error
 	quitting         bool
 	client           *api.Client
+	ctx              context.Context
 	spinner          spinner.Model
 
 	styles     PanelStyles
@@ -67,6 +69,7 @@ func NewSnapshotCreateModel(client *api.Client) snapshotCreateModel {
 	return snapshotCreateModel{
 		step:       snapshotCreateStepSelectInstance,
 		client:     client,
+		ctx:        context.Background(),
 		nameInput:  ti,
 		spinner:    s,
 		styles:     styles,
@@ -79,15 +82,15 @@ type snapshotCreateInstancesMsg struct {
 	err       error
 }
 
-func fetchSnapshotCreateInstancesCmd(client *api.Client) tea.Cmd {
+func fetchSnapshotCreateInstancesCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		instances, err := client.ListInstances()
+		instances, err := client.ListInstancesCtx(ctx)
 		return snapshotCreateInstancesMsg{instances: instances, err: err}
 	}
 }
 
 func (m snapshotCreateModel) Init() tea.Cmd {
-	return tea.Batch(fetchSnapshotCreateInstancesCmd(m.client), m.spinner.Tick)
+	return tea.Batch(fetchSnapshotCreateInstancesCmd(m.ctx, m.client), m.spinner.Tick)
 }
 
 func (m snapshotCreateModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -366,7 +369,11 @@ func (m snapshotCreateModel) View() string {
 
 func RunSnapshotCreateInteractive(client *api.Client) (*SnapshotCreateConfig, error) {
 	InitCommonStyles(os.Stdout)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	m := NewSnapshotCreateModel(client)
+	m.ctx = ctx
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -265,6 +266,7 @@ type snapshotDeleteProgressModel struct {
 	successMsg   string
 	err          error
 	client       *api.Client
+	ctx          context.Context
 	snapshotID   string
 	snapshotName string
 }
@@ -273,9 +275,9 @@ type snapshotDeleteResultMsg struct {
 	err error
 }
 
-func deleteSnapshotCmd(client *api.Client, snapshotID string) tea.Cmd {
+func deleteSnapshotCmd(ctx context.Context, client *api.Client, snapshotID string) tea.Cmd {
 	return func() tea.Msg {
-		err := client.DeleteSnapshot(snapshotID)
+		err := client.DeleteSnapshotCtx(ctx, snapshotID)
 		return snapshotDeleteResultMsg{err: err}
 	}
 }
@@ -286,13 +288,14 @@ func newSnapshotDeleteProgressModel(client *api.Client, snapshotID, snapshotName
 		spinner:      s,
 		message:      message,
 		client:       client,
+		ctx:          context.Background(),
 		snapshotID:   snapshotID,
 		snapshotName: snapshotName,
 	}
 }
 
 func (m snapshotDeleteProgressModel) Init() tea.Cmd {
-	return tea.Batch(m.spinner.Tick, deleteSnapshotCmd(m.client, m.snapshotID))
+	return tea.Batch(m.spinner.Tick, deleteSnapshotCmd(m.ctx, m.client, m.snapshotID))
 }
 
 func (m snapshotDeleteProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -333,7 +336,11 @@ func (m snapshotDeleteProgressModel) View() string {
 func RunSnapshotDeleteProgress(client *api.Client, snapshotID, snapshotName string) (string, error) {
 	InitCommonStyles(os.Stdout)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	m := newSnapshotDeleteProgressModel(client, snapshotID, snapshotName, fmt.Sprintf("Deleting snapshot '%s'...", snapshotName))
+	m.ctx = ctx
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
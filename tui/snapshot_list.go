@@ -39,6 +39,7 @@ func newSnapshotListStyles() snapshotListStyles {
 type snapshotListModel struct {
 	snapshots  api.ListSnapshotsResponse
 	client     *api.Client
+	ctx        context.Context
 	monitoring bool
 	lastUpdate time.Time
 	quitting   bool
@@ -58,6 +59,7 @@ func newSnapshotListModel(client *api.Client, monitoring bool, snapshots api.Lis
 
 	return snapshotListModel{
 		client:     client,
+		ctx:        context.Background(),
 		monitoring: monitoring,
 		snapshots:  snapshots,
 		lastUpdate: time.Now(),
@@ -112,9 +114,9 @@ func snapshotsTickCmd(d time.Duration) tea.Cmd {
 	})
 }
 
-func fetchSnapshotsCmd(client *api.Client) tea.Cmd {
+func fetchSnapshotsCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		snapshots, err := client.ListSnapshots()
+		snapshots, err := client.ListSnapshotsCtx(ctx)
 		return snapshotsMsg{snapshots: snapshots, err: err}
 	}
 }
@@ -139,7 +141,7 @@ func (m snapshotListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tickMsg:
 		if m.monitoring {
-			return m, fetchSnapshotsCmd(m.client)
+			return m, fetchSnapshotsCmd(m.ctx, m.client)
 		}
 
 	case spinner.TickMsg:
@@ -310,6 +312,7 @@ func RunSnapshotList(client *api.Client, monitoring bool, snapshots api.ListSnap
 	InitCommonStyles(os.Stdout)
 
 	m := newSnapshotListModel(client, monitoring, snapshots)
+	m.ctx = ctx
 	p := tea.NewProgram(
 		m,
 		tea.WithContext(ctx),
@@ -49,6 +49,7 @@ func newStatusStyles() statusStyles {
 type statusModel struct {
 	instances    []api.Instance
 	client       *api.Client
+	ctx          context.Context
 	monitoring   bool
 	verbose      bool
 	lastUpdate   time.Time
@@ -78,6 +79,7 @@ func newStatusModel(client *api.Client, monitoring bool, instances []api.Instanc
 
 	m := statusModel{
 		client:       client,
+		ctx:          context.Background(),
 		monitoring:   monitoring,
 		verbose:      verbose,
 		instances:    instances,
@@ -103,14 +105,16 @@ func (m statusModel) Init() tea.Cmd {
 func hasTransitionalInstance(instances []api.Instance) bool {
 	for _, inst := range instances {
 		switch inst.Status {
-		case "PROVISIONING", "RESTORING", "STARTING", "STAGING", "PENDING", "QUEUED", "UNKNOWN":
+		case "PROVISIONING", "RESTORING", "STARTING", "STOPPING", "STAGING", "PENDING", "QUEUED", "UNKNOWN":
 			return true
 		}
 	}
 	return false
 }
 
-func nextInstancePollDelay(transitionAge time.Duration) time.Duration {
+// NextInstancePollDelay returns how long to wait before polling an instance
+// again, backing off the longer it has been in a transitional state.
+func NextInstancePollDelay(transitionAge time.Duration) time.Duration {
 	switch {
 	case transitionAge < 2*time.Minute:
 		return 1 * time.Second
@@ -128,16 +132,16 @@ func nextInstancePollDelay(transitionAge time.Duration) time.Duration {
 func tickCmd(transitionStartedAt time.Time) tea.Cmd {
 	interval := 60 * time.Second
 	if !transitionStartedAt.IsZero() {
-		interval = nextInstancePollDelay(time.Since(transitionStartedAt))
+		interval = NextInstancePollDelay(time.Since(transitionStartedAt))
 	}
 	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
-func fetchInstancesCmd(client *api.Client) tea.Cmd {
+func fetchInstancesCmd(ctx context.Context, client *api.Client) tea.Cmd {
 	return func() tea.Msg {
-		instances, err := client.ListInstances()
+		instances, err := client.ListInstancesCtx(ctx)
 		return instancesMsg{instances: instances, err: err}
 	}
 }
@@ -166,7 +170,7 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tickMsg:
 		if m.monitoring && len(m.instances) > 0 {
-			return m, tea.Batch(tickCmd(m.transitionStartedAt), fetchInstancesCmd(m.client))
+			return m, tea.Batch(tickCmd(m.transitionStartedAt), fetchInstancesCmd(m.ctx, m.client))
 		}
 
 	case spinner.TickMsg:
@@ -364,7 +368,7 @@ func (m statusModel) formatStatus(status string, width int) string {
 	switch status {
 	case "RUNNING":
 		style = m.styles.running
-	case "STARTING", "SNAPPING":
+	case "STARTING", "STOPPING", "SNAPPING":
 		style = m.styles.starting
 	case "RESTORING":
 		style = m.styles.restoring
@@ -579,6 +583,7 @@ func RunStatus(client *api.Client, monitoring bool, instances []api.Instance, ve
 	InitCommonStyles(os.Stdout)
 
 	m := newStatusModel(client, monitoring, instances, verbose)
+	m.ctx = ctx
 	p := tea.NewProgram(
 		m,
 		tea.WithContext(ctx),
@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Thunder-Compute/thunder-cli/utils"
+)
+
+// TransferProgressMsg carries a progress update from a running transfer.
+type TransferProgressMsg utils.TransferProgress
+
+// TransferDoneMsg dismisses the transfer progress view.
+type TransferDoneMsg struct{}
+
+// TransferProgressModel renders a progress bar with file count, bytes,
+// throughput and ETA for a file transfer, plus a line per file in flight.
+type TransferProgressModel struct {
+	title    string
+	bar      progress.Model
+	started  time.Time
+	current  utils.TransferProgress
+	Quitting bool
+
+	styles transferStyles
+}
+
+type transferStyles struct {
+	title  lipgloss.Style
+	detail lipgloss.Style
+	help   lipgloss.Style
+}
+
+func NewTransferProgressModel(title string) TransferProgressModel {
+	InitCommonStyles(os.Stdout)
+	return TransferProgressModel{
+		title: title,
+		bar: progress.New(
+			progress.WithSolidFill("#FFA500"),
+			progress.WithWidth(50),
+		),
+		started: time.Now(),
+		styles: transferStyles{
+			title:  LabelStyle().Bold(false),
+			detail: SubtleTextStyle(),
+			help:   HelpStyle(),
+		},
+	}
+}
+
+func (m TransferProgressModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TransferProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TransferProgressMsg:
+		m.current = utils.TransferProgress(msg)
+	case TransferDoneMsg:
+		m.Quitting = true
+		return m, tea.Quit
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "Q", "esc", "ctrl+c":
+			selfInterrupt()
+			m.Quitting = true
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m TransferProgressModel) View() string {
+	if m.Quitting {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(m.styles.title.Render(m.title) + "\n")
+	b.WriteString(m.bar.ViewAs(transferFraction(m.current)) + "\n")
+	b.WriteString(m.styles.detail.Render(FormatTransferProgress(m.current, time.Since(m.started))) + "\n")
+	// A single-file transfer names its file in the summary line.
+	if m.current.TotalFiles > 1 {
+		now := time.Now()
+		for _, f := range m.current.Active {
+			b.WriteString(m.styles.detail.Render("  "+FormatFileProgress(f, now.Sub(f.Started))) + "\n")
+		}
+	}
+	b.WriteString(m.styles.help.Render("Esc/Q: Cancel\n"))
+	return b.String()
+}
+
+func transferFraction(p utils.TransferProgress) float64 {
+	if p.TotalBytes <= 0 {
+		if p.TotalFiles > 0 && p.Files+p.Failed == p.TotalFiles {
+			return 1
+		}
+		return 0
+	}
+	return float64(p.Bytes) / float64(p.TotalBytes)
+}
+
+// FormatTransferProgress renders e.g.
+// "[3/10] 42%  1.2 GB / 2.9 GB  48.1 MB/s  ETA 35s", naming the file for
+// single-file transfers. The rate counts only data actually sent, so skipped
+// and resumed bytes do not inflate it.
+func FormatTransferProgress(p utils.TransferProgress, elapsed time.Duration) string {
+	var b strings.Builder
+	done := p.Files + p.Failed
+	if p.TotalFiles == 1 {
+		fmt.Fprintf(&b, "%s  ", p.Path)
+	} else {
+		fmt.Fprintf(&b, "[%d/%d] ", min(done+1, p.TotalFiles), p.TotalFiles)
+	}
+	fmt.Fprintf(&b, "%d%%  %s / %s", int(transferFraction(p)*100), FormatBytes(p.Bytes), FormatBytes(p.TotalBytes))
+	if rate := transferRate(p.Sent, elapsed); rate > 0 {
+		fmt.Fprintf(&b, "  %s/s", FormatBytes(int64(rate)))
+		if remaining := p.TotalBytes - p.Bytes; remaining > 0 {
+			eta := time.Duration(float64(remaining) / rate * float64(time.Second))
+			fmt.Fprintf(&b, "  ETA %s", eta.Round(time.Second))
+		}
+	}
+	if p.Failed > 0 {
+		fmt.Fprintf(&b, "  %d failed", p.Failed)
+	}
+	return b.String()
+}
+
+// FormatFileProgress renders one in-flight file of a multi-file transfer,
+// e.g. "data/a.bin  42%  12.1 MB/s".
+func FormatFileProgress(f utils.FileTransferProgress, elapsed time.Duration) string {
+	pct := 100
+	if f.TotalBytes > 0 {
+		pct = int(f.Bytes * 100 / f.TotalBytes)
+	}
+	s := fmt.Sprintf("%s  %d%%", f.Path, pct)
+	if rate := transferRate(f.Sent, elapsed); rate > 0 {
+		s += fmt.Sprintf("  %s/s", FormatBytes(int64(rate)))
+	}
+	return s
+}
+
+func transferRate(sent int64, elapsed time.Duration) float64 {
+	if secs := elapsed.Seconds(); secs > 0 {
+		return float64(sent) / secs
+	}
+	return 0
+}
+
+// FormatBytes renders a byte count with a decimal unit, e.g. "48.1 MB".
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
+
+// RunWithTransferProgress runs fn, rendering the progress it reports through
+// its callback until it returns. In non-interactive mode (no TTY) it prints
+// the title and runs fn without a progress view.
+func RunWithTransferProgress(title string, out io.Writer, fn func(report utils.TransferProgressFunc) error) error {
+	if !IsInteractive() {
+		fmt.Fprintf(os.Stderr, "%s\n", title)
+		return fn(nil)
+	}
+
+	p := tea.NewProgram(NewTransferProgressModel(title), tea.WithOutput(out))
+	done := make(chan struct{})
+	go func() { _, _ = p.Run(); close(done) }()
+
+	// Transfers report after every chunk; forward at most ~10 updates a
+	// second so the program's message queue does not back up.
+	var mu sync.Mutex
+	var lastSent time.Time
+	report := func(progress utils.TransferProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(lastSent) < 100*time.Millisecond && progress.Files+progress.Failed < progress.TotalFiles {
+			return
+		}
+		lastSent = time.Now()
+		p.Send(TransferProgressMsg(progress))
+	}
+
+	err := fn(report)
+	p.Send(TransferDoneMsg{})
+	<-done
+	return err
+}
@@ -0,0 +1,388 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Credential store names, as recorded per profile in cli_config.json and
+// accepted by TNR_CREDENTIAL_STORE and tnr login --credential-store.
+const (
+	CredentialStoreKeychain  = "keychain"
+	CredentialStoreEncrypted = "encrypted"
+	CredentialStorePlaintext = "plaintext"
+)
+
+// keychainService is the service name tnr's secrets are filed under in the
+// OS keychain.
+const keychainService = "thunder-cli"
+
+// ErrCredentialsNotFound is returned by a CredentialStore with nothing saved
+// for a profile.
+var ErrCredentialsNotFound = errors.New("no saved credentials")
+
+// Credentials are the secrets of one profile.
+type Credentials struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// CredentialStore keeps profiles' credentials somewhere other than
+// cli_config.json. The plaintext fallback has no store: the credentials
+// stay in the config file, as they always have.
+type CredentialStore interface {
+	Get(profile string) (*Credentials, error)
+	Set(profile string, creds *Credentials) error
+	Delete(profile string) error
+}
+
+// MemoryCredentialStore is a CredentialStore for tests.
+type MemoryCredentialStore struct {
+	mu    sync.Mutex
+	creds map[string]Credentials
+}
+
+func NewMemoryCredentialStore() *MemoryCredentialStore {
+	return &MemoryCredentialStore{creds: map[string]Credentials{}}
+}
+
+func (s *MemoryCredentialStore) Get(profile string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, ok := s.creds[profile]
+	if !ok {
+		return nil, ErrCredentialsNotFound
+	}
+	return &creds, nil
+}
+
+func (s *MemoryCredentialStore) Set(profile string, creds *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds[profile] = *creds
+	return nil
+}
+
+func (s *MemoryCredentialStore) Delete(profile string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.creds, profile)
+	return nil
+}
+
+// KeychainAvailable reports whether this machine has an OS keychain tnr can
+// use: the login keychain on macOS, or a Secret Service (GNOME Keyring,
+// KWallet) reachable through secret-tool on Linux.
+func KeychainAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return false
+		}
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// NewKeychainStore returns the OS keychain store. Each profile is one entry
+// under the thunder-cli service, holding its credentials as JSON.
+func NewKeychainStore() (CredentialStore, error) {
+	if !KeychainAvailable() {
+		return nil, fmt.Errorf("no OS keychain is available on this machine")
+	}
+	return &keychainStore{goos: runtime.GOOS, run: runKeychainTool}, nil
+}
+
+type keychainStore struct {
+	goos string
+	// run executes a keychain tool with stdin, returning its stdout and exit code.
+	run func(stdin string, name string, args ...string) (string, int, error)
+}
+
+func runKeychainTool(stdin string, name string, args ...string) (string, int, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode(), fmt.Errorf("%s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), 0, err
+}
+
+func (k *keychainStore) Get(profile string) (*Credentials, error) {
+	var out string
+	var code int
+	var err error
+	if k.goos == "darwin" {
+		// 44 is errSecItemNotFound.
+		out, code, err = k.run("", "security", "find-generic-password", "-s", keychainService, "-a", profile, "-w")
+		if code == 44 {
+			return nil, ErrCredentialsNotFound
+		}
+	} else {
+		out, code, err = k.run("", "secret-tool", "lookup", "service", keychainService, "account", profile)
+		if code == 1 && strings.TrimSpace(out) == "" {
+			return nil, ErrCredentialsNotFound
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from keychain: %w", err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &creds); err != nil {
+		return nil, fmt.Errorf("keychain entry for profile '%s' is corrupted: %w", profile, err)
+	}
+	return &creds, nil
+}
+
+func (k *keychainStore) Set(profile string, creds *Credentials) error {
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	if k.goos == "darwin" {
+		// security -i reads the command from stdin, keeping the secret out
+		// of the process list; -X takes it hex-encoded so it needs no quoting.
+		line := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -X %s\n",
+			keychainService, profile, keychainService, hex.EncodeToString(secret))
+		_, _, err = k.run(line, "security", "-i")
+	} else {
+		_, _, err = k.run(string(secret), "secret-tool", "store",
+			"--label=Thunder Compute ("+profile+")", "service", keychainService, "account", profile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to keychain: %w", err)
+	}
+	return nil
+}
+
+func (k *keychainStore) Delete(profile string) error {
+	var code int
+	var err error
+	if k.goos == "darwin" {
+		_, code, err = k.run("", "security", "delete-generic-password", "-s", keychainService, "-a", profile)
+		if code == 44 {
+			return nil
+		}
+	} else {
+		_, _, err = k.run("", "secret-tool", "clear", "service", keychainService, "account", profile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete from keychain: %w", err)
+	}
+	return nil
+}
+
+// scrypt parameters for the encrypted credentials file (the interactive
+// login parameters recommended by the scrypt paper).
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// encryptedCredentialsFile is the on-disk form of credentials.enc.
+type encryptedCredentialsFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptedFileStore keeps every profile's credentials in one file,
+// encrypted with AES-256-GCM under a key derived from a passphrase with
+// scrypt.
+type EncryptedFileStore struct {
+	path string
+	// passphrase asks for the passphrase; create is true when the file does
+	// not exist yet and a new one is being chosen.
+	passphrase func(create bool) (string, error)
+
+	mu     sync.Mutex
+	cached string
+}
+
+func NewEncryptedFileStore(path string, passphrase func(create bool) (string, error)) *EncryptedFileStore {
+	return &EncryptedFileStore{path: path, passphrase: passphrase}
+}
+
+// EncryptedCredentialsPath is where the encrypted store keeps its file.
+func EncryptedCredentialsPath() (string, error) {
+	dir, err := ThunderDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "credentials.enc"), nil
+}
+
+func (s *EncryptedFileStore) getPassphrase(create bool) (string, error) {
+	if s.cached != "" {
+		return s.cached, nil
+	}
+	pass, err := s.passphrase(create)
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	s.cached = pass
+	return pass, nil
+}
+
+// load decrypts the file, returning an empty map if there is none.
+func (s *EncryptedFileStore) load() (map[string]Credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Credentials{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedCredentialsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	if file.Version != 1 {
+		return nil, fmt.Errorf("%s has unsupported version %d", s.path, file.Version)
+	}
+
+	pass, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newCredentialsCipher(pass, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		s.cached = ""
+		return nil, fmt.Errorf("failed to decrypt %s: wrong passphrase or corrupted file", s.path)
+	}
+	creds := map[string]Credentials{}
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func (s *EncryptedFileStore) save(creds map[string]Credentials) error {
+	pass, err := s.getPassphrase(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	file := encryptedCredentialsFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newCredentialsCipher(pass, file.Salt)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Ciphertext = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func newCredentialsCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *EncryptedFileStore) Get(profile string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	creds, ok := all[profile]
+	if !ok {
+		return nil, ErrCredentialsNotFound
+	}
+	return &creds, nil
+}
+
+func (s *EncryptedFileStore) Set(profile string, creds *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	all[profile] = *creds
+	return s.save(all)
+}
+
+func (s *EncryptedFileStore) Delete(profile string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[profile]; !ok {
+		return nil
+	}
+	delete(all, profile)
+	if len(all) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return s.save(all)
+}
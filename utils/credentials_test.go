@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	asked := 0
+	store := NewEncryptedFileStore(path, func(create bool) (string, error) {
+		asked++
+		assert.True(t, create, "the first passphrase chooses a new one")
+		return "hunter2", nil
+	})
+
+	_, err := store.Get("default")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	require.NoError(t, store.Set("default", &Credentials{Token: "tok-a", RefreshToken: "ref-a"}))
+	require.NoError(t, store.Set("team", &Credentials{Token: "tok-b"}))
+	assert.Equal(t, 1, asked)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tok-a")
+
+	// A new process asks again and decrypts with the same passphrase.
+	reopened := NewEncryptedFileStore(path, func(create bool) (string, error) {
+		assert.False(t, create)
+		return "hunter2", nil
+	})
+	creds, err := reopened.Get("default")
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{Token: "tok-a", RefreshToken: "ref-a"}, *creds)
+
+	wrong := NewEncryptedFileStore(path, func(bool) (string, error) { return "nope", nil })
+	_, err = wrong.Get("default")
+	assert.ErrorContains(t, err, "wrong passphrase")
+
+	require.NoError(t, reopened.Delete("default"))
+	require.NoError(t, reopened.Delete("team"))
+	assert.NoFileExists(t, path)
+}
+
+func TestKeychainStoreDarwin(t *testing.T) {
+	var calls []string
+	entries := map[string]string{}
+	store := &keychainStore{goos: "darwin", run: func(stdin, name string, args ...string) (string, int, error) {
+		calls = append(calls, name+" "+strings.Join(args, " "))
+		switch args[0] {
+		case "-i":
+			fields := strings.Fields(stdin)
+			secret, err := hex.DecodeString(fields[len(fields)-1])
+			require.NoError(t, err)
+			entries[fields[5]] = string(secret)
+			return "", 0, nil
+		case "find-generic-password":
+			secret, ok := entries[args[4]]
+			if !ok {
+				return "", 44, assert.AnError
+			}
+			return secret + "\n", 0, nil
+		case "delete-generic-password":
+			delete(entries, args[4])
+			return "", 0, nil
+		}
+		return "", 1, assert.AnError
+	}}
+
+	_, err := store.Get("team")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+
+	require.NoError(t, store.Set("team", &Credentials{Token: "tok"}))
+	assert.NotContains(t, strings.Join(calls, "\n"), "tok", "the secret must not be passed as an argument")
+
+	creds, err := store.Get("team")
+	require.NoError(t, err)
+	assert.Equal(t, "tok", creds.Token)
+
+	require.NoError(t, store.Delete("team"))
+	_, err = store.Get("team")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+}
+
+func TestKeychainStoreSecretTool(t *testing.T) {
+	var stored string
+	store := &keychainStore{goos: "linux", run: func(stdin, name string, args ...string) (string, int, error) {
+		assert.Equal(t, "secret-tool", name)
+		switch args[0] {
+		case "store":
+			stored = stdin
+		case "lookup":
+			if stored == "" {
+				return "", 1, assert.AnError
+			}
+			return stored, 0, nil
+		case "clear":
+			stored = ""
+		}
+		return "", 0, nil
+	}}
+
+	_, err := store.Get("default")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+	require.NoError(t, store.Set("default", &Credentials{Token: "tok", RefreshToken: "ref"}))
+	creds, err := store.Get("default")
+	require.NoError(t, err)
+	assert.Equal(t, "ref", creds.RefreshToken)
+	require.NoError(t, store.Delete("default"))
+	assert.Empty(t, stored)
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FleetState caches which instances `tnr apply` created for each entry of a
+// fleet spec. apply finds its instances by name (see FleetInstanceName), so
+// it works from any machine; the cache covers instances whose name the API
+// did not keep and those created before apply named them. Anything neither
+// named nor recorded is never modified or deleted. It is persisted as JSON
+// under ThunderDir()/fleets, in a subdirectory per profile outside the
+// default one.
+type FleetState struct {
+	Fleet string `json:"fleet"`
+	// Entries maps a spec entry name to the UUIDs of its instances, oldest first.
+	Entries map[string][]string `json:"entries"`
+}
+
+var fleetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidFleetName reports whether name can be used as a fleet or entry name.
+func ValidFleetName(name string) bool {
+	return len(name) <= 64 && fleetNamePattern.MatchString(name)
+}
+
+// FleetInstanceName returns the name `tnr apply` gives the n-th instance of
+// a fleet entry, e.g. training/trainer/0. '/' cannot appear in fleet or
+// entry names, so a name belongs to exactly one fleet and entry.
+func FleetInstanceName(fleet, entry string, n int) string {
+	return fmt.Sprintf("%s/%s/%d", fleet, entry, n)
+}
+
+// ParseFleetInstanceName reports which entry of fleet an instance named name
+// belongs to, and its number within the entry.
+func ParseFleetInstanceName(fleet, name string) (entry string, n int, ok bool) {
+	rest, found := strings.CutPrefix(name, fleet+"/")
+	if !found {
+		return "", 0, false
+	}
+	entry, num, found := strings.Cut(rest, "/")
+	if !found || !ValidFleetName(entry) {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 0 || strconv.Itoa(n) != num {
+		return "", 0, false
+	}
+	return entry, n, true
+}
+
+func fleetStatePath(fleet string) (string, error) {
+	if !ValidFleetName(fleet) {
+		return "", fmt.Errorf("invalid fleet name %q", fleet)
+	}
+	dir, err := ThunderSubdir(profileSubdir("fleets"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fleet+".json"), nil
+}
+
+// ReadFleetState returns the recorded state of a fleet, which is empty if
+// the fleet has never been applied.
+func ReadFleetState(fleet string) (*FleetState, error) {
+	path, err := fleetStatePath(fleet)
+	if err != nil {
+		return nil, err
+	}
+	state := &FleetState{Fleet: fleet, Entries: make(map[string][]string)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read fleet state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse fleet state %s: %w", filepath.Base(path), err)
+	}
+	if state.Entries == nil {
+		state.Entries = make(map[string][]string)
+	}
+	return state, nil
+}
+
+// WriteFleetState atomically records a fleet's state.
+func WriteFleetState(state *FleetState) error {
+	path, err := fleetStatePath(state.Fleet)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fleet state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fleet state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write fleet state: %w", err)
+	}
+	return nil
+}
+
+// Add records a new instance for entry.
+func (s *FleetState) Add(entry, uuid string) {
+	s.Entries[entry] = append(s.Entries[entry], uuid)
+}
+
+// Remove forgets an instance, dropping its entry once it has none left.
+func (s *FleetState) Remove(entry, uuid string) {
+	var kept []string
+	for _, u := range s.Entries[entry] {
+		if u != uuid {
+			kept = append(kept, u)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.Entries, entry)
+		return
+	}
+	s.Entries[entry] = kept
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFleetStateRoundTrip(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	state, err := ReadFleetState("training")
+	require.NoError(t, err)
+	assert.Empty(t, state.Entries)
+
+	state.Add("trainer", "uuid-1")
+	state.Add("trainer", "uuid-2")
+	state.Add("proto", "uuid-3")
+	require.NoError(t, WriteFleetState(state))
+
+	got, err := ReadFleetState("training")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"uuid-1", "uuid-2"}, got.Entries["trainer"])
+
+	got.Remove("trainer", "uuid-1")
+	got.Remove("proto", "uuid-3")
+	assert.Equal(t, map[string][]string{"trainer": {"uuid-2"}}, got.Entries)
+}
+
+func TestValidFleetName(t *testing.T) {
+	assert.True(t, ValidFleetName("train-v2.1"))
+	assert.False(t, ValidFleetName(""))
+	assert.False(t, ValidFleetName("../etc"))
+	assert.False(t, ValidFleetName("-flag"))
+
+	_, err := ReadFleetState("../x")
+	assert.Error(t, err)
+}
+
+func TestFleetInstanceName(t *testing.T) {
+	name := FleetInstanceName("training", "trainer", 2)
+	assert.Equal(t, "training/trainer/2", name)
+	entry, n, ok := ParseFleetInstanceName("training", name)
+	assert.True(t, ok)
+	assert.Equal(t, "trainer", entry)
+	assert.Equal(t, 2, n)
+
+	for _, name := range []string{"instance-3", "training/trainer", "training/trainer/x", "training/trainer/01", "train/trainer/0", "training/a/b/0"} {
+		_, _, ok := ParseFleetInstanceName("training", name)
+		assert.False(t, ok, name)
+	}
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ForwardDirection distinguishes local (-L) from remote (-R) forwards.
+type ForwardDirection string
+
+const (
+	// ForwardLocal listens on this machine and connects out from the instance.
+	ForwardLocal ForwardDirection = "local"
+	// ForwardRemote listens on the instance and connects out from this machine.
+	ForwardRemote ForwardDirection = "remote"
+	// ForwardDynamic runs a SOCKS5 proxy on this machine; each request is
+	// dialed from the instance, so no target is fixed up front.
+	ForwardDynamic ForwardDirection = "dynamic"
+)
+
+// ForwardSpec is a single port forward, following OpenSSH's -L/-R/-D
+// semantics: BindPort is listened on (locally for -L and -D, on the
+// instance's loopback for -R) and each connection is relayed to
+// TargetHost:TargetPort as seen from the other side. Dynamic forwards have
+// no target; the SOCKS client names one per connection.
+type ForwardSpec struct {
+	Direction  ForwardDirection `json:"direction"`
+	BindPort   int              `json:"bind_port"`
+	TargetHost string           `json:"target_host,omitempty"`
+	TargetPort int              `json:"target_port,omitempty"`
+}
+
+// LocalForward returns the same-port forward used by `-t N`:
+// localhost:N on this machine to localhost:N on the instance.
+func LocalForward(port int) ForwardSpec {
+	return ForwardSpec{Direction: ForwardLocal, BindPort: port, TargetHost: "localhost", TargetPort: port}
+}
+
+// DynamicForward returns a SOCKS5 proxy listening on localhost:port.
+func DynamicForward(port int) ForwardSpec {
+	return ForwardSpec{Direction: ForwardDynamic, BindPort: port}
+}
+
+// bindsLocally reports whether the forward listens on this machine.
+func (f ForwardSpec) bindsLocally() bool {
+	return f.Direction != ForwardRemote
+}
+
+// ParseForwardSpec parses a forward in one of the forms
+//
+//	port                  same port on both sides, target localhost
+//	bindPort:targetPort   target localhost
+//	bindPort:host:port    arbitrary target; IPv6 hosts go in brackets
+func ParseForwardSpec(direction ForwardDirection, spec string) (ForwardSpec, error) {
+	raw := strings.TrimSpace(spec)
+	if raw == "" {
+		return ForwardSpec{}, fmt.Errorf("empty forward spec")
+	}
+
+	bindStr, rest, hasTarget := strings.Cut(raw, ":")
+	bindPort, err := parseForwardPort(bindStr)
+	if err != nil {
+		return ForwardSpec{}, err
+	}
+	if !hasTarget {
+		return ForwardSpec{Direction: direction, BindPort: bindPort, TargetHost: "localhost", TargetPort: bindPort}, nil
+	}
+
+	host := "localhost"
+	portStr := rest
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		host, portStr = rest[:i], rest[i+1:]
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		if host == "" {
+			return ForwardSpec{}, fmt.Errorf("invalid forward %q: empty target host", raw)
+		}
+		if strings.ContainsAny(host, " \t/") {
+			return ForwardSpec{}, fmt.Errorf("invalid forward %q: bad target host %q", raw, host)
+		}
+	}
+	targetPort, err := parseForwardPort(portStr)
+	if err != nil {
+		return ForwardSpec{}, err
+	}
+
+	return ForwardSpec{Direction: direction, BindPort: bindPort, TargetHost: host, TargetPort: targetPort}, nil
+}
+
+func parseForwardPort(s string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid port: %s", s)
+	}
+	if err := validatePortBounds(port); err != nil {
+		return 0, err
+	}
+	return port, nil
+}
+
+// TargetAddr returns the host:port dialed for each forwarded connection.
+func (f ForwardSpec) TargetAddr() string {
+	return net.JoinHostPort(f.TargetHost, strconv.Itoa(f.TargetPort))
+}
+
+// String returns the spec in the form accepted by ParseForwardSpec, or just
+// the port for dynamic forwards.
+func (f ForwardSpec) String() string {
+	if f.Direction == ForwardDynamic {
+		return strconv.Itoa(f.BindPort)
+	}
+	host := f.TargetHost
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return fmt.Sprintf("%d:%s:%d", f.BindPort, host, f.TargetPort)
+}
+
+// Describe returns a human-readable summary such as
+// "localhost:8080 → instance localhost:80".
+func (f ForwardSpec) Describe() string {
+	switch f.Direction {
+	case ForwardRemote:
+		return fmt.Sprintf("instance localhost:%d → %s", f.BindPort, f.TargetAddr())
+	case ForwardDynamic:
+		return fmt.Sprintf("SOCKS5 proxy on localhost:%d → instance", f.BindPort)
+	}
+	return fmt.Sprintf("localhost:%d → instance %s", f.BindPort, f.TargetAddr())
+}
+
+// sshConfigLine returns the ssh_config directive for the forward.
+func (f ForwardSpec) sshConfigLine() string {
+	switch f.Direction {
+	case ForwardRemote:
+		return fmt.Sprintf("RemoteForward %d %s", f.BindPort, f.TargetAddr())
+	case ForwardDynamic:
+		return fmt.Sprintf("DynamicForward %d", f.BindPort)
+	}
+	return fmt.Sprintf("LocalForward %d %s", f.BindPort, f.TargetAddr())
+}
+
+// MergeForwards combines forward lists, dropping exact duplicates, and
+// rejects two forwards that would bind the same port on the same side.
+func MergeForwards(lists ...[]ForwardSpec) ([]ForwardSpec, error) {
+	type bindKey struct {
+		local bool
+		port  int
+	}
+	seen := make(map[bindKey]ForwardSpec)
+	var merged []ForwardSpec
+	for _, list := range lists {
+		for _, f := range list {
+			key := bindKey{f.bindsLocally(), f.BindPort}
+			if prev, ok := seen[key]; ok {
+				if prev == f {
+					continue
+				}
+				side := "locally"
+				if !key.local {
+					side = "on the instance"
+				}
+				return nil, fmt.Errorf("port %d is forwarded twice %s (%s and %s)", f.BindPort, side, prev, f)
+			}
+			seen[key] = f
+			merged = append(merged, f)
+		}
+	}
+	return merged, nil
+}
+
+// WithTemplatePorts appends a same-port local forward for each template port
+// that is not already bound locally by one of forwards.
+func WithTemplatePorts(forwards []ForwardSpec, templatePorts []int) []ForwardSpec {
+	result := append([]ForwardSpec(nil), forwards...)
+	bound := make(map[int]bool)
+	for _, f := range forwards {
+		if f.bindsLocally() {
+			bound[f.BindPort] = true
+		}
+	}
+	sorted := append([]int(nil), templatePorts...)
+	sort.Ints(sorted)
+	for _, p := range sorted {
+		if !bound[p] {
+			bound[p] = true
+			result = append(result, LocalForward(p))
+		}
+	}
+	return result
+}
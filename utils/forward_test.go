@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForwardSpec(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction ForwardDirection
+		input     string
+		want      ForwardSpec
+		wantErr   string
+	}{
+		{name: "single port", direction: ForwardLocal, input: "8888", want: ForwardSpec{ForwardLocal, 8888, "localhost", 8888}},
+		{name: "different ports", direction: ForwardLocal, input: "8080:80", want: ForwardSpec{ForwardLocal, 8080, "localhost", 80}},
+		{name: "target host", direction: ForwardLocal, input: "5433:db.internal:5432", want: ForwardSpec{ForwardLocal, 5433, "db.internal", 5432}},
+		{name: "remote", direction: ForwardRemote, input: "27000:license.lan:27000", want: ForwardSpec{ForwardRemote, 27000, "license.lan", 27000}},
+		{name: "ipv6 target", direction: ForwardLocal, input: "8080:[::1]:80", want: ForwardSpec{ForwardLocal, 8080, "::1", 80}},
+		{name: "whitespace", direction: ForwardLocal, input: " 9000 ", want: ForwardSpec{ForwardLocal, 9000, "localhost", 9000}},
+		{name: "empty", direction: ForwardLocal, input: "", wantErr: "empty forward spec"},
+		{name: "bad bind port", direction: ForwardLocal, input: "abc:80", wantErr: "invalid port: abc"},
+		{name: "bad target port", direction: ForwardLocal, input: "8080:host:x", wantErr: "invalid port: x"},
+		{name: "out of range", direction: ForwardLocal, input: "70000", wantErr: "out of range"},
+		{name: "empty host", direction: ForwardLocal, input: "8080::80", wantErr: "empty target host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseForwardSpec(tt.direction, tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			// String output must parse back to the same spec.
+			again, err := ParseForwardSpec(tt.direction, got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+}
+
+func TestForwardSpecFormatting(t *testing.T) {
+	local := ForwardSpec{ForwardLocal, 5433, "db", 5432}
+	remote := ForwardSpec{ForwardRemote, 9000, "cache.lan", 80}
+
+	assert.Equal(t, "localhost:5433 → instance db:5432", local.Describe())
+	assert.Equal(t, "instance localhost:9000 → cache.lan:80", remote.Describe())
+	assert.Equal(t, "LocalForward 5433 db:5432", local.sshConfigLine())
+	assert.Equal(t, "RemoteForward 9000 cache.lan:80", remote.sshConfigLine())
+	assert.Equal(t, "[::1]:80", ForwardSpec{ForwardLocal, 1, "::1", 80}.TargetAddr())
+}
+
+func TestMergeForwards(t *testing.T) {
+	merged, err := MergeForwards(
+		[]ForwardSpec{LocalForward(8080), LocalForward(3000)},
+		[]ForwardSpec{LocalForward(8080), {ForwardRemote, 8080, "localhost", 8080}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []ForwardSpec{LocalForward(8080), LocalForward(3000), {ForwardRemote, 8080, "localhost", 8080}}, merged)
+
+	_, err = MergeForwards([]ForwardSpec{LocalForward(8080), {ForwardLocal, 8080, "localhost", 80}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port 8080 is forwarded twice locally")
+}
+
+func TestWithTemplatePorts(t *testing.T) {
+	forwards := []ForwardSpec{
+		{ForwardLocal, 8888, "localhost", 9999},
+		{ForwardRemote, 6006, "localhost", 6006},
+	}
+	got := WithTemplatePorts(forwards, []int{8888, 6006, 443})
+	assert.Equal(t, []ForwardSpec{
+		forwards[0],
+		forwards[1],
+		LocalForward(443),
+		LocalForward(6006),
+	}, got)
+}
+
+func TestUpdateSSHConfigForwards(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	forwards := []ForwardSpec{
+		{ForwardLocal, 5433, "db.internal", 5432},
+		{ForwardRemote, 27000, "license.lan", 27000},
+	}
+	require.NoError(t, UpdateSSHConfig("3", "10.0.0.3", 22, "uuid-3", forwards, []int{8888}))
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, ".ssh", "config"))
+	require.NoError(t, err)
+	config := string(data)
+	assert.Contains(t, config, "Host tnr-3\n")
+	assert.Contains(t, config, "    LocalForward 5433 db.internal:5432\n    RemoteForward 27000 license.lan:27000\n    LocalForward 8888 localhost:8888\n")
+}
+
+func TestSessionForwards(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	clientPrivateKey, _, clientPublicKey := generateRSAKeyPair(t)
+	keyFile := filepath.Join(tmpDir, "forward_key")
+	savePrivateKeyToFile(t, clientPrivateKey, keyFile)
+
+	server, serverCleanup := setupSSHTestServer(t, clientPublicKey)
+	defer serverCleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := RobustSSHConnectCtx(ctx, "127.0.0.1", keyFile, server.port, 5)
+	require.NoError(t, err)
+	defer client.Close()
+
+	echoAddr := startEchoServer(t)
+	echoFwd, err := ParseForwardSpec(ForwardLocal, "1:"+echoAddr)
+	require.NoError(t, err)
+
+	t.Run("local forward to another port", func(t *testing.T) {
+		fwd := echoFwd
+		fwd.BindPort = freePort(t)
+		require.NoError(t, startForward(ctx, client.GetClient(), fwd))
+
+		got, err := echoThroughTunnel(fwd.BindPort, "local")
+		require.NoError(t, err)
+		assert.Equal(t, "local\n", got)
+	})
+
+	t.Run("remote forward", func(t *testing.T) {
+		fwd := echoFwd
+		fwd.Direction = ForwardRemote
+		fwd.BindPort = freePort(t)
+		require.NoError(t, startForward(ctx, client.GetClient(), fwd))
+
+		// The test server listens for the instance side on this host.
+		got, err := echoThroughTunnel(fwd.BindPort, "remote")
+		require.NoError(t, err)
+		assert.Equal(t, "remote\n", got)
+	})
+}
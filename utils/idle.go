@@ -0,0 +1,488 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdleAction is what the idle watcher does once an instance has been idle
+// for its policy's timeout.
+type IdleAction string
+
+const (
+	IdleActionStop              IdleAction = "stop"
+	IdleActionSnapshotAndDelete IdleAction = "snapshot-and-delete"
+)
+
+const (
+	// MinIdleTimeout keeps a policy from firing between two commands.
+	MinIdleTimeout = 10 * time.Minute
+	// IdleGPUThreshold is the utilisation, in percent, at which a GPU
+	// counts as busy.
+	IdleGPUThreshold = 5
+	// idleWatcherGap is how long the watcher may go without running before
+	// its record of the last activity is considered stale: the instance was
+	// stopped, not idle.
+	idleWatcherGap = 5 * time.Minute
+	// idleSnapshotTimeout is how long snapshot-and-delete waits for its
+	// snapshot to become READY before giving up and keeping the instance.
+	idleSnapshotTimeout = 6 * time.Hour
+
+	idleDir      = thunderConfigDir + "/idle"
+	idleCronPath = "/etc/cron.d/tnr-idle"
+)
+
+// ParseIdleAction validates an --idle-action value.
+func ParseIdleAction(s string) (IdleAction, error) {
+	switch a := IdleAction(strings.ToLower(s)); a {
+	case IdleActionStop, IdleActionSnapshotAndDelete:
+		return a, nil
+	}
+	return "", fmt.Errorf("idle action must be %q or %q", IdleActionStop, IdleActionSnapshotAndDelete)
+}
+
+// IdlePolicy is an instance's idle auto-shutdown policy. The API has no
+// notion of one, so it is kept locally under ThunderDir()/idle and pushed to
+// the instance as a small watcher whenever tnr connects to it.
+type IdlePolicy struct {
+	InstanceID     string     `json:"instance_id"`
+	InstanceUUID   string     `json:"instance_uuid"`
+	TimeoutSeconds int64      `json:"timeout_seconds"`
+	Action         IdleAction `json:"action"`
+	// InstalledAt is when the watcher was last installed on the instance,
+	// zero until tnr has been able to reach it.
+	InstalledAt time.Time `json:"installed_at,omitempty"`
+}
+
+// Timeout returns how long the instance may be idle before the policy acts.
+func (p *IdlePolicy) Timeout() time.Duration {
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+var idleIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+func idlePolicyPath(uuid string) (string, error) {
+	if !idleIDPattern.MatchString(uuid) {
+		return "", fmt.Errorf("invalid instance UUID %q", uuid)
+	}
+	dir, err := ThunderSubdir("idle")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, uuid+".json"), nil
+}
+
+// ReadIdlePolicy returns the policy for an instance, or nil if it has none.
+func ReadIdlePolicy(uuid string) (*IdlePolicy, error) {
+	path, err := idlePolicyPath(uuid)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read idle policy: %w", err)
+	}
+	var policy IdlePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse idle policy %s: %w", filepath.Base(path), err)
+	}
+	return &policy, nil
+}
+
+// ListIdlePolicies returns every recorded policy.
+func ListIdlePolicies() ([]*IdlePolicy, error) {
+	dir, err := ThunderSubdir("idle")
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var policies []*IdlePolicy
+	for _, match := range matches {
+		policy, err := ReadIdlePolicy(strings.TrimSuffix(filepath.Base(match), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// WriteIdlePolicy atomically records a policy.
+func WriteIdlePolicy(policy *IdlePolicy) error {
+	path, err := idlePolicyPath(policy.InstanceUUID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode idle policy: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write idle policy: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write idle policy: %w", err)
+	}
+	return nil
+}
+
+// RemoveIdlePolicy forgets an instance's policy.
+func RemoveIdlePolicy(uuid string) error {
+	path, err := idlePolicyPath(uuid)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove idle policy: %w", err)
+	}
+	return nil
+}
+
+// IdleSample is one observation of an instance's activity.
+type IdleSample struct {
+	GPUUtilization int // busiest GPU, in percent
+	SSHSessions    int
+}
+
+// Busy reports whether the sample shows the instance in use.
+func (s IdleSample) Busy() bool {
+	return s.GPUUtilization >= IdleGPUThreshold || s.SSHSessions > 0
+}
+
+// IdleTracker decides when an instance has been idle for long enough. It is
+// the reference for the watcher script InstallIdleWatcher puts on the
+// instance, which applies the same rules once a minute from cron.
+type IdleTracker struct {
+	Timeout    time.Duration
+	Now        func() time.Time
+	LastActive time.Time
+	LastCheck  time.Time
+}
+
+// NewIdleTracker returns a tracker that counts idle time from now.
+func NewIdleTracker(timeout time.Duration, now func() time.Time) *IdleTracker {
+	t := now()
+	return &IdleTracker{Timeout: timeout, Now: now, LastActive: t, LastCheck: t}
+}
+
+// Observe records a sample and reports whether the timeout has passed.
+// A long gap since the previous sample means the instance was off, so idle
+// time restarts rather than firing as soon as it boots.
+func (t *IdleTracker) Observe(sample IdleSample) bool {
+	now := t.Now()
+	if sample.Busy() || now.Sub(t.LastCheck) > idleWatcherGap {
+		t.LastActive = now
+	}
+	t.LastCheck = now
+	return t.IdleFor() >= t.Timeout
+}
+
+// IdleFor returns how long the instance has been idle.
+func (t *IdleTracker) IdleFor() time.Duration {
+	return t.Now().Sub(t.LastActive)
+}
+
+// Remaining returns how long until the policy acts, or 0 if it is due.
+func (t *IdleTracker) Remaining() time.Duration {
+	return max(t.Timeout-t.IdleFor(), 0)
+}
+
+// ParseGPUUtilization returns the busiest GPU's utilisation from
+// `nvidia-smi --query-gpu=utilization.gpu --format=csv,noheader,nounits`.
+func ParseGPUUtilization(output string) (int, error) {
+	busiest, found := 0, false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "%"))
+		if line == "" {
+			continue
+		}
+		util, err := strconv.Atoi(line)
+		if err != nil {
+			// "[N/A]" on GPUs that don't report utilisation.
+			continue
+		}
+		busiest, found = max(busiest, util), true
+	}
+	if !found {
+		return 0, fmt.Errorf("nvidia-smi reported no GPU utilisation")
+	}
+	return busiest, nil
+}
+
+// CountSSHSessions counts logged-in SSH connections in `ps -eo args=`
+// output. Each has an sshd process titled "sshd: user@pts/N" or
+// "sshd: user@notty"; the privileged "[priv]" halves are not counted.
+func CountSSHSessions(psOutput string) int {
+	n := 0
+	for _, line := range strings.Split(psOutput, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "sshd: ") {
+			continue
+		}
+		if user, _, _ := strings.Cut(strings.TrimPrefix(line, "sshd: "), " "); strings.Contains(user, "@") {
+			n++
+		}
+	}
+	return n
+}
+
+// idleWatcherScript runs from cron on the instance. It follows IdleTracker:
+// busy GPUs or SSH sessions reset the idle clock, a gap in checks means the
+// instance was off, and once idle for IDLE_TIMEOUT it acts once, then waits
+// for activity before it can act again.
+//
+// snapshot-and-delete takes one step per run: create the snapshot, check on
+// it until it is READY, then delete the instance. Progress is kept in acted,
+// so a failed call is retried a minute later, and a snapshot that is not
+// READY within idleSnapshotTimeout is given up on. A failed API call is
+// recorded in api_error for tnr idle to report; the watcher uses the token
+// left by the last tnr connect, which may have expired.
+var idleWatcherScript = `#!/bin/sh
+# Installed by tnr; see 'tnr idle'. Run by cron every minute.
+dir=` + idleDir + `
+. "$dir/policy" || exit 1
+now=$(date +%s)
+gpu=$(nvidia-smi --query-gpu=utilization.gpu --format=csv,noheader,nounits 2>/dev/null | grep -E '^ *[0-9]+ *$' | sort -n | tail -n 1 | tr -d ' ')
+sessions=$(ps -eo args= | grep -c '^sshd: [^ ]*@')
+state() { sed -n "s/^$1=//p" "$dir/state" 2>/dev/null; }
+last_active=$(state last_active)
+checked_at=$(state checked_at)
+acted=$(state acted)
+acted_at=$(state acted_at)
+api_error=$(state api_error)
+api_error_at=$(state api_error_at)
+if [ -z "$last_active" ] || [ $((now - ${checked_at:-0})) -gt ` + strconv.Itoa(int(idleWatcherGap/time.Second)) + ` ] ||
+	[ "${gpu:-0}" -ge ` + strconv.Itoa(IdleGPUThreshold) + ` ] || [ "$sessions" -gt 0 ]; then
+	last_active=$now
+	acted=
+fi
+save() {
+	printf 'last_active=%s\nchecked_at=%s\ngpu=%s\nsessions=%s\nacted=%s\nacted_at=%s\napi_error=%s\napi_error_at=%s\n' \
+		"$last_active" "$now" "$gpu" "$sessions" "$acted" "$acted_at" "$api_error" "$api_error_at" > "$dir/state.tmp" &&
+		mv "$dir/state.tmp" "$dir/state"
+}
+act() {
+	acted=$1
+	acted_at=$now
+	save
+}
+save
+case "$acted" in
+"snapshotting "* | "deleting "*) ;;
+?*) exit 0 ;;
+*) [ $((now - last_active)) -ge "$IDLE_TIMEOUT" ] || exit 0 ;;
+esac
+
+api() {
+	curl -fsS -X "$1" -H "Authorization: Bearer $(cat ` + tokenPath + `)" -H 'Content-Type: application/json' \
+		-H 'Thunder-Client: GO-CLI' ${3+-d} ${3+"$3"} "$IDLE_API_URL$2" 2> "$dir/api.err"
+}
+# fail records why an API call failed and exits; cron tries again in a minute.
+fail() {
+	api_error="$1: $(tr '\n' ' ' < "$dir/api.err")"
+	api_error_at=$now
+	save
+	echo "$(date -u) $api_error"
+	exit 1
+}
+# snapshot_status prints the status of snapshot $1 in the snapshot list on
+# stdin, using jq where the image has it.
+snapshot_status() {
+	if command -v jq > /dev/null; then
+		jq -r --arg name "$1" '.[] | select(.name == $name) | .status'
+	else
+		tr -d '\n' | sed 's/}[[:space:]]*,[[:space:]]*{/}\n{/g' | grep "\"name\": *\"$1\"" |
+			sed -n 's/.*"status": *"\([A-Z_]*\)".*/\1/p'
+	fi
+}
+
+case "$IDLE_ACTION" in
+stop)
+	echo "$(date -u) idle for $((now - last_active))s, stopping"
+	api POST "/v1/instances/$IDLE_INSTANCE_ID/stop" || fail "stop instance"
+	;;
+snapshot-and-delete)
+	case "$acted" in
+	"")
+		echo "$(date -u) idle for $((now - last_active))s, taking a snapshot"
+		name="idle-$IDLE_INSTANCE_ID-$(date -u +%Y%m%d-%H%M)"
+		api POST /v1/snapshots/create "{\"instanceId\":\"$IDLE_INSTANCE_UUID\",\"name\":\"$name\"}" || fail "create snapshot $name"
+		api_error=
+		act "snapshotting $name"
+		exit 0
+		;;
+	"snapshotting "*)
+		name=${acted#snapshotting }
+		api GET /v1/snapshots/list > "$dir/snapshots" || fail "list snapshots"
+		api_error=
+		case "$(snapshot_status "$name" < "$dir/snapshots")" in
+		READY) act "deleting $name" ;;
+		FAILED) act "snapshot $name failed"; exit 1 ;;
+		*)
+			if [ $((now - acted_at)) -ge ` + strconv.Itoa(int(idleSnapshotTimeout/time.Second)) + ` ]; then
+				act "snapshot $name timed out"
+				exit 1
+			fi
+			save
+			exit 0
+			;;
+		esac
+		;;
+	esac
+	echo "$(date -u) snapshot ${acted#deleting } is ready, deleting the instance"
+	api POST "/v1/instances/$IDLE_INSTANCE_ID/delete" || fail "delete instance"
+	;;
+esac
+api_error=
+act "$IDLE_ACTION"
+`
+
+// idlePolicyEnv renders the policy file the watcher script sources.
+func idlePolicyEnv(policy *IdlePolicy, apiURL string) (string, error) {
+	if !idleIDPattern.MatchString(policy.InstanceID) || !idleIDPattern.MatchString(policy.InstanceUUID) {
+		return "", fmt.Errorf("invalid instance identifiers")
+	}
+	if strings.ContainsAny(apiURL, "'\n") {
+		return "", fmt.Errorf("invalid API URL %q", apiURL)
+	}
+	return fmt.Sprintf("IDLE_INSTANCE_ID=%s\nIDLE_INSTANCE_UUID=%s\nIDLE_TIMEOUT=%d\nIDLE_ACTION=%s\nIDLE_API_URL='%s'\n",
+		policy.InstanceID, policy.InstanceUUID, policy.TimeoutSeconds, policy.Action, apiURL), nil
+}
+
+// InstallIdleWatcher puts the idle watcher and its policy on the instance
+// and schedules it. The watcher calls the API with the token SetupToken
+// leaves on the instance, so it must run after SetupToken.
+func InstallIdleWatcher(client *SSHClient, policy *IdlePolicy, apiURL string) error {
+	env, err := idlePolicyEnv(policy, apiURL)
+	if err != nil {
+		return err
+	}
+	cron := fmt.Sprintf("* * * * * ubuntu flock -n %[1]s/lock %[1]s/watch.sh >> %[1]s/watch.log 2>&1\n", idleDir)
+	b64 := base64.StdEncoding.EncodeToString
+	commands := []string{
+		fmt.Sprintf("mkdir -p %s", idleDir),
+		fmt.Sprintf("echo '%s' | base64 -d > %s/watch.sh", b64([]byte(idleWatcherScript)), idleDir),
+		fmt.Sprintf("chmod 755 %s/watch.sh", idleDir),
+		fmt.Sprintf("echo '%s' | base64 -d > %s/policy", b64([]byte(env)), idleDir),
+		// A new policy starts counting from now.
+		fmt.Sprintf("rm -f %s/state", idleDir),
+		fmt.Sprintf("echo '%s' | base64 -d | sudo tee %s > /dev/null", b64([]byte(cron)), idleCronPath),
+		fmt.Sprintf("sudo chmod 644 %s", idleCronPath),
+	}
+	if _, err := ExecuteSSHCommand(client, strings.Join(commands, " && ")); err != nil {
+		return fmt.Errorf("failed to install idle watcher: %w", err)
+	}
+	return nil
+}
+
+// UninstallIdleWatcher removes the idle watcher from the instance.
+func UninstallIdleWatcher(client *SSHClient) error {
+	if _, err := ExecuteSSHCommand(client, fmt.Sprintf("sudo rm -f %s && rm -rf %s", idleCronPath, idleDir)); err != nil {
+		return fmt.Errorf("failed to remove idle watcher: %w", err)
+	}
+	return nil
+}
+
+// IdleWatcherStatus is what tnr idle reports about a running instance.
+type IdleWatcherStatus struct {
+	Installed bool `json:"installed"`
+	// GPUUtilization is the busiest GPU right now, or -1 if unknown.
+	GPUUtilization int `json:"gpu_utilization"`
+	// SSHSessions excludes the connection used to check.
+	SSHSessions int       `json:"ssh_sessions"`
+	LastActive  time.Time `json:"last_active,omitempty"`
+	LastCheck   time.Time `json:"last_check,omitempty"`
+	// Acted is the watcher's last action since the instance was last busy,
+	// or the step snapshot-and-delete is on.
+	Acted string `json:"acted,omitempty"`
+	// APIError is the watcher's last failed API call, if it has not
+	// succeeded since.
+	APIError   string    `json:"api_error,omitempty"`
+	APIErrorAt time.Time `json:"api_error_at,omitempty"`
+}
+
+const idleStatusSeparator = "--tnr-idle--"
+
+// ReadIdleWatcherStatus samples the instance and reads the watcher's state.
+func ReadIdleWatcherStatus(client *SSHClient) (*IdleWatcherStatus, error) {
+	command := strings.Join([]string{
+		fmt.Sprintf("test -f %s && echo installed", idleCronPath),
+		"echo " + idleStatusSeparator,
+		fmt.Sprintf("cat %s/state 2>/dev/null", idleDir),
+		"echo " + idleStatusSeparator,
+		"nvidia-smi --query-gpu=utilization.gpu --format=csv,noheader,nounits 2>/dev/null",
+		"echo " + idleStatusSeparator,
+		"ps -eo args=",
+	}, "; ")
+	output, err := ExecuteSSHCommand(client, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idle watcher status: %w", err)
+	}
+	return parseIdleWatcherStatus(output)
+}
+
+func parseIdleWatcherStatus(output string) (*IdleWatcherStatus, error) {
+	parts := strings.Split(output, idleStatusSeparator+"\n")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("unexpected idle watcher status output")
+	}
+	status := &IdleWatcherStatus{
+		Installed:      strings.TrimSpace(parts[0]) == "installed",
+		GPUUtilization: -1,
+		// The connection reading this status is a session too.
+		SSHSessions: max(CountSSHSessions(parts[3])-1, 0),
+	}
+	if util, err := ParseGPUUtilization(parts[2]); err == nil {
+		status.GPUUtilization = util
+	}
+	for _, line := range strings.Split(parts[1], "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "last_active", "checked_at", "api_error_at":
+			secs, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "last_active":
+				status.LastActive = time.Unix(secs, 0)
+			case "checked_at":
+				status.LastCheck = time.Unix(secs, 0)
+			default:
+				status.APIErrorAt = time.Unix(secs, 0)
+			}
+		case "acted":
+			status.Acted = value
+		case "api_error":
+			status.APIError = value
+		}
+	}
+	return status, nil
+}
+
+// Tracker returns an IdleTracker positioned at the watcher's last check.
+func (s *IdleWatcherStatus) Tracker(timeout time.Duration, now func() time.Time) *IdleTracker {
+	t := NewIdleTracker(timeout, now)
+	if !s.LastActive.IsZero() {
+		t.LastActive, t.LastCheck = s.LastActive, s.LastCheck
+	}
+	return t
+}
@@ -0,0 +1,291 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestIdleTracker(t *testing.T) {
+	idle := IdleSample{GPUUtilization: 0}
+
+	t.Run("fires after the timeout", func(t *testing.T) {
+		clock := &fakeClock{t: time.Unix(1_000_000, 0)}
+		tracker := NewIdleTracker(10*time.Minute, clock.now)
+		for range 9 {
+			clock.advance(time.Minute)
+			assert.False(t, tracker.Observe(idle))
+		}
+		assert.Equal(t, time.Minute, tracker.Remaining())
+		clock.advance(time.Minute)
+		assert.True(t, tracker.Observe(idle))
+		assert.Equal(t, time.Duration(0), tracker.Remaining())
+	})
+
+	t.Run("busy GPU or SSH session resets", func(t *testing.T) {
+		clock := &fakeClock{t: time.Unix(1_000_000, 0)}
+		tracker := NewIdleTracker(10*time.Minute, clock.now)
+		idleFor := func(minutes int) bool {
+			fired := false
+			for range minutes {
+				clock.advance(time.Minute)
+				fired = tracker.Observe(idle)
+			}
+			return fired
+		}
+		idleFor(8)
+		tracker.Observe(IdleSample{GPUUtilization: IdleGPUThreshold})
+		assert.False(t, idleFor(9))
+		tracker.Observe(IdleSample{SSHSessions: 1})
+		assert.False(t, idleFor(9))
+		assert.Equal(t, 9*time.Minute, tracker.IdleFor())
+		assert.True(t, idleFor(1))
+	})
+
+	t.Run("low utilisation is idle", func(t *testing.T) {
+		clock := &fakeClock{t: time.Unix(1_000_000, 0)}
+		tracker := NewIdleTracker(10*time.Minute, clock.now)
+		for range 10 {
+			clock.advance(time.Minute)
+			tracker.Observe(IdleSample{GPUUtilization: IdleGPUThreshold - 1})
+		}
+		assert.True(t, tracker.Observe(idle))
+	})
+
+	t.Run("gap in checks restarts the clock", func(t *testing.T) {
+		clock := &fakeClock{t: time.Unix(1_000_000, 0)}
+		tracker := NewIdleTracker(10*time.Minute, clock.now)
+		clock.advance(3 * time.Hour)
+		assert.False(t, tracker.Observe(idle))
+		assert.Equal(t, time.Duration(0), tracker.IdleFor())
+	})
+}
+
+func TestParseGPUUtilization(t *testing.T) {
+	util, err := ParseGPUUtilization("0\n 87\n12\n")
+	require.NoError(t, err)
+	assert.Equal(t, 87, util)
+
+	util, err = ParseGPUUtilization("[N/A]\n3 %\n")
+	require.NoError(t, err)
+	assert.Equal(t, 3, util)
+
+	_, err = ParseGPUUtilization("")
+	assert.Error(t, err)
+	_, err = ParseGPUUtilization("[N/A]\n")
+	assert.Error(t, err)
+}
+
+func TestCountSSHSessions(t *testing.T) {
+	ps := `/sbin/init
+sshd: /usr/sbin/sshd -D [listener] 0 of 10-100 startups
+sshd: ubuntu [priv]
+sshd: ubuntu@pts/0
+-bash
+sshd: ubuntu [priv]
+sshd: ubuntu@notty
+grep sshd: ubuntu@pts/0
+`
+	assert.Equal(t, 2, CountSSHSessions(ps))
+	assert.Equal(t, 0, CountSSHSessions(""))
+}
+
+func TestParseIdleWatcherStatus(t *testing.T) {
+	output := "installed\n--tnr-idle--\nlast_active=1000\nchecked_at=1600\ngpu=0\nsessions=0\nacted=\n" +
+		"api_error=stop instance: curl: (22) The requested URL returned error: 401 \napi_error_at=1540\n" +
+		"--tnr-idle--\n0\n2\n--tnr-idle--\nsshd: ubuntu@notty\nsshd: ubuntu@pts/1\n"
+	status, err := parseIdleWatcherStatus(output)
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.Equal(t, 2, status.GPUUtilization)
+	assert.Equal(t, 1, status.SSHSessions)
+	assert.Equal(t, time.Unix(1000, 0), status.LastActive)
+	assert.Equal(t, time.Unix(1600, 0), status.LastCheck)
+	assert.Equal(t, "stop instance: curl: (22) The requested URL returned error: 401 ", status.APIError)
+	assert.Equal(t, time.Unix(1540, 0), status.APIErrorAt)
+
+	clock := &fakeClock{t: time.Unix(1660, 0)}
+	tracker := status.Tracker(20*time.Minute, clock.now)
+	assert.Equal(t, 11*time.Minute, tracker.IdleFor())
+	assert.Equal(t, 9*time.Minute, tracker.Remaining())
+
+	status, err = parseIdleWatcherStatus("--tnr-idle--\n--tnr-idle--\n--tnr-idle--\nsshd: ubuntu@notty\n")
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+	assert.Equal(t, -1, status.GPUUtilization)
+	assert.Equal(t, 0, status.SSHSessions)
+	assert.True(t, status.LastActive.IsZero())
+
+	_, err = parseIdleWatcherStatus("garbage")
+	assert.Error(t, err)
+}
+
+func TestIdlePolicyStore(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	policy, err := ReadIdlePolicy("abc-123")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	want := &IdlePolicy{InstanceID: "0", InstanceUUID: "abc-123", TimeoutSeconds: 7200, Action: IdleActionStop}
+	require.NoError(t, WriteIdlePolicy(want))
+	require.NoError(t, WriteIdlePolicy(&IdlePolicy{InstanceID: "1", InstanceUUID: "def-456", TimeoutSeconds: 600, Action: IdleActionSnapshotAndDelete}))
+
+	got, err := ReadIdlePolicy("abc-123")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 2*time.Hour, got.Timeout())
+
+	all, err := ListIdlePolicies()
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	require.NoError(t, RemoveIdlePolicy("abc-123"))
+	require.NoError(t, RemoveIdlePolicy("abc-123"))
+	got, err = ReadIdlePolicy("abc-123")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = ReadIdlePolicy("../token")
+	assert.Error(t, err)
+}
+
+func TestParseIdleAction(t *testing.T) {
+	action, err := ParseIdleAction("Snapshot-And-Delete")
+	require.NoError(t, err)
+	assert.Equal(t, IdleActionSnapshotAndDelete, action)
+
+	_, err = ParseIdleAction("delete")
+	assert.Error(t, err)
+}
+
+func TestIdlePolicyEnv(t *testing.T) {
+	const apiURL = "https://api.thundercompute.com:8443"
+	policy := &IdlePolicy{InstanceID: "3", InstanceUUID: "abc-123", TimeoutSeconds: 3600, Action: IdleActionStop}
+	env, err := idlePolicyEnv(policy, apiURL)
+	require.NoError(t, err)
+	assert.Equal(t, "IDLE_INSTANCE_ID=3\nIDLE_INSTANCE_UUID=abc-123\nIDLE_TIMEOUT=3600\nIDLE_ACTION=stop\nIDLE_API_URL='"+apiURL+"'\n", env)
+
+	_, err = idlePolicyEnv(policy, "https://x'; rm -rf ~'")
+	assert.Error(t, err)
+	_, err = idlePolicyEnv(&IdlePolicy{InstanceID: "$(id)", InstanceUUID: "abc"}, apiURL)
+	assert.Error(t, err)
+}
+
+// fakeCurl stands in for curl in TestIdleWatcherScript. It logs each call,
+// lists the snapshot the watcher created with the status in $T/status, and
+// fails deletes while $T/fail exists.
+const fakeCurl = `#!/bin/sh
+for url; do :; done
+echo "$url" >> "$T/calls"
+case "$url" in
+*/snapshots/create)
+	for arg; do case "$arg" in "{"*) echo "$arg" > "$T/body" ;; esac; done ;;
+*/snapshots/list)
+	name=$(sed 's/.*"name":"\([^"]*\)".*/\1/' "$T/body")
+	printf '[\n  {"name": "other", "status": "READY"},\n  {\n    "name": "%s",\n    "status": "%s"\n  }\n]\n' "$name" "$(cat "$T/status")" ;;
+*/delete)
+	if [ -f "$T/fail" ]; then
+		echo "curl: (22) The requested URL returned error: 401" >&2
+		exit 22
+	fi ;;
+esac
+`
+
+func TestIdleWatcherScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the watcher is a POSIX shell script")
+	}
+	tools := []string{"date", "grep", "sort", "tail", "tr", "sed", "mv", "cat"}
+	for _, withJQ := range []bool{false, true} {
+		if withJQ {
+			if _, err := exec.LookPath("jq"); err != nil {
+				continue
+			}
+			tools = append(tools, "jq")
+		}
+		t.Run(fmt.Sprintf("jq=%t", withJQ), func(t *testing.T) {
+			dir := t.TempDir()
+			bin := filepath.Join(dir, "bin")
+			require.NoError(t, os.Mkdir(bin, 0o755))
+			for _, tool := range tools {
+				path, err := exec.LookPath(tool)
+				require.NoError(t, err)
+				require.NoError(t, os.Symlink(path, filepath.Join(bin, tool)))
+			}
+			stubs := map[string]string{
+				"curl":       fakeCurl,
+				"nvidia-smi": "#!/bin/sh\necho 0\n",
+				"ps":         "#!/bin/sh\n",
+			}
+			for name, body := range stubs {
+				require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte(body), 0o755))
+			}
+
+			script := strings.ReplaceAll(idleWatcherScript, tokenPath, filepath.Join(dir, "token"))
+			script = strings.ReplaceAll(script, idleDir, dir)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "watch.sh"), []byte(script), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("tok"), 0o600))
+			policy, err := idlePolicyEnv(&IdlePolicy{InstanceID: "3", InstanceUUID: "abc", Action: IdleActionSnapshotAndDelete}, "http://api")
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "policy"), []byte(policy), 0o600))
+
+			setStatus := func(status string) {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o600))
+			}
+			run := func() *IdleWatcherStatus {
+				cmd := exec.Command("/bin/sh", filepath.Join(dir, "watch.sh"))
+				cmd.Env = []string{"PATH=" + bin, "T=" + dir}
+				_ = cmd.Run()
+				state, err := os.ReadFile(filepath.Join(dir, "state"))
+				require.NoError(t, err)
+				status, err := parseIdleWatcherStatus("--tnr-idle--\n" + string(state) + "--tnr-idle--\n--tnr-idle--\n")
+				require.NoError(t, err)
+				return status
+			}
+
+			setStatus("CREATING")
+			status := run()
+			require.True(t, strings.HasPrefix(status.Acted, "snapshotting idle-3-"), status.Acted)
+			name := strings.TrimPrefix(status.Acted, "snapshotting ")
+
+			status = run()
+			assert.Equal(t, "snapshotting "+name, status.Acted, "waits for the snapshot")
+
+			setStatus("READY")
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "fail"), nil, 0o600))
+			status = run()
+			assert.Equal(t, "deleting "+name, status.Acted)
+			assert.Contains(t, status.APIError, "delete instance: curl: (22)")
+
+			require.NoError(t, os.Remove(filepath.Join(dir, "fail")))
+			status = run()
+			assert.Equal(t, string(IdleActionSnapshotAndDelete), status.Acted, "the delete is retried")
+			assert.Empty(t, status.APIError)
+
+			calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+			require.NoError(t, err)
+			assert.Equal(t, "http://api/v1/snapshots/create\nhttp://api/v1/snapshots/list\nhttp://api/v1/snapshots/list\n"+
+				"http://api/v1/instances/3/delete\nhttp://api/v1/instances/3/delete\n", string(calls))
+
+			// Once it has acted, the watcher waits for activity.
+			run()
+			calls2, err := os.ReadFile(filepath.Join(dir, "calls"))
+			require.NoError(t, err)
+			assert.Equal(t, string(calls), string(calls2))
+		})
+	}
+}
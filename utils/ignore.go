@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// SyncIgnoreFiles are read from the root of a synced directory. Both use
+// .gitignore syntax; .tnrignore holds exclusions that only matter to tnr.
+var SyncIgnoreFiles = []string{".gitignore", ".tnrignore"}
+
+// IgnoreMatcher matches slash-separated paths, relative to the directory the
+// patterns were loaded from, against .gitignore-style patterns. Later
+// patterns take precedence, "!" re-includes, a trailing "/" matches only
+// directories, and a pattern containing "/" (other than a trailing one) is
+// anchored to the root. "*" and "?" do not cross "/"; "**" matches any number
+// of directories. A nil matcher ignores nothing.
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// ParseIgnorePatterns reads patterns from r, one per line. Blank lines and
+// lines starting with "#" are skipped.
+func ParseIgnorePatterns(r io.Reader) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m.add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *IgnoreMatcher) add(line string) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimLeft(line, "/")
+	}
+	if line == "" {
+		return
+	}
+	rule.segments = strings.Split(line, "/")
+	m.rules = append(m.rules, rule)
+}
+
+// Merge appends other's patterns, which then take precedence over m's.
+func (m *IgnoreMatcher) Merge(other *IgnoreMatcher) {
+	if other != nil {
+		m.rules = append(m.rules, other.rules...)
+	}
+}
+
+// Match reports whether rel should be ignored. Callers walking a tree should
+// not descend into ignored directories: as with git, a file cannot be
+// re-included when one of its parent directories is excluded.
+func (m *IgnoreMatcher) Match(rel string, isDir bool) bool {
+	if m == nil || rel == "" {
+		return false
+	}
+	name := strings.Split(rel, "/")
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.matches(name) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(name []string) bool {
+	if !r.anchored {
+		ok, _ := path.Match(r.segments[0], name[len(name)-1])
+		return ok
+	}
+	return matchIgnoreSegments(r.segments, name)
+}
+
+func matchIgnoreSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				// "dir/**" matches everything inside dir, but not dir itself.
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchIgnoreSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
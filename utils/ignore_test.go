@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	m, err := ParseIgnorePatterns(strings.NewReader(strings.Join([]string{
+		"# build output",
+		"*.pyc",
+		"__pycache__/",
+		"/checkpoints",
+		"data/raw/",
+		"logs/**/*.log",
+		"*.ckpt",
+		"!keep.ckpt",
+		"\\#notes",
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"a.pyc", false, true},
+		{"src/model/a.pyc", false, true},
+		{"__pycache__", true, true},
+		{"src/__pycache__", true, true},
+		{"__pycache__", false, false},
+		{"checkpoints", true, true},
+		{"src/checkpoints", true, false},
+		{"data/raw", true, true},
+		{"src/data/raw", true, false},
+		{"logs/a.log", false, true},
+		{"logs/run1/a.log", false, true},
+		{"src/logs/a.log", false, false},
+		{"best.ckpt", false, true},
+		{"keep.ckpt", false, false},
+		{"#notes", false, true},
+		{"train.py", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, m.Match(tt.rel, tt.isDir), tt.rel)
+	}
+
+	var none *IgnoreMatcher
+	assert.False(t, none.Match("a.pyc", false))
+}
+
+func TestIgnoreMatcherMerge(t *testing.T) {
+	git, err := ParseIgnorePatterns(strings.NewReader("*.bin\n"))
+	require.NoError(t, err)
+	tnr, err := ParseIgnorePatterns(strings.NewReader("!weights.bin\nwandb/\n"))
+	require.NoError(t, err)
+	git.Merge(tnr)
+
+	assert.True(t, git.Match("data.bin", false))
+	assert.False(t, git.Match("weights.bin", false))
+	assert.True(t, git.Match("wandb", true))
+}
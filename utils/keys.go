@@ -6,7 +6,8 @@ import (
 	"path/filepath"
 )
 
-// GetKeyFile returns the path to the SSH key file for a given instance UUID.
+// GetKeyFile returns the path to the SSH key file for a given instance UUID,
+// under the selected profile's keys directory.
 // Falls back to empty string only if ThunderDir resolution fails, which
 // callers should treat as "no cached key".
 func GetKeyFile(uuid string) string {
@@ -14,7 +15,7 @@ func GetKeyFile(uuid string) string {
 	if err != nil {
 		return ""
 	}
-	return filepath.Join(base, "keys", uuid)
+	return filepath.Join(base, keysSubdir(), uuid)
 }
 
 // KeyExists checks if the SSH key file exists for a given UUID
@@ -29,7 +30,7 @@ func KeyExists(uuid string) bool {
 
 // SavePrivateKey writes the private key to disk with appropriate permissions
 func SavePrivateKey(uuid, privateKey string) error {
-	keyDir, err := ThunderSubdir("keys")
+	keyDir, err := ThunderSubdir(keysSubdir())
 	if err != nil {
 		return fmt.Errorf("failed to create keys directory: %w", err)
 	}
@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -116,3 +118,33 @@ func isProcessAlive(pid int) bool {
 	_, err := os.FindProcess(pid)
 	return err == nil
 }
+
+// LockConfigFile takes the lock that serializes rewrites of cli_config.json
+// across tnr processes, waiting for another holder until ctx is done. The
+// lock is released by calling release or when the process exits.
+func LockConfigFile(ctx context.Context) (release func(), err error) {
+	dir, err := ThunderDir()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "cli_config.lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config lock: %w", err)
+	}
+	for {
+		err := lockFile(f)
+		if err == nil {
+			return func() { f.Close() }, nil
+		}
+		if !errors.Is(err, errFileLocked) {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock config: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("timed out waiting for another tnr process to update the config: %w", ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
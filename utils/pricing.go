@@ -1,6 +1,16 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
+)
+
+// HoursPerMonth is the average month used for monthly projections.
+const HoursPerMonth = 730
 
 // PricingData holds fetched pricing rates from the API.
 type PricingData struct {
@@ -47,3 +57,42 @@ func CalculateHourlyPrice(p *PricingData, mode, gpuType string, numGPUs, vcpus,
 func FormatPrice(hourlyPrice float64) string {
 	return fmt.Sprintf("$%.2f/hr", hourlyPrice)
 }
+
+// InstanceHourlyPrice prices an existing instance's current configuration.
+func InstanceHourlyPrice(p *PricingData, specs *SpecStore, inst *api.Instance) float64 {
+	mode := strings.ToLower(inst.Mode)
+	gpuType, _ := specs.NormalizeGPUType(inst.GPUType, mode)
+	numGPUs, err := strconv.Atoi(inst.NumGPUs)
+	if err != nil || numGPUs == 0 {
+		numGPUs = 1
+	}
+	included := specs.IncludedVCPUs(gpuType, numGPUs, mode)
+	vcpus, err := strconv.Atoi(inst.CPUCores)
+	if err != nil {
+		vcpus = included
+	}
+	return CalculateHourlyPrice(p, mode, gpuType, numGPUs, vcpus, inst.Storage, inst.EphemeralDiskGB, included)
+}
+
+// MonthStart returns the start of the billing month containing now, in UTC.
+func MonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// HoursLeftInMonth returns the hours from now until the next billing month.
+func HoursLeftInMonth(now time.Time) float64 {
+	return MonthStart(now).AddDate(0, 1, 0).Sub(now).Hours()
+}
+
+// ParseCreatedAt parses an instance's createdAt, which the API sends as
+// RFC 3339 or as Unix seconds.
+func ParseCreatedAt(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil && secs > 0 {
+		return time.Unix(secs, 0), true
+	}
+	return time.Time{}, false
+}
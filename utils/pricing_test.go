@@ -2,8 +2,11 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/Thunder-Compute/thunder-cli/api"
 )
 
 func testPricingData() *PricingData {
@@ -206,3 +209,30 @@ func TestFormatPrice(t *testing.T) {
 		})
 	}
 }
+
+func TestInstanceHourlyPrice(t *testing.T) {
+	p := testPricingData()
+	s := testSpecStore()
+
+	inst := &api.Instance{Mode: "prototyping", GPUType: "A100", NumGPUs: "1", CPUCores: "8", Storage: 200}
+	assert.InDelta(t, 1.10+4*0.03+100*0.0001, InstanceHourlyPrice(p, s, inst), 1e-9)
+
+	// Unparseable counts fall back to one GPU and the included vCPUs.
+	inst = &api.Instance{Mode: "production", GPUType: "h100", CPUCores: "n/a", Storage: 100}
+	assert.InDelta(t, 3.49, InstanceHourlyPrice(p, s, inst), 1e-9)
+}
+
+func TestBillingMonth(t *testing.T) {
+	now := time.Date(2026, 2, 27, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), MonthStart(now))
+	assert.Equal(t, 36.0, HoursLeftInMonth(now))
+
+	created, ok := ParseCreatedAt("2026-02-03T04:05:06Z")
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 2, 3, 4, 5, 6, 0, time.UTC), created.UTC())
+	created, ok = ParseCreatedAt("1700000000")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000000), created.Unix())
+	_, ok = ParseCreatedAt("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DefaultProfile is the auth profile used when none is selected. Its SSH
+// keys and host aliases keep their original, un-namespaced names.
+const DefaultProfile = "default"
+
+// profile is the auth profile this process runs as.
+var profile = DefaultProfile
+
+// SetProfile selects the auth profile whose SSH keys and host aliases are used.
+func SetProfile(name string) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	profile = name
+}
+
+// Profile returns the selected auth profile.
+func Profile() string {
+	return profile
+}
+
+// SSHHostAlias returns the ~/.ssh/config alias for an instance: tnr-<id>, or
+// tnr-<profile>-<id> outside the default profile, so instance IDs from two
+// organizations can't collide.
+func SSHHostAlias(instanceID string) string {
+	if profile == DefaultProfile {
+		return fmt.Sprintf("tnr-%s", instanceID)
+	}
+	return fmt.Sprintf("tnr-%s-%s", profile, instanceID)
+}
+
+// keysSubdir is the ThunderDir subdirectory holding the selected profile's
+// SSH keys.
+func keysSubdir() string {
+	return profileSubdir("keys")
+}
+
+// profileSubdir namespaces a ThunderDir subdirectory by the selected profile:
+// dir itself for the default profile and dir/<profile> for the others, so
+// that state keyed by instance ID can't collide between organizations.
+func profileSubdir(dir string) string {
+	if profile == DefaultProfile {
+		return dir
+	}
+	return filepath.Join(dir, profile)
+}
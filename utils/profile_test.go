@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileNamespacing(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TNR_HOME", dir)
+	t.Cleanup(func() { SetProfile(DefaultProfile) })
+
+	SetProfile("")
+	assert.Equal(t, DefaultProfile, Profile())
+	assert.Equal(t, "tnr-3", SSHHostAlias("3"))
+	assert.Equal(t, filepath.Join(dir, "keys", "abc"), GetKeyFile("abc"))
+
+	SetProfile("team")
+	assert.Equal(t, "tnr-team-3", SSHHostAlias("3"))
+	assert.Equal(t, filepath.Join(dir, "keys", "team", "abc"), GetKeyFile("abc"))
+
+	// Tunnel and fleet state written under one profile is invisible to the
+	// others, whose instance IDs and fleet names may be the same.
+	require.NoError(t, WriteTunnelState(&TunnelState{InstanceID: "0", PID: 1}))
+	fleet := &FleetState{Fleet: "web", Entries: map[string][]string{"gpu": {"u1"}}}
+	require.NoError(t, WriteFleetState(fleet))
+	_, err := os.Stat(filepath.Join(dir, "tunnels", "team", "tnr-0.json"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "fleets", "team", "web.json"))
+	assert.NoError(t, err)
+
+	SetProfile(DefaultProfile)
+	tunnel, err := ReadTunnelState("0")
+	require.NoError(t, err)
+	assert.Nil(t, tunnel)
+	got, err := ReadFleetState("web")
+	require.NoError(t, err)
+	assert.Empty(t, got.Entries)
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,35 +17,60 @@ import (
 
 // SessionConfig holds configuration for an interactive SSH session.
 type SessionConfig struct {
-	Client *SSHClient
-	Ports  []int    // local ports to forward (each maps localhost:port -> remote localhost:port)
-	Stdin  *os.File // typically os.Stdin
-	Stdout *os.File // typically os.Stdout
-	Stderr *os.File // typically os.Stderr
+	Client   *SSHClient
+	Forwards []ForwardSpec // -L/-R style forwards active for the lifetime of the session
+	Stdin    *os.File      // typically os.Stdin
+	Stdout   *os.File      // typically os.Stdout
+	Stderr   *os.File      // typically os.Stderr
+
+	// Command, when set, runs in the PTY instead of the login shell.
+	Command string
+	// Reconnect, when set, enables auto-reconnect: if the connection drops,
+	// it is called (with backoff) to open a new connection and the session
+	// is reattached. Pair it with a Command that reattaches to a persistent
+	// remote session, such as PersistentShellCommand.
+	Reconnect func(ctx context.Context) (*SSHClient, error)
+}
+
+// ErrReconnectAborted is returned when the user gives up on reconnecting.
+var ErrReconnectAborted = errors.New("reconnect cancelled")
+
+// PersistentShellCommand returns a remote command that attaches to (or
+// creates) a tmux session with the given name, falling back to screen and
+// then a plain login shell when neither is installed. The plain shell does not
+// survive a reconnect, so the fallback prints a warning first. The name must
+// be shell-safe.
+func PersistentShellCommand(name string) string {
+	return fmt.Sprintf("if command -v tmux >/dev/null 2>&1; then exec tmux new-session -A -s %[1]s; "+
+		"elif command -v screen >/dev/null 2>&1; then exec screen -D -RR -S %[1]s; "+
+		"else echo '%[2]s' >&2; exec \"${SHELL:-/bin/bash}\" -l; fi", name, persistentShellMissingWarning)
 }
 
+// persistentShellMissingWarning is shown on the instance when
+// PersistentShellCommand falls back to a plain login shell.
+const persistentShellMissingWarning = "[tnr] Warning: neither tmux nor screen is installed; " +
+	"processes in this shell will not survive a reconnect (install with: sudo apt-get install -y tmux)"
+
 // RunInteractiveSession starts a PTY-allocated shell session with local port forwarding.
 // It blocks until the session ends (user exits shell, connection drops, or ctx is cancelled).
+// With cfg.Reconnect set, a dropped connection is redialed and the session reattached
+// instead of ending it.
 func RunInteractiveSession(ctx context.Context, cfg SessionConfig) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	sshClient := cfg.Client.GetClient()
-
-	// Start port forwarding before raw mode so bind errors print cleanly.
-	for _, port := range cfg.Ports {
-		if err := startPortForward(ctx, sshClient, port); err != nil {
-			fmt.Fprintf(cfg.Stderr, "Warning: could not forward port %d: %v\n", port, err)
+	client := cfg.Client
+	defer func() {
+		// cfg.Client belongs to the caller; connections we redialed are ours.
+		if client != cfg.Client {
+			client.Close()
 		}
-	}
+	}()
 
-	// Get terminal size before entering raw mode.
-	// Use stdout fd — on Windows, the screen buffer size is on the output handle.
-	outFd := int(cfg.Stdout.Fd())
-	width, height, err := term.GetSize(outFd)
-	if err != nil {
-		width, height = 80, 24
-	}
+	// Start port forwarding before raw mode so bind errors print cleanly.
+	connCtx, connCancel := context.WithCancel(ctx)
+	defer func() { connCancel() }()
+	startSessionForwards(connCtx, client.GetClient(), cfg, "\n")
 
 	fd := int(cfg.Stdin.Fd())
 	if term.IsTerminal(fd) {
@@ -54,6 +81,53 @@ func RunInteractiveSession(ctx context.Context, cfg SessionConfig) error {
 		defer term.Restore(fd, oldState)
 	}
 
+	input := startStdinPump(cfg.Stdin)
+	for {
+		err := runRemoteShell(connCtx, client.GetClient(), cfg, input)
+		connCancel()
+		if cfg.Reconnect == nil || ctx.Err() != nil || !isConnectionLost(client.GetClient(), err) {
+			return err
+		}
+
+		if client != cfg.Client {
+			client.Close()
+		}
+		next, reconnectErr := reconnectSession(ctx, cfg, input)
+		if reconnectErr != nil {
+			return reconnectErr
+		}
+		client = next
+
+		connCtx, connCancel = context.WithCancel(ctx)
+		startSessionForwards(connCtx, client.GetClient(), cfg, "\r\n")
+	}
+}
+
+// startSessionForwards starts cfg.Forwards on the given connection, printing
+// a warning for each forward that could not be set up.
+func startSessionForwards(ctx context.Context, sshClient *ssh.Client, cfg SessionConfig, newline string) {
+	for _, fwd := range cfg.Forwards {
+		if err := startForward(ctx, sshClient, fwd); err != nil {
+			fmt.Fprintf(cfg.Stderr, "Warning: could not forward %s: %v%s", fwd.Describe(), err, newline)
+		}
+	}
+}
+
+// runRemoteShell runs one PTY session on sshClient until the remote side
+// exits or the connection is lost. A connection declared dead by the
+// keepalive is closed so the session ends promptly.
+func runRemoteShell(ctx context.Context, sshClient *ssh.Client, cfg SessionConfig, input <-chan []byte) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Get terminal size.
+	// Use stdout fd — on Windows, the screen buffer size is on the output handle.
+	outFd := int(cfg.Stdout.Fd())
+	width, height, err := term.GetSize(outFd)
+	if err != nil {
+		width, height = 80, 24
+	}
+
 	session, err := sshClient.NewSession()
 	if err != nil {
 		return fmt.Errorf("failed to create SSH session: %w", err)
@@ -82,11 +156,20 @@ func RunInteractiveSession(ctx context.Context, cfg SessionConfig) error {
 		return fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
-	if err := session.Shell(); err != nil {
+	if cfg.Command != "" {
+		err = session.Start(cfg.Command)
+	} else {
+		err = session.Shell()
+	}
+	if err != nil {
 		return fmt.Errorf("failed to start shell: %w", err)
 	}
 
-	go startKeepalive(ctx, sshClient)
+	go func() {
+		if startKeepalive(ctx, sshClient) {
+			sshClient.Close()
+		}
+	}()
 	go watchWindowSize(ctx, outFd, session)
 
 	// Only wait for stdout/stderr — stdin blocks on os.Stdin.Read() which
@@ -102,7 +185,7 @@ func RunInteractiveSession(ctx context.Context, cfg SessionConfig) error {
 		defer wg.Done()
 		io.Copy(cfg.Stderr, sessionStderr)
 	}()
-	go pipeStdin(cfg.Stdin, sessionStdin)
+	go forwardInput(ctx, input, sessionStdin)
 
 	err = session.Wait()
 	cancel()
@@ -111,12 +194,97 @@ func RunInteractiveSession(ctx context.Context, cfg SessionConfig) error {
 	return err
 }
 
-// startPortForward sets up local port forwarding: listens on 127.0.0.1:localPort
-// and tunnels each connection through the SSH client to localhost:localPort on the remote.
-func startPortForward(ctx context.Context, sshClient *ssh.Client, localPort int) error {
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+// isConnectionLost reports whether a session ended because the transport
+// went away rather than because the remote shell exited. Apart from a remote
+// exit status, every session error (a missing exit status, a refused PTY
+// request, a failed shell start) can also occur on a healthy connection, so
+// the transport is probed to tell the two apart. Reconnecting after an error
+// on a healthy connection would only reproduce it.
+func isConnectionLost(sshClient *ssh.Client, err error) bool {
+	if err == nil {
+		return false
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return false
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := sshClient.SendRequest("keepalive@openssh.com", true, nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err != nil
+	case <-time.After(10 * time.Second):
+		return true
+	}
+}
+
+// reconnectSession redials with cfg.Reconnect, backing off between
+// attempts, until it succeeds, the failure is permanent, or the user presses
+// Ctrl+C. The terminal is in raw mode, so Ctrl+C arrives on stdin as a byte
+// rather than as SIGINT.
+func reconnectSession(ctx context.Context, cfg SessionConfig, input <-chan []byte) (*SSHClient, error) {
+	const (
+		initialBackoff = time.Second
+		maxBackoff     = 30 * time.Second
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-input:
+				if !ok || bytes.IndexByte(data, 0x03) >= 0 {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		fmt.Fprintf(cfg.Stderr, "\r\n[tnr] Connection lost, reconnecting (attempt %d, Ctrl+C to give up)...\r\n", attempt)
+		client, err := cfg.Reconnect(ctx)
+		if err == nil {
+			fmt.Fprintf(cfg.Stderr, "[tnr] Reconnected.\r\n")
+			return client, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ErrReconnectAborted
+		}
+		if errors.Is(err, ErrPersistentAuthFailure) {
+			return nil, err
+		}
+		fmt.Fprintf(cfg.Stderr, "[tnr] Reconnect failed: %v\r\n", err)
+		if sleepWithContext(ctx, backoff) != nil {
+			return nil, ErrReconnectAborted
+		}
+		backoff = minDuration(backoff*2, maxBackoff)
+	}
+}
+
+// startForward starts a local or remote forward that stays up until ctx is done.
+func startForward(ctx context.Context, sshClient *ssh.Client, fwd ForwardSpec) error {
+	if fwd.Direction == ForwardRemote {
+		return startRemoteForward(ctx, sshClient, fwd)
+	}
+	return startLocalForward(ctx, sshClient, fwd)
+}
+
+// startLocalForward listens on 127.0.0.1:BindPort and tunnels each connection
+// through the SSH client to the forward's target as seen from the instance.
+// Dynamic forwards serve SOCKS5 on the listener instead.
+func startLocalForward(ctx context.Context, sshClient *ssh.Client, fwd ForwardSpec) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", fwd.BindPort))
 	if err != nil {
-		return fmt.Errorf("listen on port %d: %w", localPort, err)
+		return fmt.Errorf("listen on port %d: %w", fwd.BindPort, err)
 	}
 
 	go func() {
@@ -130,7 +298,52 @@ func startPortForward(ctx context.Context, sshClient *ssh.Client, localPort int)
 			if err != nil {
 				return // listener closed
 			}
-			go forwardConnection(sshClient, localConn, localPort)
+			go serveLocalConn(sshClient, localConn, fwd)
+		}
+	}()
+
+	return nil
+}
+
+// serveLocalConn relays one accepted local connection for a local or
+// dynamic forward.
+func serveLocalConn(sshClient *ssh.Client, localConn net.Conn, fwd ForwardSpec) {
+	if fwd.Direction == ForwardDynamic {
+		serveSOCKS5(localConn, sshClient.Dial)
+		return
+	}
+	forwardConnection(sshClient, localConn, fwd.TargetAddr())
+}
+
+// startRemoteForward asks the instance to listen on its loopback BindPort
+// and relays each incoming connection to the forward's target, dialed from
+// this machine. This lets an instance reach services on the local network.
+func startRemoteForward(ctx context.Context, sshClient *ssh.Client, fwd ForwardSpec) error {
+	listener, err := sshClient.Listen("tcp", fmt.Sprintf("localhost:%d", fwd.BindPort))
+	if err != nil {
+		return fmt.Errorf("listen on instance port %d: %w", fwd.BindPort, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	go func() {
+		for {
+			remoteConn, err := listener.Accept()
+			if err != nil {
+				return // listener closed
+			}
+			go func() {
+				defer remoteConn.Close()
+				localConn, err := net.DialTimeout("tcp", fwd.TargetAddr(), 10*time.Second)
+				if err != nil {
+					return
+				}
+				defer localConn.Close()
+				pipeConns(localConn, remoteConn)
+			}()
 		}
 	}()
 
@@ -143,7 +356,9 @@ func startPortForward(ctx context.Context, sshClient *ssh.Client, localPort int)
 // SendRequest blocks for minutes (waiting for TCP retransmission to give up),
 // during which no keepalives are sent and NAT/firewall entries can expire.
 // The connection is only considered dead after multiple consecutive failures.
-func startKeepalive(ctx context.Context, client *ssh.Client) {
+// It returns true when the connection was declared dead and false when ctx
+// was cancelled.
+func startKeepalive(ctx context.Context, client *ssh.Client) bool {
 	const (
 		keepaliveInterval    = 15 * time.Second
 		keepaliveTimeout     = 10 * time.Second
@@ -156,7 +371,7 @@ func startKeepalive(ctx context.Context, client *ssh.Client) {
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return false
 		case <-ticker.C:
 			done := make(chan error, 1)
 			go func() {
@@ -166,61 +381,88 @@ func startKeepalive(ctx context.Context, client *ssh.Client) {
 			select {
 			case err := <-done:
 				if err != nil {
-					return // transport is dead
+					return true // transport is dead
 				}
 				consecutiveMisses = 0
 			case <-time.After(keepaliveTimeout):
 				consecutiveMisses++
 				if consecutiveMisses >= maxConsecutiveMisses {
-					return // connection is dead
+					return true // connection is dead
 				}
 				// Reply was lost but connection may still be alive - keep trying.
 				// The next SendRequest will generate fresh traffic to keep NAT entries alive.
 			case <-ctx.Done():
-				return
+				return false
 			}
 		}
 	}
 }
 
-// pipeStdin copies local stdin to the remote session stdin. Uses a manual
-// read loop instead of io.Copy because on Windows, Ctrl+Z causes ReadConsole
-// to return EOF — we must keep reading after that or the user can never type again.
-func pipeStdin(local *os.File, remote io.WriteCloser) {
-	buf := make([]byte, 1024)
-	for {
-		n, err := local.Read(buf)
-		if n > 0 {
-			if _, writeErr := remote.Write(buf[:n]); writeErr != nil {
+// startStdinPump reads local stdin for the lifetime of the process so input
+// can be handed from one remote session to the next across reconnects. Uses a
+// manual read loop instead of io.Copy because on Windows, Ctrl+Z causes
+// ReadConsole to return EOF — we must keep reading after that or the user can
+// never type again. The channel is closed on any other read error.
+func startStdinPump(local *os.File) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := local.Read(buf)
+			if n > 0 {
+				ch <- append([]byte(nil), buf[:n]...)
+			}
+			if err != nil {
+				if err == io.EOF {
+					continue
+				}
+				close(ch)
 				return
 			}
 		}
-		if err != nil {
-			if err == io.EOF {
-				continue
-			}
-			remote.Close()
+	}()
+	return ch
+}
+
+// forwardInput copies pumped stdin to the remote session until ctx is done.
+func forwardInput(ctx context.Context, input <-chan []byte, remote io.WriteCloser) {
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case data, ok := <-input:
+			if !ok {
+				remote.Close()
+				return
+			}
+			if _, err := remote.Write(data); err != nil {
+				return
+			}
 		}
 	}
 }
 
-func forwardConnection(sshClient *ssh.Client, localConn net.Conn, remotePort int) {
+func forwardConnection(sshClient *ssh.Client, localConn net.Conn, targetAddr string) {
 	defer localConn.Close()
 
-	remoteConn, err := sshClient.Dial("tcp", fmt.Sprintf("localhost:%d", remotePort))
+	remoteConn, err := sshClient.Dial("tcp", targetAddr)
 	if err != nil {
 		return
 	}
 	defer remoteConn.Close()
 
+	pipeConns(localConn, remoteConn)
+}
+
+// pipeConns copies in both directions until either side finishes.
+func pipeConns(a, b net.Conn) {
 	done := make(chan struct{}, 2)
 	go func() {
-		io.Copy(remoteConn, localConn)
+		io.Copy(a, b)
 		done <- struct{}{}
 	}()
 	go func() {
-		io.Copy(localConn, remoteConn)
+		io.Copy(b, a)
 		done <- struct{}{}
 	}()
 	<-done
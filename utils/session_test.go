@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestPersistentShellCommand(t *testing.T) {
+	cmd := PersistentShellCommand("tnr")
+	assert.Contains(t, cmd, "tmux new-session -A -s tnr")
+	assert.Contains(t, cmd, "screen -D -RR -S tnr")
+	assert.Contains(t, cmd, "${SHELL:-/bin/bash}")
+	assert.Contains(t, cmd, persistentShellMissingWarning)
+}
+
+func TestIsConnectionLost(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	clientPrivateKey, _, clientPublicKey := generateRSAKeyPair(t)
+	keyFile := filepath.Join(tmpDir, "session_key")
+	savePrivateKeyToFile(t, clientPrivateKey, keyFile)
+
+	server, serverCleanup := setupSSHTestServer(t, clientPublicKey)
+	defer serverCleanup()
+
+	client, err := RobustSSHConnectCtx(context.Background(), "127.0.0.1", keyFile, server.port, 5)
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.False(t, isConnectionLost(client.GetClient(), nil))
+	assert.False(t, isConnectionLost(client.GetClient(), &ssh.ExitError{}))
+
+	// On a healthy transport, a missing exit status means the remote process
+	// died, and other session errors (e.g. a refused PTY) would recur on a
+	// new connection.
+	assert.False(t, isConnectionLost(client.GetClient(), &ssh.ExitMissingError{}))
+	assert.False(t, isConnectionLost(client.GetClient(), errors.New("failed to request PTY: ssh: request failed")))
+
+	server.dropConnections()
+	require.Eventually(t, func() bool {
+		return isConnectionLost(client.GetClient(), &ssh.ExitMissingError{})
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.True(t, isConnectionLost(client.GetClient(), errors.New("EOF")))
+}
+
+func TestReconnectSessionRetriesUntilSuccess(t *testing.T) {
+	stderrFile, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+	defer stderrFile.Close()
+
+	want := &SSHClient{}
+	attempts := 0
+	cfg := SessionConfig{
+		Stderr: stderrFile,
+		Reconnect: func(ctx context.Context) (*SSHClient, error) {
+			attempts++
+			if attempts < 2 {
+				return nil, errors.New("connection refused")
+			}
+			return want, nil
+		},
+	}
+
+	got, err := reconnectSession(context.Background(), cfg, make(chan []byte))
+	require.NoError(t, err)
+	assert.Same(t, want, got)
+	assert.Equal(t, 2, attempts)
+
+	status, err := os.ReadFile(stderrFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(status), "attempt 2")
+	assert.Contains(t, string(status), "Reconnected")
+}
+
+func TestReconnectSessionCtrlCAborts(t *testing.T) {
+	stderrFile, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+	defer stderrFile.Close()
+
+	cfg := SessionConfig{
+		Stderr: stderrFile,
+		Reconnect: func(ctx context.Context) (*SSHClient, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	input := make(chan []byte, 1)
+	input <- []byte{0x03}
+	_, err = reconnectSession(context.Background(), cfg, input)
+	assert.ErrorIs(t, err, ErrReconnectAborted)
+}
+
+func TestReconnectSessionStopsOnPersistentAuthFailure(t *testing.T) {
+	stderrFile, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+	defer stderrFile.Close()
+
+	attempts := 0
+	cfg := SessionConfig{
+		Stderr: stderrFile,
+		Reconnect: func(ctx context.Context) (*SSHClient, error) {
+			attempts++
+			return nil, ErrPersistentAuthFailure
+		},
+	}
+
+	_, err = reconnectSession(context.Background(), cfg, make(chan []byte))
+	assert.ErrorIs(t, err, ErrPersistentAuthFailure)
+	assert.Equal(t, 1, attempts)
+}
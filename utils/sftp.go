@@ -0,0 +1,651 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// TransferProgress is reported while an SFTP transfer runs. Byte counts
+// include data already present at the destination (up-to-date or resumed
+// files), so Bytes reaches TotalBytes when the transfer completes.
+type TransferProgress struct {
+	Path       string // file most recently started, relative to the transfer root
+	Bytes      int64
+	TotalBytes int64
+	Files      int // files completed
+	TotalFiles int
+	Failed     int // files that could not be copied
+	// Sent counts only the data actually copied, for throughput estimates.
+	Sent int64
+	// Active lists the files being copied, in the order they started.
+	Active []FileTransferProgress
+}
+
+// FileTransferProgress is the progress of one file being copied.
+type FileTransferProgress struct {
+	Path       string
+	Bytes      int64
+	TotalBytes int64
+	Sent       int64
+	Started    time.Time
+}
+
+// TransferProgressFunc receives progress updates. It is called after every
+// chunk of data, so implementations should throttle any rendering.
+type TransferProgressFunc func(TransferProgress)
+
+// errTransferConnectionLost marks failures that a fresh connection can resume.
+var errTransferConnectionLost = newTransferUserError("connection lost during transfer: check your internet or instance status")
+
+// partialSuffix names the temporary file a transfer writes into before
+// renaming it over the destination. It is left behind on failure so the next
+// attempt can resume from it.
+const partialSuffix = ".tnr-partial"
+
+// partialSourceSuffix is appended to a partial file's name for the sidecar
+// recording which version of the source (size and mtime) the partial holds, so
+// a partial of an older version is discarded rather than spliced onto the new one.
+const partialSourceSuffix = ".src"
+
+// uploadChunkSize bounds how much data is written concurrently to a remote
+// file. Concurrent SFTP writes can land out of order, so after a failure only
+// the last chunk may contain gaps; see remoteFS.ResumeOffset.
+const uploadChunkSize = 8 << 20
+
+// SFTPTransfer copies localPath to remotePath (upload) or remotePath to
+// localPath over SFTP sessions on client. It follows scp -r semantics: a
+// destination that is an existing directory (or ends in "/") receives the
+// source by name, otherwise the source is copied to the destination path.
+// Permissions and modification times are preserved, files that already match
+// by size and mtime are skipped, and interrupted files are resumed. Up to
+// DefaultTransferWorkers files are copied at once.
+func SFTPTransfer(ctx context.Context, client *SSHClient, localPath, remotePath string, upload bool, progress TransferProgressFunc) error {
+	if client == nil || client.client == nil {
+		return fmt.Errorf("SSH client is not connected")
+	}
+	source, dest := transferSourceDest(localPath, remotePath, upload)
+	rec := newTransferRecorder()
+	if err := copyEndpoints(ctx, localEndpoint, sftpSessionEndpoint(client), []string{source}, dest,
+		upload, DefaultTransferWorkers, progress, rec); err != nil {
+		return err
+	}
+	return rec.report().Err()
+}
+
+// openSFTPSession starts an SFTP session on client. The session is closed
+// when ctx is cancelled, which unblocks any request in flight, or when the
+// returned function is called.
+func openSFTPSession(ctx context.Context, client *SSHClient) (*sftp.Client, func(), error) {
+	if client == nil || client.client == nil {
+		return nil, nil, fmt.Errorf("SSH client is not connected")
+	}
+	sc, err := sftp.NewClient(client.client)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ErrTransferCancelled
+		}
+		if isTransferConnectionError(err) {
+			return nil, nil, errTransferConnectionLost
+		}
+		return nil, nil, fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { sc.Close() })
+	return sc, func() {
+		stop()
+		sc.Close()
+	}, nil
+}
+
+// transferSFTP runs a transfer over an established SFTP client.
+func transferSFTP(ctx context.Context, sc *sftp.Client, localPath, remotePath string, upload bool, progress TransferProgressFunc) error {
+	source, dest := transferSourceDest(localPath, remotePath, upload)
+	rec := newTransferRecorder()
+	if err := copyEndpoints(ctx, localEndpoint, sftpClientEndpoint(sc), []string{source}, dest,
+		upload, DefaultTransferWorkers, progress, rec); err != nil {
+		return err
+	}
+	return rec.report().Err()
+}
+
+// transferSourceDest orders a local and a remote path by transfer direction.
+func transferSourceDest(localPath, remotePath string, upload bool) (source, dest string) {
+	if upload {
+		return localPath, remotePath
+	}
+	return remotePath, localPath
+}
+
+// copyEndpoints runs parallelCopy in the given direction, mapping shell-style
+// remote paths first.
+func copyEndpoints(ctx context.Context, local, remote transferEndpoint, sources []string, dest string, upload bool,
+	workers int, progress TransferProgressFunc, rec *transferRecorder) error {
+	if upload {
+		return parallelCopy(ctx, local, remote, sources, normalizeRemotePath(dest), workers, progress, rec)
+	}
+	remoteSources := make([]string, len(sources))
+	for i, source := range sources {
+		remoteSources[i] = normalizeRemotePath(source)
+	}
+	return parallelCopy(ctx, remote, local, remoteSources, dest, workers, progress, rec)
+}
+
+// copyBetween copies sources on one remote endpoint to dest on another.
+func copyBetween(ctx context.Context, from, to transferEndpoint, sources []string, dest string,
+	workers int, progress TransferProgressFunc, rec *transferRecorder) error {
+	remoteSources := make([]string, len(sources))
+	for i, source := range sources {
+		remoteSources[i] = normalizeRemotePath(source)
+	}
+	return parallelCopy(ctx, from, to, remoteSources, normalizeRemotePath(dest), workers, progress, rec)
+}
+
+// normalizeRemotePath maps shell-style home paths onto SFTP paths, which are
+// resolved relative to the remote user's home directory.
+func normalizeRemotePath(p string) string {
+	switch {
+	case p == "" || p == "~":
+		return "."
+	case strings.HasPrefix(p, "~/"):
+		if rest := p[2:]; rest != "" {
+			return rest
+		}
+		return "./"
+	default:
+		return p
+	}
+}
+
+// classifyTransferError maps filesystem and connection failures onto the
+// transfer error sentinels used by callers.
+func classifyTransferError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return ErrTransferCancelled
+	}
+	if errors.Is(err, ErrTransferUser) {
+		return err
+	}
+	if isTransferConnectionError(err) {
+		return errTransferConnectionLost
+	}
+
+	var pathErr *os.PathError
+	hasPath := errors.As(err, &pathErr)
+	switch {
+	case errors.Is(err, os.ErrPermission) && hasPath:
+		return newTransferUserError(fmt.Sprintf("permission denied: %s", pathErr.Path))
+	case errors.Is(err, os.ErrPermission):
+		return newTransferUserError("permission denied")
+	case errors.Is(err, os.ErrNotExist) && hasPath:
+		return newTransferUserError(fmt.Sprintf("no such file or directory: %s", pathErr.Path))
+	}
+	return err
+}
+
+func isTransferConnectionError(err error) bool {
+	if errors.Is(err, sftp.ErrSSHFxConnectionLost) || errors.Is(err, sftp.ErrSSHFxNoConnection) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// transferFile is an open file on either side of a transfer.
+type transferFile interface {
+	io.Reader
+	io.Writer
+	io.Seeker
+	io.Closer
+}
+
+// transferFS abstracts the local filesystem and the remote SFTP filesystem so
+// one copy algorithm serves both directions.
+type transferFS interface {
+	Stat(name string) (os.FileInfo, error)
+	// ReadDir lists a directory without following symlinks.
+	ReadDir(name string) ([]os.FileInfo, error)
+	MkdirAll(name string) error
+	Chmod(name string, mode os.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+	Open(name string) (transferFile, error)
+	OpenFile(name string, flag int) (transferFile, error)
+	// Rename replaces newname if it exists.
+	Rename(oldname, newname string) error
+	Remove(name string) error
+	Join(elem ...string) string
+	Dir(name string) string
+	Base(name string) string
+	// ResumeOffset returns the offset from which a partial file of the given
+	// size can safely be continued.
+	ResumeOffset(size int64) int64
+	// Side is "local" or "remote", for error messages.
+	Side() string
+}
+
+type localFS struct{}
+
+func (localFS) Stat(name string) (os.FileInfo, error) { return os.Stat(name) }
+
+func (localFS) ReadDir(name string) ([]os.FileInfo, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdir(-1)
+}
+
+func (localFS) MkdirAll(name string) error { return os.MkdirAll(name, 0755) }
+
+func (localFS) Chmod(name string, mode os.FileMode) error { return os.Chmod(name, mode) }
+
+func (localFS) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+func (localFS) Open(name string) (transferFile, error) { return os.Open(name) }
+
+func (localFS) OpenFile(name string, flag int) (transferFile, error) {
+	return os.OpenFile(name, flag, 0644)
+}
+
+func (localFS) Rename(oldname, newname string) error { return os.Rename(oldname, newname) }
+func (localFS) Remove(name string) error             { return os.Remove(name) }
+func (localFS) Join(elem ...string) string           { return filepath.Join(elem...) }
+func (localFS) Dir(name string) string               { return filepath.Dir(name) }
+func (localFS) Base(name string) string              { return filepath.Base(name) }
+
+// ResumeOffset trusts the whole partial file: local writes are sequential.
+func (localFS) ResumeOffset(size int64) int64 { return size }
+func (localFS) Side() string                  { return "local" }
+
+type remoteFS struct {
+	c *sftp.Client
+}
+
+// remotePathError attaches the path to errors from pkg/sftp, which returns
+// bare os.ErrNotExist/os.ErrPermission for status codes.
+func remotePathError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &os.PathError{Op: op, Path: name, Err: err}
+}
+
+func (r remoteFS) Stat(name string) (os.FileInfo, error) {
+	info, err := r.c.Stat(name)
+	return info, remotePathError("stat", name, err)
+}
+
+func (r remoteFS) ReadDir(name string) ([]os.FileInfo, error) {
+	infos, err := r.c.ReadDir(name)
+	return infos, remotePathError("readdir", name, err)
+}
+
+func (r remoteFS) MkdirAll(name string) error {
+	return remotePathError("mkdir", name, r.c.MkdirAll(name))
+}
+
+func (r remoteFS) Chmod(name string, mode os.FileMode) error {
+	return remotePathError("chmod", name, r.c.Chmod(name, mode))
+}
+
+func (r remoteFS) Chtimes(name string, atime, mtime time.Time) error {
+	return remotePathError("chtimes", name, r.c.Chtimes(name, atime, mtime))
+}
+
+func (r remoteFS) Open(name string) (transferFile, error) {
+	f, err := r.c.Open(name)
+	if err != nil {
+		return nil, remotePathError("open", name, err)
+	}
+	return f, nil
+}
+
+func (r remoteFS) OpenFile(name string, flag int) (transferFile, error) {
+	f, err := r.c.OpenFile(name, flag)
+	if err != nil {
+		return nil, remotePathError("open", name, err)
+	}
+	return f, nil
+}
+
+// Rename prefers the posix-rename extension, which replaces the destination
+// atomically; plain SFTP rename fails when the destination exists.
+func (r remoteFS) Rename(oldname, newname string) error {
+	if err := r.c.PosixRename(oldname, newname); err == nil {
+		return nil
+	}
+	if err := r.c.Remove(newname); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return remotePathError("rename", newname, err)
+	}
+	return remotePathError("rename", newname, r.c.Rename(oldname, newname))
+}
+
+func (r remoteFS) Remove(name string) error {
+	return remotePathError("remove", name, r.c.Remove(name))
+}
+
+func (remoteFS) Join(elem ...string) string { return path.Join(elem...) }
+func (remoteFS) Dir(name string) string     { return path.Dir(name) }
+func (remoteFS) Base(name string) string    { return path.Base(name) }
+
+// ResumeOffset restarts the last upload chunk, the only one that may contain
+// gaps from out-of-order concurrent writes.
+func (remoteFS) ResumeOffset(size int64) int64 {
+	if size <= 0 {
+		return 0
+	}
+	return (size - 1) / uploadChunkSize * uploadChunkSize
+}
+
+func (remoteFS) Side() string { return "remote" }
+
+// transferEntry is a file or directory to copy, relative to the source root.
+type transferEntry struct {
+	rel  string // slash-separated; "" for the root itself
+	info os.FileInfo
+}
+
+// planTransfer lists everything under root, directories before their
+// contents. Symlinks to files are followed; symlinks to directories, special
+// files and leftovers of interrupted transfers are skipped.
+func planTransfer(src transferFS, root string) ([]transferEntry, error) {
+	info, err := src.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	entries := []transferEntry{{info: info}}
+	if !info.IsDir() {
+		return entries, nil
+	}
+	children, err := walkTransferTree(src, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, children...), nil
+}
+
+// walkTransferTree lists the contents of the directory root the way
+// planTransfer does, sorted by name with directories before their contents.
+// Paths matched by ignore are skipped, and ignored directories are not
+// descended into.
+func walkTransferTree(src transferFS, root string, ignore *IgnoreMatcher) ([]transferEntry, error) {
+	var entries []transferEntry
+	var walk func(dir, rel string) error
+	walk = func(dir, rel string) error {
+		children, err := src.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].Name() < children[j].Name() })
+		for _, child := range children {
+			if isPartialArtifact(child.Name()) {
+				continue
+			}
+			childPath := src.Join(dir, child.Name())
+			childRel := path.Join(rel, child.Name())
+			info := child
+			if info.Mode()&os.ModeSymlink != 0 {
+				target, err := src.Stat(childPath)
+				if err != nil || target.IsDir() {
+					continue
+				}
+				info = target
+			}
+			if ignore.Match(childRel, info.IsDir()) {
+				continue
+			}
+			switch {
+			case info.IsDir():
+				entries = append(entries, transferEntry{rel: childRel, info: info})
+				if err := walk(childPath, childRel); err != nil {
+					return err
+				}
+			case info.Mode().IsRegular():
+				entries = append(entries, transferEntry{rel: childRel, info: info})
+			}
+		}
+		return nil
+	}
+	if err := walk(root, ""); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// isPartialArtifact reports whether name is a partial file or sidecar left
+// behind by an interrupted transfer.
+func isPartialArtifact(name string) bool {
+	return strings.HasPrefix(name, ".") &&
+		(strings.HasSuffix(name, partialSuffix) || strings.HasSuffix(name, partialSuffix+partialSourceSuffix))
+}
+
+// resolveTransferTarget applies scp -r destination semantics.
+func resolveTransferTarget(dst transferFS, dstPath, srcName string) (string, error) {
+	info, err := dst.Stat(dstPath)
+	if err == nil {
+		if info.IsDir() {
+			return dst.Join(dstPath, srcName), nil
+		}
+		return dstPath, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	if strings.HasSuffix(dstPath, "/") || strings.HasSuffix(dstPath, string(filepath.Separator)) {
+		if err := dst.MkdirAll(dstPath); err != nil {
+			return "", err
+		}
+		return dst.Join(dstPath, srcName), nil
+	}
+
+	parent := dst.Dir(dstPath)
+	if info, err := dst.Stat(parent); err != nil || !info.IsDir() {
+		return "", newTransferUserError(fmt.Sprintf("%s directory does not exist: %s", dst.Side(), parent))
+	}
+	return dstPath, nil
+}
+
+// fileCopier copies single files from src to dst.
+type fileCopier struct {
+	ctx      context.Context
+	src, dst transferFS
+	// overwrite copies files even when the destination already matches by
+	// size and mtime, for callers that have already decided what changed.
+	overwrite bool
+}
+
+// copyFile copies one regular file through a partial file next to dstName,
+// skipping it when dstName already matches (unless c.overwrite is set) and
+// resuming an earlier partial. onBytes receives every byte of the file that
+// is accounted for, with sent false for data already at the destination. It
+// reports whether any data had to be copied.
+func (c *fileCopier) copyFile(srcName, dstName string, info os.FileInfo, onBytes func(n int64, sent bool)) (bool, error) {
+	size := info.Size()
+	if !c.overwrite {
+		if existing, err := c.dst.Stat(dstName); err == nil && existing.Mode().IsRegular() &&
+			existing.Size() == size && existing.ModTime().Unix() == info.ModTime().Unix() {
+			onBytes(size, false)
+			return false, nil
+		}
+	}
+
+	partial := c.dst.Join(c.dst.Dir(dstName), "."+c.dst.Base(dstName)+partialSuffix)
+	sidecar := partial + partialSourceSuffix
+	source := partialSourceID(info)
+	var offset int64
+	if p, err := c.dst.Stat(partial); err == nil && p.Mode().IsRegular() && p.Size() <= size &&
+		readPartialSourceID(c.dst, sidecar) == source {
+		offset = c.dst.ResumeOffset(p.Size())
+	}
+	if offset == 0 {
+		if err := writePartialSourceID(c.dst, sidecar, source); err != nil {
+			return false, err
+		}
+	}
+
+	in, err := c.src.Open(srcName)
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	flag := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flag |= os.O_TRUNC
+	}
+	out, err := c.dst.OpenFile(partial, flag)
+	if err != nil {
+		return false, err
+	}
+	if offset > 0 {
+		if _, err := in.Seek(offset, io.SeekStart); err != nil {
+			out.Close()
+			return false, err
+		}
+		if _, err := out.Seek(offset, io.SeekStart); err != nil {
+			out.Close()
+			return false, err
+		}
+		onBytes(offset, false)
+	}
+
+	if err := copyFileData(c.ctx, out, in, size-offset, func(n int64) { onBytes(n, true) }); err != nil {
+		out.Close()
+		return false, err
+	}
+	if err := out.Close(); err != nil {
+		return false, err
+	}
+
+	if err := c.dst.Chmod(partial, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := c.dst.Chtimes(partial, info.ModTime(), info.ModTime()); err != nil {
+		return false, err
+	}
+	if err := c.dst.Rename(partial, dstName); err != nil {
+		return false, err
+	}
+	return true, c.dst.Remove(sidecar)
+}
+
+// partialSourceID identifies the version of a source file a partial holds.
+func partialSourceID(info os.FileInfo) string {
+	return fmt.Sprintf("%d %d", info.Size(), info.ModTime().Unix())
+}
+
+// readPartialSourceID returns the source ID recorded for a partial, or ""
+// if there is none.
+func readPartialSourceID(fsys transferFS, sidecar string) string {
+	f, err := fsys.Open(sidecar)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, 256))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func writePartialSourceID(fsys transferFS, sidecar, id string) error {
+	f, err := fsys.OpenFile(sidecar, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, id+"\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyFileData copies n bytes from src to dst, using pipelined SFTP requests
+// when either side is remote.
+func copyFileData(ctx context.Context, dst, src transferFile, n int64, onBytes func(int64)) error {
+	if f, ok := dst.(*sftp.File); ok {
+		r := &progressReader{ctx: ctx, r: src, onBytes: onBytes}
+		for n > 0 {
+			chunk := min(n, uploadChunkSize)
+			written, err := f.ReadFromWithConcurrency(io.LimitReader(r, chunk), 0)
+			if err != nil {
+				return err
+			}
+			if written < chunk {
+				return fmt.Errorf("file changed during transfer: %w", io.ErrUnexpectedEOF)
+			}
+			n -= chunk
+		}
+		return nil
+	}
+
+	w := &progressWriter{ctx: ctx, w: dst, onBytes: onBytes}
+	var written int64
+	var err error
+	if f, ok := src.(*sftp.File); ok {
+		written, err = f.WriteTo(w)
+	} else {
+		written, err = io.Copy(w, io.LimitReader(src, n))
+	}
+	if err != nil {
+		return err
+	}
+	if written < n {
+		return fmt.Errorf("file changed during transfer: %w", io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// progressReader reports bytes read and stops once ctx is done.
+type progressReader struct {
+	ctx     context.Context
+	r       io.Reader
+	onBytes func(int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.onBytes(int64(n))
+	}
+	return n, err
+}
+
+// progressWriter reports bytes written and stops once ctx is done.
+type progressWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	onBytes func(int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.onBytes(int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,407 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// DefaultTransferWorkers is how many files a transfer copies at once unless
+// the caller asks otherwise.
+const DefaultTransferWorkers = 4
+
+// MaxTransferWorkers bounds the worker pool. Each worker opens its own SFTP
+// session and OpenSSH allows 10 sessions per connection by default.
+const MaxTransferWorkers = 8
+
+// Transfer outcomes recorded in TransferFileResult.Status.
+const (
+	TransferCopied  = "copied"
+	TransferSkipped = "skipped" // already up to date at the destination
+	TransferFailed  = "failed"
+)
+
+// TransferFileResult records what happened to one file of a transfer.
+type TransferFileResult struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Bytes       int64  `json:"bytes"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+
+	err error
+}
+
+// TransferReport summarises a transfer of one or more files.
+type TransferReport struct {
+	Files []TransferFileResult
+	// Sent is the data actually copied, excluding files that were already up
+	// to date and the resumed part of interrupted files.
+	Sent     int64
+	Duration time.Duration
+}
+
+// Count returns the number of files with the given status.
+func (r *TransferReport) Count(status string) int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns nil if every file was transferred. A failed single-file
+// transfer returns that file's error; otherwise the error counts the failures
+// and wraps the first one.
+func (r *TransferReport) Err() error {
+	var failed []TransferFileResult
+	for _, f := range r.Files {
+		if f.Status == TransferFailed {
+			failed = append(failed, f)
+		}
+	}
+	switch {
+	case len(failed) == 0:
+		return nil
+	case len(r.Files) == 1:
+		return failed[0].err
+	}
+	return fmt.Errorf("%d of %d files failed to transfer (%s: %w)", len(failed), len(r.Files), failed[0].Source, failed[0].err)
+}
+
+// transferRecorder collects per-file results across the attempts of a
+// transfer, so a file copied before a reconnect is still reported as copied
+// when the retry finds it up to date.
+type transferRecorder struct {
+	mu      sync.Mutex
+	started time.Time
+	sent    int64
+	results map[string]*TransferFileResult
+	order   []string
+}
+
+func newTransferRecorder() *transferRecorder {
+	return &transferRecorder{started: time.Now(), results: make(map[string]*TransferFileResult)}
+}
+
+func (r *transferRecorder) addSent(n int64) {
+	r.mu.Lock()
+	r.sent += n
+	r.mu.Unlock()
+}
+
+func (r *transferRecorder) record(f copyTask, copied bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result, ok := r.results[f.dst]
+	if !ok {
+		result = &TransferFileResult{Source: f.src, Destination: f.dst}
+		r.results[f.dst] = result
+		r.order = append(r.order, f.dst)
+	}
+	result.Bytes = f.info.Size()
+	result.Error, result.err = "", nil
+	switch {
+	case err != nil:
+		result.Status = TransferFailed
+		result.Error, result.err = err.Error(), err
+	case copied:
+		result.Status = TransferCopied
+	case result.Status != TransferCopied:
+		result.Status = TransferSkipped
+	}
+}
+
+func (r *transferRecorder) report() *TransferReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := &TransferReport{Sent: r.sent, Duration: time.Since(r.started)}
+	for _, dst := range r.order {
+		report.Files = append(report.Files, *r.results[dst])
+	}
+	return report
+}
+
+// transferEndpoint opens a filesystem for one side of a transfer along with
+// a function that releases it.
+type transferEndpoint func(ctx context.Context) (transferFS, func(), error)
+
+func localEndpoint(context.Context) (transferFS, func(), error) {
+	return localFS{}, func() {}, nil
+}
+
+// sftpSessionEndpoint opens a new SFTP session on client for every caller,
+// so each worker gets its own channel and flow-control window.
+func sftpSessionEndpoint(client *SSHClient) transferEndpoint {
+	return func(ctx context.Context) (transferFS, func(), error) {
+		sc, closeSession, err := openSFTPSession(ctx, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		return remoteFS{sc}, closeSession, nil
+	}
+}
+
+// sftpClientEndpoint shares one established SFTP session between callers.
+func sftpClientEndpoint(sc *sftp.Client) transferEndpoint {
+	return func(context.Context) (transferFS, func(), error) {
+		return remoteFS{sc}, func() {}, nil
+	}
+}
+
+// copyTask is one file of a planned copy.
+type copyTask struct {
+	src, dst string
+	display  string // slash-separated path shown in progress
+	info     os.FileInfo
+}
+
+// copyPlan is everything a parallelCopy call creates at the destination.
+type copyPlan struct {
+	files []copyTask
+	dirs  []transferEntry // rel holds the destination path; parents first
+}
+
+// planCopy lists the files and directories to copy for each source with
+// SFTPTransfer's scp -r semantics. Several sources require the destination to
+// be a directory, and may not map two files onto the same destination path.
+func planCopy(src, dst transferFS, sources []string, dest string) (*copyPlan, error) {
+	if len(sources) > 1 {
+		info, err := dst.Stat(dest)
+		switch {
+		case err == nil && !info.IsDir():
+			return nil, newTransferUserError(fmt.Sprintf("%s destination must be a directory when copying several sources: %s", dst.Side(), dest))
+		case errors.Is(err, os.ErrNotExist) && !strings.HasSuffix(dest, "/") && !strings.HasSuffix(dest, string(filepath.Separator)):
+			return nil, newTransferUserError(fmt.Sprintf("%s directory does not exist: %s", dst.Side(), dest))
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	plan := &copyPlan{}
+	seen := make(map[string]string)
+	for _, source := range sources {
+		entries, err := planTransfer(src, source)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, newTransferUserError(fmt.Sprintf("%s file not found: %s", src.Side(), source))
+			}
+			return nil, err
+		}
+		name := src.Base(source)
+		target, err := resolveTransferTarget(dst, dest, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			srcName, dstName := source, target
+			if e.rel != "" {
+				srcName, dstName = src.Join(source, e.rel), dst.Join(target, e.rel)
+			}
+			if e.info.IsDir() {
+				plan.dirs = append(plan.dirs, transferEntry{rel: dstName, info: e.info})
+				continue
+			}
+			if other, ok := seen[dstName]; ok {
+				return nil, newTransferUserError(fmt.Sprintf("%s and %s would both be copied to %s", other, srcName, dstName))
+			}
+			seen[dstName] = srcName
+			plan.files = append(plan.files, copyTask{src: srcName, dst: dstName, display: path.Join(name, e.rel), info: e.info})
+		}
+	}
+	return plan, nil
+}
+
+// parallelCopy copies sources on src to dest on dst, running up to workers
+// file copies at once, each worker on its own endpoint sessions. A file that
+// fails is recorded in rec and does not stop the others; cancellation and a
+// lost connection stop the whole copy and are returned.
+func parallelCopy(ctx context.Context, src, dst transferEndpoint, sources []string, dest string, workers int,
+	report TransferProgressFunc, rec *transferRecorder) error {
+	return classifyTransferError(ctx, runParallelCopy(ctx, src, dst, sources, dest, workers, report, rec))
+}
+
+func runParallelCopy(ctx context.Context, src, dst transferEndpoint, sources []string, dest string, workers int,
+	report TransferProgressFunc, rec *transferRecorder) error {
+	srcFS, closeSrc, err := src(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+	dstFS, closeDst, err := dst(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDst()
+
+	plan, err := planCopy(srcFS, dstFS, sources, dest)
+	if err != nil {
+		return err
+	}
+	for _, d := range plan.dirs {
+		if err := dstFS.MkdirAll(d.rel); err != nil {
+			return err
+		}
+	}
+
+	var totalBytes int64
+	for _, f := range plan.files {
+		totalBytes += f.info.Size()
+	}
+	tracker := newProgressTracker(len(plan.files), totalBytes, report)
+
+	copyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var fatalMu sync.Mutex
+	var fatal error
+	stop := func(err error) {
+		fatalMu.Lock()
+		if fatal == nil {
+			fatal = err
+		}
+		fatalMu.Unlock()
+		cancel()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(workers, 1), len(plan.files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wsrc, closeSrc, err := src(copyCtx)
+			if err != nil {
+				stop(err)
+				return
+			}
+			defer closeSrc()
+			wdst, closeDst, err := dst(copyCtx)
+			if err != nil {
+				stop(err)
+				return
+			}
+			defer closeDst()
+
+			c := &fileCopier{ctx: copyCtx, src: wsrc, dst: wdst}
+			for i := range jobs {
+				f := plan.files[i]
+				tracker.start(i, f.display, f.info.Size())
+				copied, err := c.copyFile(f.src, f.dst, f.info, func(n int64, sent bool) { tracker.add(i, n, sent) })
+				rec.addSent(tracker.finish(i, err == nil))
+				if err != nil && (copyCtx.Err() != nil || isTransferConnectionError(err)) {
+					stop(err)
+					continue
+				}
+				if err != nil {
+					err = classifyTransferError(copyCtx, err)
+				}
+				rec.record(f, copied, err)
+			}
+		}()
+	}
+
+feed:
+	for i := range plan.files {
+		select {
+		case jobs <- i:
+		case <-copyCtx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if fatal != nil {
+		return fatal
+	}
+
+	// Directory modes and mtimes are applied last: writing files into a
+	// directory changes its mtime, and a read-only mode would block the writes.
+	for i := len(plan.dirs) - 1; i >= 0; i-- {
+		d := plan.dirs[i]
+		if err := dstFS.Chmod(d.rel, d.info.Mode().Perm()); err != nil {
+			return err
+		}
+		if err := dstFS.Chtimes(d.rel, d.info.ModTime(), d.info.ModTime()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progressTracker aggregates progress across concurrent file copies. Reports
+// are made under the lock so consumers see them in order.
+type progressTracker struct {
+	mu     sync.Mutex
+	status TransferProgress
+	active map[int]*FileTransferProgress
+	report TransferProgressFunc
+}
+
+func newProgressTracker(totalFiles int, totalBytes int64, report TransferProgressFunc) *progressTracker {
+	return &progressTracker{
+		status: TransferProgress{TotalFiles: totalFiles, TotalBytes: totalBytes},
+		active: make(map[int]*FileTransferProgress),
+		report: report,
+	}
+}
+
+func (t *progressTracker) start(id int, path string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.Path = path
+	t.active[id] = &FileTransferProgress{Path: path, TotalBytes: size, Started: time.Now()}
+}
+
+func (t *progressTracker) add(id int, n int64, sent bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f := t.active[id]
+	f.Bytes += n
+	t.status.Bytes += n
+	if sent {
+		f.Sent += n
+		t.status.Sent += n
+	}
+	t.reportLocked()
+}
+
+// finish ends a file's copy and returns how many of its bytes were sent.
+func (t *progressTracker) finish(id int, ok bool) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f := t.active[id]
+	delete(t.active, id)
+	if ok {
+		t.status.Files++
+	} else {
+		t.status.Failed++
+	}
+	t.reportLocked()
+	return f.Sent
+}
+
+func (t *progressTracker) reportLocked() {
+	if t.report == nil {
+		return
+	}
+	p := t.status
+	p.Active = make([]FileTransferProgress, 0, len(t.active))
+	for _, f := range t.active {
+		p.Active = append(p.Active, *f)
+	}
+	sort.Slice(p.Active, func(i, j int) bool { return p.Active[i].Started.Before(p.Active[j].Started) })
+	t.report(p)
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelCopyUploadsSeveralSources(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	var sources []string
+	for i := range 6 {
+		name := filepath.Join(localDir, fmt.Sprintf("shard-%d.tar", i))
+		writeTestFile(t, name, bytes.Repeat([]byte{byte('a' + i)}, 200_000), 0644, time.Now())
+		sources = append(sources, name)
+	}
+	writeTestFile(t, filepath.Join(localDir, "meta", "index.json"), []byte("{}"), 0644, time.Now())
+	sources = append(sources, filepath.Join(localDir, "meta"))
+	require.NoError(t, os.Mkdir(filepath.Join(remoteDir, "data"), 0755))
+
+	var mu sync.Mutex
+	maxActive := 0
+	var last TransferProgress
+	rec := newTransferRecorder()
+	err := copyEndpoints(context.Background(), localEndpoint, sftpClientEndpoint(sc), sources, "data", true, 3,
+		func(p TransferProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			maxActive = max(maxActive, len(p.Active))
+			last = p
+		}, rec)
+	require.NoError(t, err)
+
+	for i := range 6 {
+		got, err := os.ReadFile(filepath.Join(remoteDir, "data", fmt.Sprintf("shard-%d.tar", i)))
+		require.NoError(t, err)
+		assert.Len(t, got, 200_000)
+	}
+	assert.FileExists(t, filepath.Join(remoteDir, "data", "meta", "index.json"))
+
+	assert.LessOrEqual(t, maxActive, 3)
+	assert.Equal(t, 7, last.Files)
+	assert.Equal(t, last.TotalBytes, last.Bytes)
+	assert.Empty(t, last.Active)
+
+	report := rec.report()
+	assert.Len(t, report.Files, 7)
+	assert.Equal(t, 7, report.Count(TransferCopied))
+	assert.Equal(t, int64(6*200_000+2), report.Sent)
+	assert.NoError(t, report.Err())
+
+	// A second run finds everything up to date.
+	rec = newTransferRecorder()
+	require.NoError(t, copyEndpoints(context.Background(), localEndpoint, sftpClientEndpoint(sc), sources, "data", true, 3, nil, rec))
+	assert.Equal(t, 7, rec.report().Count(TransferSkipped))
+	assert.Zero(t, rec.report().Sent)
+}
+
+func TestParallelCopyContinuesPastFailedFiles(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("relies on file permissions")
+	}
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	writeTestFile(t, filepath.Join(localDir, "a.txt"), []byte("a"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(localDir, "secret.txt"), []byte("s"), 0000, time.Now())
+	writeTestFile(t, filepath.Join(localDir, "b.txt"), []byte("b"), 0644, time.Now())
+
+	rec := newTransferRecorder()
+	err := copyEndpoints(context.Background(), localEndpoint, sftpClientEndpoint(sc), []string{localDir}, ".", true, 2, nil, rec)
+	require.NoError(t, err)
+
+	report := rec.report()
+	assert.Equal(t, 2, report.Count(TransferCopied))
+	assert.Equal(t, 1, report.Count(TransferFailed))
+	err = report.Err()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 3 files failed")
+	assert.ErrorIs(t, err, ErrTransferUser)
+	assert.FileExists(t, filepath.Join(remoteDir, filepath.Base(localDir), "b.txt"))
+}
+
+func TestPlanCopyRejectsAmbiguousDestinations(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+	writeTestFile(t, filepath.Join(localDir, "x", "model.pt"), []byte("x"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(localDir, "y", "model.pt"), []byte("y"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(remoteDir, "file"), []byte("f"), 0644, time.Now())
+
+	sources := []string{filepath.Join(localDir, "x", "model.pt"), filepath.Join(localDir, "y", "model.pt")}
+	tests := []struct{ dest, want string }{
+		{"missing", "remote directory does not exist"},
+		{"file", "must be a directory when copying several sources"},
+		{"out/", "would both be copied to"},
+	}
+	for _, tt := range tests {
+		_, err := planCopy(localFS{}, remoteFS{sc}, sources, tt.dest)
+		require.Error(t, err, tt.dest)
+		assert.True(t, errors.Is(err, ErrTransferUser), tt.dest)
+		assert.Contains(t, err.Error(), tt.want, tt.dest)
+	}
+}
+
+func TestTransferRecorderKeepsCopiedAcrossAttempts(t *testing.T) {
+	info := fakeFileInfo{size: 10}
+	rec := newTransferRecorder()
+	task := copyTask{src: "a", dst: "b", info: info}
+
+	rec.record(task, true, nil)
+	// The retry after a reconnect finds the file up to date.
+	rec.record(task, false, nil)
+	rec.record(copyTask{src: "c", dst: "d", info: info}, false, errors.New("boom"))
+
+	report := rec.report()
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, TransferCopied, report.Files[0].Status)
+	assert.Equal(t, TransferFailed, report.Files[1].Status)
+	assert.Equal(t, "boom", report.Files[1].Error)
+	assert.EqualError(t, report.Err(), "1 of 2 files failed to transfer (c: boom)")
+}
+
+type fakeFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+func (f fakeFileInfo) Size() int64 { return f.size }
+
+func TestCopyBetweenRemotes(t *testing.T) {
+	fromDir := t.TempDir()
+	toDir := t.TempDir()
+	from := newTestSFTPClient(t, fromDir)
+	to := newTestSFTPClient(t, toDir)
+
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	writeTestFile(t, filepath.Join(fromDir, "ckpt", "model.pt"), bytes.Repeat([]byte("w"), 300_000), 0600, mtime)
+	writeTestFile(t, filepath.Join(fromDir, "ckpt", "opt", "state.pt"), []byte("state"), 0644, mtime)
+
+	var last TransferProgress
+	rec := newTransferRecorder()
+	err := copyBetween(context.Background(), sftpClientEndpoint(from), sftpClientEndpoint(to), []string{"~/ckpt"}, "~/",
+		2, func(p TransferProgress) { last = p }, rec)
+	require.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(toDir, "ckpt", "model.pt"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(300_000), info.Size())
+	assert.True(t, info.ModTime().Equal(mtime))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	got, err := os.ReadFile(filepath.Join(toDir, "ckpt", "opt", "state.pt"))
+	require.NoError(t, err)
+	assert.Equal(t, "state", string(got))
+
+	assert.Equal(t, 2, last.Files)
+	assert.Equal(t, int64(300_005), last.Bytes)
+	assert.Equal(t, 2, rec.report().Count(TransferCopied))
+
+	err = copyBetween(context.Background(), sftpClientEndpoint(from), sftpClientEndpoint(to), []string{"missing"}, ".", 2, nil, newTransferRecorder())
+	assert.True(t, errors.Is(err, ErrTransferUser))
+	assert.Contains(t, err.Error(), "remote file not found")
+}
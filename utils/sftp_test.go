@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSFTPClient connects an SFTP client to an in-process server rooted
+// at workDir over a pair of pipes.
+func newTestSFTPClient(t *testing.T, workDir string) *sftp.Client {
+	t.Helper()
+	clientRead, serverWrite := io.Pipe()
+	serverRead, clientWrite := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverRead, serverWrite}, sftp.WithServerWorkingDirectory(workDir))
+	require.NoError(t, err)
+	go func() { _ = server.Serve() }()
+
+	client, err := sftp.NewClientPipe(clientRead, clientWrite)
+	require.NoError(t, err)
+	// The server must go first: closing it ends the client's receive loop,
+	// which client.Close waits for.
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return client
+}
+
+func writeTestFile(t *testing.T, name string, data []byte, mode os.FileMode, mtime time.Time) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+	require.NoError(t, os.WriteFile(name, data, mode))
+	require.NoError(t, os.Chmod(name, mode))
+	require.NoError(t, os.Chtimes(name, mtime, mtime))
+}
+
+func TestTransferSFTPUploadDirectory(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	big := bytes.Repeat([]byte("0123456789"), 100_000)
+	writeTestFile(t, filepath.Join(localDir, "project", "run.sh"), []byte("#!/bin/sh\n"), 0755, mtime)
+	writeTestFile(t, filepath.Join(localDir, "project", "data", "big.bin"), big, 0644, mtime)
+
+	var last TransferProgress
+	err := transferSFTP(context.Background(), sc, filepath.Join(localDir, "project"), "~/", true, func(p TransferProgress) {
+		last = p
+	})
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filepath.Join(remoteDir, "project", "data", "big.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, big, got)
+
+	info, err := os.Stat(filepath.Join(remoteDir, "project", "run.sh"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(mtime))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+
+	assert.Equal(t, 2, last.Files)
+	assert.Equal(t, 2, last.TotalFiles)
+	assert.Equal(t, int64(len(big)+10), last.TotalBytes)
+	assert.Equal(t, last.TotalBytes, last.Bytes)
+
+	partials, err := filepath.Glob(filepath.Join(remoteDir, "project", "data", "*"+partialSuffix))
+	require.NoError(t, err)
+	assert.Empty(t, partials)
+}
+
+func TestTransferSFTPDownloadRenamesToDestination(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	writeTestFile(t, filepath.Join(remoteDir, "results.csv"), []byte("a,b\n"), 0600, time.Now())
+
+	dest := filepath.Join(localDir, "out.csv")
+	require.NoError(t, transferSFTP(context.Background(), sc, dest, "results.csv", false, nil))
+
+	got, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "a,b\n", string(got))
+}
+
+func TestTransferSFTPResumesPartialDownload(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	data := bytes.Repeat([]byte("abcdefgh"), 50_000)
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeTestFile(t, filepath.Join(remoteDir, "model.bin"), data, 0644, mtime)
+	// A previous attempt got halfway.
+	half := len(data) / 2
+	partial := filepath.Join(localDir, ".model.bin"+partialSuffix)
+	require.NoError(t, os.WriteFile(partial, data[:half], 0644))
+	require.NoError(t, os.WriteFile(partial+partialSourceSuffix, []byte(fmt.Sprintf("%d %d\n", len(data), mtime.Unix())), 0644))
+
+	var first *TransferProgress
+	err := transferSFTP(context.Background(), sc, localDir, "model.bin", false, func(p TransferProgress) {
+		if first == nil {
+			first = &p
+		}
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, first)
+	assert.Equal(t, int64(half), first.Bytes)
+	got, err := os.ReadFile(filepath.Join(localDir, "model.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.NoFileExists(t, partial+partialSourceSuffix)
+}
+
+func TestTransferSFTPDiscardsPartialOfChangedSource(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	oldData := bytes.Repeat([]byte("old-"), 50_000)
+	newData := bytes.Repeat([]byte("new-"), 50_000)
+	src := filepath.Join(remoteDir, "ckpt.pt")
+	writeTestFile(t, src, oldData, 0644, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	// The first attempt is cancelled partway through.
+	ctx, cancel := context.WithCancel(context.Background())
+	err := transferSFTP(ctx, sc, localDir, "ckpt.pt", false, func(p TransferProgress) {
+		if p.Bytes > 0 {
+			cancel()
+		}
+	})
+	require.ErrorIs(t, err, ErrTransferCancelled)
+	partial, err := os.Stat(filepath.Join(localDir, ".ckpt.pt"+partialSuffix))
+	require.NoError(t, err)
+	require.Greater(t, partial.Size(), int64(0))
+
+	// The checkpoint is rewritten (same size, new mtime) before the retry.
+	writeTestFile(t, src, newData, 0644, time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, transferSFTP(context.Background(), sc, localDir, "ckpt.pt", false, nil))
+
+	got, err := os.ReadFile(filepath.Join(localDir, "ckpt.pt"))
+	require.NoError(t, err)
+	assert.Equal(t, newData, got)
+}
+
+func TestTransferSFTPSkipsUpToDateFiles(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	mtime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeTestFile(t, filepath.Join(localDir, "a.txt"), []byte("local"), 0644, mtime)
+	// Same size and mtime: treated as already transferred.
+	writeTestFile(t, filepath.Join(remoteDir, "a.txt"), []byte("other"), 0644, mtime)
+
+	require.NoError(t, transferSFTP(context.Background(), sc, filepath.Join(localDir, "a.txt"), ".", true, nil))
+
+	got, err := os.ReadFile(filepath.Join(remoteDir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "other", string(got))
+}
+
+func TestTransferSFTPUserErrors(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+	writeTestFile(t, filepath.Join(localDir, "a.txt"), []byte("x"), 0644, time.Now())
+
+	err := transferSFTP(context.Background(), sc, localDir, "missing.txt", false, nil)
+	assert.True(t, errors.Is(err, ErrTransferUser))
+	assert.Contains(t, err.Error(), "remote file not found")
+
+	err = transferSFTP(context.Background(), sc, filepath.Join(localDir, "missing.txt"), ".", true, nil)
+	assert.True(t, errors.Is(err, ErrTransferUser))
+	assert.Contains(t, err.Error(), "local file not found")
+
+	err = transferSFTP(context.Background(), sc, filepath.Join(localDir, "a.txt"), "no/such/dir/a.txt", true, nil)
+	assert.True(t, errors.Is(err, ErrTransferUser))
+	assert.Contains(t, err.Error(), "remote directory does not exist")
+}
+
+func TestTransferSFTPCancelled(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+	writeTestFile(t, filepath.Join(localDir, "a.bin"), make([]byte, 1<<20), 0644, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := transferSFTP(ctx, sc, filepath.Join(localDir, "a.bin"), ".", true, func(TransferProgress) { cancel() })
+	assert.ErrorIs(t, err, ErrTransferCancelled)
+}
+
+func TestRemoteResumeOffset(t *testing.T) {
+	var r remoteFS
+	assert.Equal(t, int64(0), r.ResumeOffset(0))
+	assert.Equal(t, int64(0), r.ResumeOffset(uploadChunkSize))
+	assert.Equal(t, int64(uploadChunkSize), r.ResumeOffset(uploadChunkSize+1))
+	assert.Equal(t, int64(uploadChunkSize), r.ResumeOffset(2*uploadChunkSize))
+}
+
+func TestNormalizeRemotePath(t *testing.T) {
+	tests := map[string]string{
+		"":             ".",
+		"~":            ".",
+		"~/":           "./",
+		"~/data":       "data",
+		"/home/ubuntu": "/home/ubuntu",
+		"./":           "./",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, normalizeRemotePath(in), in)
+	}
+}
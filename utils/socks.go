@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minimal SOCKS5 server (RFC 1928) used by dynamic forwards. Only the
+// "no authentication" method and the CONNECT command are supported, which is
+// what browsers and curl use; the listener is bound to loopback only.
+const (
+	socksVersion = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCmdConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyHostUnreachable     = 0x04
+	socksReplyConnectionRefused   = 0x05
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddrNotSupported    = 0x08
+
+	socksHandshakeTimeout = 30 * time.Second
+)
+
+type socksError struct {
+	reply byte
+	msg   string
+}
+
+func (e *socksError) Error() string { return e.msg }
+
+// serveSOCKS5 handles one client connection: it negotiates the target
+// address, opens it with dial (typically ssh.Client.Dial, so the connection
+// originates from the instance) and relays data until either side closes.
+func serveSOCKS5(conn net.Conn, dial func(network, addr string) (net.Conn, error)) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(socksHandshakeTimeout))
+	target, err := socks5Handshake(conn)
+	if err != nil {
+		var serr *socksError
+		if errors.As(err, &serr) {
+			writeSOCKS5Reply(conn, serr.reply)
+		}
+		return
+	}
+
+	remote, err := dial("tcp", target)
+	if err != nil {
+		reply := byte(socksReplyHostUnreachable)
+		if strings.Contains(strings.ToLower(err.Error()), "refused") {
+			reply = socksReplyConnectionRefused
+		}
+		writeSOCKS5Reply(conn, reply)
+		return
+	}
+	defer remote.Close()
+
+	if err := writeSOCKS5Reply(conn, socksReplySucceeded); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	pipeConns(conn, remote)
+}
+
+// socks5Handshake performs method negotiation and reads a CONNECT request,
+// returning the requested host:port.
+func socks5Handshake(conn io.ReadWriter) (string, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", err
+	}
+	if header[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", err
+	}
+	method := byte(socksMethodNoAcceptable)
+	for _, m := range methods {
+		if m == socksMethodNoAuth {
+			method = socksMethodNoAuth
+			break
+		}
+	}
+	if _, err := conn.Write([]byte{socksVersion, method}); err != nil {
+		return "", err
+	}
+	if method == socksMethodNoAcceptable {
+		return "", fmt.Errorf("client offered no supported authentication method")
+	}
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return "", err
+	}
+	if request[0] != socksVersion {
+		return "", fmt.Errorf("unsupported SOCKS version %d", request[0])
+	}
+
+	var host string
+	switch request[3] {
+	case socksAddrIPv4:
+		addr := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case socksAddrIPv6:
+		addr := make([]byte, net.IPv6len)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		return "", &socksError{socksReplyAddrNotSupported, fmt.Sprintf("unsupported address type %d", request[3])}
+	}
+
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBytes); err != nil {
+		return "", err
+	}
+	port := binary.BigEndian.Uint16(portBytes)
+
+	if request[1] != socksCmdConnect {
+		return "", &socksError{socksReplyCommandNotSupported, fmt.Sprintf("unsupported SOCKS command %d", request[1])}
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+// writeSOCKS5Reply sends a reply with an unspecified bound address; clients
+// only use it for BIND, which is not supported.
+func writeSOCKS5Reply(w io.Writer, reply byte) error {
+	_, err := w.Write([]byte{socksVersion, reply, 0x00, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socks5Connect performs a no-auth SOCKS5 CONNECT to host:port through the
+// proxy at proxyAddr and returns the connection and the server's reply code.
+func socks5Connect(t *testing.T, proxyAddr, host string, port int) (net.Conn, byte) {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", proxyAddr, time.Second)
+	require.NoError(t, err)
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write([]byte{0x05, 0x01, 0x00})
+	require.NoError(t, err)
+	method := make([]byte, 2)
+	_, err = io.ReadFull(conn, method)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x05, 0x00}, method)
+
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		req = append(append(req, 0x01), ip.To4()...)
+	} else {
+		req = append(append(req, 0x03, byte(len(host))), host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	_, err = conn.Write(req)
+	require.NoError(t, err)
+
+	reply := make([]byte, 10)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	_ = conn.SetDeadline(time.Time{})
+	return conn, reply[1]
+}
+
+func TestSOCKS5Handshake(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   []byte
+		want      string
+		wantReply []byte
+		wantErr   string
+	}{
+		{
+			name:    "ipv4",
+			request: []byte{0x05, 0x01, 0x00, 0x05, 0x01, 0x00, 0x01, 127, 0, 0, 1, 0x1f, 0x90},
+			want:    "127.0.0.1:8080",
+		},
+		{
+			name:    "domain",
+			request: append(append([]byte{0x05, 0x02, 0x02, 0x00, 0x05, 0x01, 0x00, 0x03, 9}, "localhost"...), 0x17, 0x70),
+			want:    "localhost:6000",
+		},
+		{
+			name: "ipv6",
+			request: append(append([]byte{0x05, 0x01, 0x00, 0x05, 0x01, 0x00, 0x04},
+				net.ParseIP("::1").To16()...), 0x00, 0x50),
+			want: "[::1]:80",
+		},
+		{
+			name:      "no acceptable method",
+			request:   []byte{0x05, 0x01, 0x02},
+			wantReply: []byte{0x05, 0xff},
+			wantErr:   "no supported authentication method",
+		},
+		{
+			name:      "bind unsupported",
+			request:   []byte{0x05, 0x01, 0x00, 0x05, 0x02, 0x00, 0x01, 127, 0, 0, 1, 0x00, 0x50},
+			wantReply: []byte{0x05, 0x00},
+			wantErr:   "unsupported SOCKS command 2",
+		},
+		{
+			name:    "socks4",
+			request: []byte{0x04, 0x01},
+			wantErr: "unsupported SOCKS version 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			rw := struct {
+				io.Reader
+				io.Writer
+			}{bytes.NewReader(tt.request), &out}
+
+			got, err := socks5Handshake(rw)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				if tt.wantReply != nil {
+					assert.Equal(t, tt.wantReply, out.Bytes())
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, []byte{0x05, 0x00}, out.Bytes())
+		})
+	}
+}
+
+func TestDynamicForwardThroughSSH(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	clientPrivateKey, _, clientPublicKey := generateRSAKeyPair(t)
+	keyFile := filepath.Join(tmpDir, "socks_key")
+	savePrivateKeyToFile(t, clientPrivateKey, keyFile)
+
+	server, serverCleanup := setupSSHTestServer(t, clientPublicKey)
+	defer serverCleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := RobustSSHConnectCtx(ctx, "127.0.0.1", keyFile, server.port, 5)
+	require.NoError(t, err)
+	defer client.Close()
+
+	proxyPort := freePort(t)
+	require.NoError(t, startForward(ctx, client.GetClient(), DynamicForward(proxyPort)))
+	proxyAddr := fmt.Sprintf("127.0.0.1:%d", proxyPort)
+
+	echoHost, echoPortStr, err := net.SplitHostPort(startEchoServer(t))
+	require.NoError(t, err)
+	echoPort, _ := strconv.Atoi(echoPortStr)
+
+	t.Run("connect", func(t *testing.T) {
+		conn, reply := socks5Connect(t, proxyAddr, echoHost, echoPort)
+		defer conn.Close()
+		require.Equal(t, byte(socksReplySucceeded), reply)
+
+		_, err := conn.Write([]byte("via socks\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "via socks\n", line)
+	})
+
+	t.Run("domain target", func(t *testing.T) {
+		conn, reply := socks5Connect(t, proxyAddr, "localhost", echoPort)
+		defer conn.Close()
+		assert.Equal(t, byte(socksReplySucceeded), reply)
+	})
+
+	t.Run("refused target", func(t *testing.T) {
+		conn, reply := socks5Connect(t, proxyAddr, "127.0.0.1", freePort(t))
+		defer conn.Close()
+		assert.NotEqual(t, byte(socksReplySucceeded), reply)
+	})
+}
@@ -514,6 +514,70 @@ func ExecuteSSHCommandStdoutOnly(client *SSHClient, command string) (string, err
 	return string(stdoutData), nil
 }
 
+// ExecuteSSHCommandStreaming runs a command without a PTY and streams remote
+// stdout and stderr to separate writers as output arrives. stdin may be nil.
+// It returns the remote exit status; a non-nil error means no exit status was
+// received (session failure, dropped connection, or ctx cancellation).
+// Commands killed by a signal report 255, matching OpenSSH.
+func ExecuteSSHCommandStreaming(ctx context.Context, client *SSHClient, command string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	if client == nil || client.client == nil {
+		return -1, fmt.Errorf("SSH client is not connected")
+	}
+
+	session, err := client.client.NewSession()
+	if err != nil {
+		return -1, fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	// Attach stdin through a pipe rather than session.Stdin: Wait blocks on
+	// every copy goroutine, and a local stdin that never reaches EOF would
+	// otherwise keep the command from returning after the remote side exits.
+	if stdin != nil {
+		stdinPipe, err := session.StdinPipe()
+		if err != nil {
+			return -1, fmt.Errorf("failed to get stdin pipe: %w", err)
+		}
+		go func() {
+			_, _ = io.Copy(stdinPipe, stdin)
+			stdinPipe.Close()
+		}()
+	}
+
+	if err := session.Start(command); err != nil {
+		return -1, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGTERM)
+		session.Close()
+		<-done
+		return -1, ctx.Err()
+	}
+
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Signal() != "" {
+			return 255, nil
+		}
+		return exitErr.ExitStatus(), nil
+	}
+	return -1, fmt.Errorf("command failed: %w", err)
+}
+
 // UploadFile uploads a single file via SSH stdin pipe
 func UploadFile(client *SSHClient, localPath, remotePath string) error {
 	if client == nil || client.client == nil {
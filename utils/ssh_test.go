@@ -9,10 +9,12 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +31,27 @@ type testSSHServer struct {
 	hostKey  ssh.Signer
 	port     int
 	stop     chan struct{}
+	// exec, when set, handles "exec" requests: it receives the command and
+	// the session channel and returns the exit status to report.
+	exec func(command string, channel ssh.Channel) uint32
+
+	// forwardAddr, when set, is dialed for every direct-tcpip channel in
+	// place of the requested destination, so tests can forward a local port
+	// to a different port on the same host.
+	forwardAddr string
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// dropConnections closes every open client connection without stopping the
+// listener, simulating a transport failure.
+func (s *testSSHServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
 }
 
 // setupTestEnvironment creates a temporary directory and sets HOME environment variable
@@ -82,6 +105,20 @@ func setupSSHTestServer(t *testing.T, clientPublicKey ssh.PublicKey) (*testSSHSe
 }
 
 func startSSHTestServerWithListener(listener net.Listener, clientPublicKey ssh.PublicKey) (*testSSHServer, func(), error) {
+	return startSSHTestServerWithExec(listener, clientPublicKey, nil)
+}
+
+// setupSSHExecTestServer starts a test SSH server whose exec requests are
+// answered by the given handler.
+func setupSSHExecTestServer(t *testing.T, clientPublicKey ssh.PublicKey, exec func(string, ssh.Channel) uint32) (*testSSHServer, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, cleanup, err := startSSHTestServerWithExec(listener, clientPublicKey, exec)
+	require.NoError(t, err)
+	return server, cleanup
+}
+
+func startSSHTestServerWithExec(listener net.Listener, clientPublicKey ssh.PublicKey, exec func(string, ssh.Channel) uint32) (*testSSHServer, func(), error) {
 	hostSigner, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
 		return nil, nil, err
@@ -109,6 +146,8 @@ func startSSHTestServerWithListener(listener net.Listener, clientPublicKey ssh.P
 		hostKey:  hostKeySigner,
 		port:     addr.Port,
 		stop:     make(chan struct{}),
+		exec:     exec,
+		conns:    make(map[net.Conn]struct{}),
 	}
 
 	go server.serve()
@@ -146,16 +185,28 @@ func (s *testSSHServer) serve() {
 }
 
 func (s *testSSHServer) handleConn(conn net.Conn) {
-	defer conn.Close()
+	s.mu.Lock()
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
 
-	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
 	if err != nil {
 		return
 	}
 
-	go ssh.DiscardRequests(reqs)
+	go handleGlobalRequests(sconn, reqs)
 
 	for newChannel := range chans {
+		if newChannel.ChannelType() == "direct-tcpip" {
+			go handleDirectTCPIP(newChannel, s.forwardAddr)
+			continue
+		}
 		if newChannel.ChannelType() != "session" {
 			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
 			continue
@@ -170,6 +221,12 @@ func (s *testSSHServer) handleConn(conn net.Conn) {
 			if req.Type == "exec" {
 				_ = req.Reply(true, nil)
 				exitStatus := []byte{0, 0, 0, 0}
+				if s.exec != nil {
+					var payload struct{ Command string }
+					_ = ssh.Unmarshal(req.Payload, &payload)
+					status := s.exec(payload.Command, channel)
+					exitStatus = ssh.Marshal(struct{ Status uint32 }{status})
+				}
 				_, _ = channel.SendRequest("exit-status", false, exitStatus)
 				channel.Close()
 				break
@@ -180,6 +237,121 @@ func (s *testSSHServer) handleConn(conn net.Conn) {
 	}
 }
 
+// handleGlobalRequests answers keepalives and serves "tcpip-forward"
+// (remote port forwarding) by listening on the test host and opening a
+// forwarded-tcpip channel back to the client for every connection.
+func handleGlobalRequests(sconn *ssh.ServerConn, reqs <-chan *ssh.Request) {
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	for req := range reqs {
+		switch req.Type {
+		case "tcpip-forward":
+			var payload struct {
+				Addr string
+				Port uint32
+			}
+			if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", payload.Port))
+			if err != nil {
+				_ = req.Reply(false, nil)
+				continue
+			}
+			listeners = append(listeners, listener)
+			port := uint32(listener.Addr().(*net.TCPAddr).Port)
+			_ = req.Reply(true, ssh.Marshal(struct{ Port uint32 }{port}))
+
+			go func(listener net.Listener, addr string, port uint32) {
+				for {
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					origin := conn.RemoteAddr().(*net.TCPAddr)
+					channel, requests, err := sconn.OpenChannel("forwarded-tcpip", ssh.Marshal(struct {
+						Addr       string
+						Port       uint32
+						OriginAddr string
+						OriginPort uint32
+					}{addr, port, origin.IP.String(), uint32(origin.Port)}))
+					if err != nil {
+						conn.Close()
+						continue
+					}
+					go ssh.DiscardRequests(requests)
+					go func() {
+						defer conn.Close()
+						defer channel.Close()
+						done := make(chan struct{}, 2)
+						go func() { _, _ = io.Copy(channel, conn); done <- struct{}{} }()
+						go func() { _, _ = io.Copy(conn, channel); done <- struct{}{} }()
+						<-done
+					}()
+				}
+			}(listener, payload.Addr, port)
+		default:
+			if req.WantReply {
+				_ = req.Reply(req.Type == "keepalive@openssh.com", nil)
+			}
+		}
+	}
+}
+
+// handleDirectTCPIP serves a local port-forward channel by dialing the
+// requested destination from the test process.
+func handleDirectTCPIP(newChannel ssh.NewChannel, forwardAddr string) {
+	var payload struct {
+		DestAddr string
+		DestPort uint32
+		OrigAddr string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, "invalid payload")
+		return
+	}
+	addr := net.JoinHostPort(payload.DestAddr, fmt.Sprint(payload.DestPort))
+	if forwardAddr != "" {
+		addr = forwardAddr
+	}
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(channel, target)
+		_ = channel.CloseWrite()
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(target, channel)
+		if tc, ok := target.(*net.TCPConn); ok {
+			_ = tc.CloseWrite()
+		}
+		done <- struct{}{}
+	}()
+	<-done
+	<-done
+	channel.Close()
+	target.Close()
+}
+
 // readKnownHosts reads the known_hosts file and returns its contents
 func readKnownHosts(t *testing.T, knownHostsPath string) string {
 	data, err := os.ReadFile(knownHostsPath)
@@ -411,6 +583,45 @@ func TestVerifySSHConnectionCtxSuccess(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestExecuteSSHCommandStreaming(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	clientPrivateKey, _, clientPublicKey := generateRSAKeyPair(t)
+	keyFile := filepath.Join(tmpDir, "exec_key")
+	savePrivateKeyToFile(t, clientPrivateKey, keyFile)
+
+	var gotCommand string
+	server, serverCleanup := setupSSHExecTestServer(t, clientPublicKey, func(command string, channel ssh.Channel) uint32 {
+		gotCommand = command
+		_, _ = channel.Write([]byte("to stdout\n"))
+		_, _ = channel.Stderr().Write([]byte("to stderr\n"))
+		return 3
+	})
+	defer serverCleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, err := RobustSSHConnectCtx(ctx, "127.0.0.1", keyFile, server.port, 5)
+	require.NoError(t, err)
+	defer client.Close()
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := ExecuteSSHCommandStreaming(ctx, client, "nvidia-smi -L", nil, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "nvidia-smi -L", gotCommand)
+	assert.Equal(t, "to stdout\n", stdout.String())
+	assert.Equal(t, "to stderr\n", stderr.String())
+}
+
+func TestExecuteSSHCommandStreamingNotConnected(t *testing.T) {
+	_, err := ExecuteSSHCommandStreaming(context.Background(), &SSHClient{}, "true", nil, io.Discard, io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not connected")
+}
+
 func TestVerifySSHConnectionCtxRespectsContext(t *testing.T) {
 	tmpDir, cleanup := setupTestEnvironment(t)
 	defer cleanup()
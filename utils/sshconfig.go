@@ -8,7 +8,7 @@ import (
 )
 
 // UpdateSSHConfig updates the ~/.ssh/config file with the instance connection details
-func UpdateSSHConfig(instanceID, ip string, port int, uuid string, tunnelPorts []int, templatePorts []int) error {
+func UpdateSSHConfig(instanceID, ip string, port int, uuid string, forwards []ForwardSpec, templatePorts []int) error {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return fmt.Errorf("failed to get home directory: %w", err)
@@ -28,7 +28,7 @@ func UpdateSSHConfig(instanceID, ip string, port int, uuid string, tunnelPorts [
 	}
 
 	// Check if entry exists
-	hostName := fmt.Sprintf("tnr-%s", instanceID)
+	hostName := SSHHostAlias(instanceID)
 	existingIndex := -1
 	inBlock := false
 	blockStart := -1
@@ -65,16 +65,8 @@ func UpdateSSHConfig(instanceID, ip string, port int, uuid string, tunnelPorts [
 	configLines = append(configLines, fmt.Sprintf("    Port %d", port))
 
 	// Add port forwarding
-	allPorts := make(map[int]bool)
-	for _, p := range tunnelPorts {
-		allPorts[p] = true
-	}
-	for _, p := range templatePorts {
-		allPorts[p] = true
-	}
-
-	for port := range allPorts {
-		configLines = append(configLines, fmt.Sprintf("    LocalForward %d localhost:%d", port, port))
+	for _, f := range WithTemplatePorts(forwards, templatePorts) {
+		configLines = append(configLines, "    "+f.sshConfigLine())
 	}
 
 	// Update or append config
@@ -0,0 +1,432 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// SyncPlan lists the work a sync performs, as slash-separated paths relative
+// to the synced directories. Deleted directories end in "/".
+type SyncPlan struct {
+	Add    []string `json:"add"`
+	Change []string `json:"change"`
+	Delete []string `json:"delete"`
+	// Bytes is the amount of data copied for Add and Change.
+	Bytes int64 `json:"bytes"`
+}
+
+// Empty reports whether the destination is already in sync.
+func (p *SyncPlan) Empty() bool {
+	return len(p.Add) == 0 && len(p.Change) == 0 && len(p.Delete) == 0
+}
+
+// copies returns the files the plan copies, in path order.
+func (p *SyncPlan) copies() []string {
+	files := append(append([]string{}, p.Add...), p.Change...)
+	sort.Strings(files)
+	return files
+}
+
+// SyncOptions configures SFTPSync.
+type SyncOptions struct {
+	// Upload mirrors the local directory to the remote one; otherwise the
+	// remote directory is mirrored locally.
+	Upload bool
+	// Delete removes destination files that do not exist in the source.
+	Delete bool
+	// DryRun only computes the plan.
+	DryRun   bool
+	Progress TransferProgressFunc
+}
+
+// SFTPSync makes the destination directory a mirror of the source directory
+// over an SFTP session on client. Unlike SFTPTransfer it copies the contents
+// of the source into the destination rather than the source directory
+// itself, and only copies files whose size or mtime differ. Paths matched by
+// SyncIgnoreFiles at the source root are skipped on both sides. It returns
+// the plan that was (or, with DryRun, would be) carried out.
+func SFTPSync(ctx context.Context, client *SSHClient, localRoot, remoteRoot string, opts SyncOptions) (*SyncPlan, error) {
+	sc, closeSession, err := openSFTPSession(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	defer closeSession()
+
+	return syncSFTP(ctx, sc, localRoot, remoteRoot, opts)
+}
+
+// syncSFTP runs a sync over an established SFTP client.
+func syncSFTP(ctx context.Context, sc *sftp.Client, localRoot, remoteRoot string, opts SyncOptions) (*SyncPlan, error) {
+	var src, dst transferFS = localFS{}, remoteFS{sc}
+	srcRoot, dstRoot := localRoot, normalizeRemotePath(remoteRoot)
+	if !opts.Upload {
+		src, dst = dst, src
+		srcRoot, dstRoot = dstRoot, srcRoot
+	}
+
+	work, err := planSync(src, dst, srcRoot, dstRoot, opts.Delete)
+	if err != nil {
+		return nil, classifyTransferError(ctx, err)
+	}
+	if opts.DryRun {
+		return work.plan, nil
+	}
+	err = applySync(ctx, src, dst, srcRoot, dstRoot, work, opts.Progress)
+	return work.plan, classifyTransferError(ctx, err)
+}
+
+// syncTree maps paths relative to a sync root to their file info.
+type syncTree map[string]os.FileInfo
+
+func (t syncTree) paths() []string {
+	paths := make([]string, 0, len(t))
+	for rel := range t {
+		paths = append(paths, rel)
+	}
+	// Sorting puts every directory before its contents.
+	sort.Strings(paths)
+	return paths
+}
+
+// syncWork is a SyncPlan plus what applySync needs to carry it out.
+type syncWork struct {
+	plan   *SyncPlan
+	mkdirs []string // directories to create, parents first
+	src    syncTree
+}
+
+func (w *syncWork) empty() bool {
+	return w.plan.Empty() && len(w.mkdirs) == 0
+}
+
+// scanSyncSource lists a sync source, applying the ignore files at its root.
+func scanSyncSource(fsys transferFS, root string) (syncTree, *IgnoreMatcher, error) {
+	exists, err := statSyncRoot(fsys, root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, newTransferUserError(fmt.Sprintf("%s directory not found: %s", fsys.Side(), root))
+	}
+	ignore, err := loadSyncIgnore(fsys, root)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := scanSyncTree(fsys, root, ignore)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, ignore, nil
+}
+
+func scanSyncTree(fsys transferFS, root string, ignore *IgnoreMatcher) (syncTree, error) {
+	entries, err := walkTransferTree(fsys, root, ignore)
+	if err != nil {
+		return nil, err
+	}
+	tree := make(syncTree, len(entries))
+	for _, e := range entries {
+		tree[e.rel] = e.info
+	}
+	return tree, nil
+}
+
+// statSyncRoot reports whether root exists, failing if it is not a directory.
+func statSyncRoot(fsys transferFS, root string) (bool, error) {
+	info, err := fsys.Stat(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !info.IsDir() {
+		return false, newTransferUserError(fmt.Sprintf("%s path is not a directory: %s", fsys.Side(), root))
+	}
+	return true, nil
+}
+
+// loadSyncIgnore reads SyncIgnoreFiles from root, later files taking precedence.
+func loadSyncIgnore(fsys transferFS, root string) (*IgnoreMatcher, error) {
+	matcher := &IgnoreMatcher{}
+	for _, name := range SyncIgnoreFiles {
+		f, err := fsys.Open(fsys.Join(root, name))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		rules, err := ParseIgnorePatterns(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		matcher.Merge(rules)
+	}
+	return matcher, nil
+}
+
+// planSync compares the source and destination directories. A missing
+// destination is treated as empty.
+func planSync(src, dst transferFS, srcRoot, dstRoot string, deleteExtra bool) (*syncWork, error) {
+	srcTree, ignore, err := scanSyncSource(src, srcRoot)
+	if err != nil {
+		return nil, err
+	}
+	dstTree := syncTree{}
+	exists, err := statSyncRoot(dst, dstRoot)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if dstTree, err = scanSyncTree(dst, dstRoot, ignore); err != nil {
+			return nil, err
+		}
+	}
+	return diffSyncTrees(srcTree, dstTree, deleteExtra, sameSyncVersion), nil
+}
+
+// sameSyncVersion compares files across machines. Modification times are
+// compared to the second, the precision copies preserve over SFTP.
+func sameSyncVersion(a, b os.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Unix() == b.ModTime().Unix()
+}
+
+// diffSyncTrees works out how to turn dst into src. Destination entries whose
+// type differs from the source are always replaced; other entries missing
+// from the source are only deleted with deleteExtra.
+func diffSyncTrees(src, dst syncTree, deleteExtra bool, same func(a, b os.FileInfo) bool) *syncWork {
+	work := &syncWork{plan: &SyncPlan{}, src: src}
+
+	deleted := make(map[string]bool)
+	for _, rel := range dst.paths() {
+		d := dst[rel]
+		s, inSrc := src[rel]
+		remove := deleted[path.Dir(rel)] ||
+			(inSrc && s.IsDir() != d.IsDir()) ||
+			(!inSrc && deleteExtra)
+		if !remove {
+			continue
+		}
+		deleted[rel] = true
+		if d.IsDir() {
+			work.plan.Delete = append(work.plan.Delete, rel+"/")
+		} else {
+			work.plan.Delete = append(work.plan.Delete, rel)
+		}
+	}
+
+	for _, rel := range src.paths() {
+		s := src[rel]
+		d, inDst := dst[rel]
+		inDst = inDst && !deleted[rel]
+		switch {
+		case s.IsDir():
+			if !inDst {
+				work.mkdirs = append(work.mkdirs, rel)
+			}
+		case !inDst:
+			work.plan.Add = append(work.plan.Add, rel)
+			work.plan.Bytes += s.Size()
+		case !same(s, d):
+			work.plan.Change = append(work.plan.Change, rel)
+			work.plan.Bytes += s.Size()
+		}
+	}
+	return work
+}
+
+// applySync carries out work: deletions first (contents before their
+// directories), then new directories, then file copies.
+func applySync(ctx context.Context, src, dst transferFS, srcRoot, dstRoot string, work *syncWork, report TransferProgressFunc) error {
+	plan := work.plan
+	for i := len(plan.Delete) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		name := dst.Join(dstRoot, strings.TrimSuffix(plan.Delete[i], "/"))
+		if err := dst.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := dst.MkdirAll(dstRoot); err != nil {
+		return err
+	}
+	for _, rel := range work.mkdirs {
+		if err := dst.MkdirAll(dst.Join(dstRoot, rel)); err != nil {
+			return err
+		}
+	}
+
+	c := &fileCopier{ctx: ctx, src: src, dst: dst, overwrite: true}
+	tracker := newProgressTracker(len(plan.Add)+len(plan.Change), plan.Bytes, report)
+	for i, rel := range plan.copies() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tracker.start(i, rel, work.src[rel].Size())
+		_, err := c.copyFile(src.Join(srcRoot, rel), dst.Join(dstRoot, rel), work.src[rel], func(n int64, sent bool) { tracker.add(i, n, sent) })
+		tracker.finish(i, err == nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// New directories get the source's permissions once their contents are
+	// written, in case the mode is read-only.
+	for i := len(work.mkdirs) - 1; i >= 0; i-- {
+		rel := work.mkdirs[i]
+		if err := dst.Chmod(dst.Join(dstRoot, rel), work.src[rel].Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncWatchConfig configures WatchSync.
+type SyncWatchConfig struct {
+	LocalRoot  string
+	RemoteRoot string
+	// Delete removes remote files that do not exist locally.
+	Delete bool
+	// Interval between scans of LocalRoot; defaults to one second.
+	Interval time.Duration
+	// Dial opens a new SSH connection. It is called at start-up and again
+	// whenever the connection is lost.
+	Dial func(ctx context.Context) (*SSHClient, error)
+	// OnSync is called after each batch of changes is pushed, starting with
+	// the initial sync.
+	OnSync func(plan *SyncPlan)
+	Logf   func(format string, args ...any)
+}
+
+// WatchSync mirrors LocalRoot to RemoteRoot, then keeps pushing local changes
+// until ctx is cancelled. LocalRoot is rescanned every Interval and only the
+// files that changed since the last push are copied, over the same
+// connection. Polling needs no platform-specific notification API and a scan
+// only stats local files. When the connection drops WatchSync redials and
+// starts over with a full sync, since the remote side may have changed in the
+// meantime. Failing to make the initial connection is returned as an error.
+func WatchSync(ctx context.Context, cfg SyncWatchConfig) error {
+	const (
+		initialBackoff = time.Second
+		maxBackoff     = 30 * time.Second
+	)
+
+	logf := cfg.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+
+	first := true
+	backoff := initialBackoff
+	for {
+		client, err := cfg.Dial(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if first {
+				return err
+			}
+			logf("reconnect failed: %s (retrying in %s)", strings.TrimSpace(err.Error()), backoff)
+			if sleepWithContext(ctx, backoff) != nil {
+				return nil
+			}
+			backoff = minDuration(backoff*2, maxBackoff)
+			continue
+		}
+		first = false
+		backoff = initialBackoff
+
+		err = func() error {
+			defer client.Close()
+			sc, closeSession, err := openSFTPSession(ctx, client)
+			if err != nil {
+				return err
+			}
+			defer closeSession()
+			return watchSFTP(ctx, sc, cfg, logf)
+		}()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !errors.Is(err, errTransferConnectionLost) {
+			return err
+		}
+		logf("connection lost, reconnecting")
+	}
+}
+
+// watchSFTP runs one connection's worth of WatchSync. It returns
+// errTransferConnectionLost when the connection drops. Other failures while
+// pushing changes are logged and retried on the next scan.
+func watchSFTP(ctx context.Context, sc *sftp.Client, cfg SyncWatchConfig, logf func(string, ...any)) error {
+	local, remote := localFS{}, remoteFS{sc}
+	remoteRoot := normalizeRemotePath(cfg.RemoteRoot)
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	onSync := cfg.OnSync
+	if onSync == nil {
+		onSync = func(*SyncPlan) {}
+	}
+
+	work, err := planSync(local, remote, cfg.LocalRoot, remoteRoot, cfg.Delete)
+	if err == nil {
+		err = applySync(ctx, local, remote, cfg.LocalRoot, remoteRoot, work, nil)
+	}
+	if err != nil {
+		return classifyTransferError(ctx, err)
+	}
+	onSync(work.plan)
+	snapshot := work.src
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, _, err := scanSyncSource(local, cfg.LocalRoot)
+		if err != nil {
+			logf("warning: %v", err)
+			continue
+		}
+		// Local scans compare exact mtimes so that a same-size edit within
+		// the same second as the last push is still picked up.
+		work := diffSyncTrees(current, snapshot, cfg.Delete, func(a, b os.FileInfo) bool {
+			return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+		})
+		if work.empty() {
+			continue
+		}
+		if err := applySync(ctx, local, remote, cfg.LocalRoot, remoteRoot, work, nil); err != nil {
+			err = classifyTransferError(ctx, err)
+			if errors.Is(err, ErrTransferCancelled) || errors.Is(err, errTransferConnectionLost) {
+				return err
+			}
+			// The snapshot is kept, so the same changes are retried.
+			logf("warning: %v", err)
+			continue
+		}
+		snapshot = current
+		if !work.plan.Empty() {
+			onSync(work.plan)
+		}
+	}
+}
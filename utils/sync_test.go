@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncSFTPUpload(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	old := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeTestFile(t, filepath.Join(localDir, ".gitignore"), []byte("*.pyc\nwandb/\n"), 0644, old)
+	writeTestFile(t, filepath.Join(localDir, "train.py"), []byte("print('v2')\n"), 0644, old.Add(time.Hour))
+	writeTestFile(t, filepath.Join(localDir, "same.py"), []byte("same\n"), 0644, old)
+	writeTestFile(t, filepath.Join(localDir, "src", "model.py"), []byte("model\n"), 0644, old)
+	writeTestFile(t, filepath.Join(localDir, "src", "model.pyc"), []byte("bytecode"), 0644, old)
+	writeTestFile(t, filepath.Join(localDir, "wandb", "run.log"), []byte("log"), 0644, old)
+
+	dst := filepath.Join(remoteDir, "project")
+	writeTestFile(t, filepath.Join(dst, ".gitignore"), []byte("*.pyc\nwandb/\n"), 0644, old)
+	writeTestFile(t, filepath.Join(dst, "train.py"), []byte("print('v1')\n"), 0644, old)
+	writeTestFile(t, filepath.Join(dst, "same.py"), []byte("same\n"), 0644, old)
+	writeTestFile(t, filepath.Join(dst, "stale.py"), []byte("stale"), 0644, old)
+	writeTestFile(t, filepath.Join(dst, "old", "x.py"), []byte("x"), 0644, old)
+	// Ignored files on the destination are left alone, even with Delete.
+	writeTestFile(t, filepath.Join(dst, "cache.pyc"), []byte("keep"), 0644, old)
+
+	opts := SyncOptions{Upload: true, Delete: true, DryRun: true}
+	plan, err := syncSFTP(context.Background(), sc, localDir, "~/project", opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"src/model.py"}, plan.Add)
+	assert.Equal(t, []string{"train.py"}, plan.Change)
+	assert.Equal(t, []string{"old/", "old/x.py", "stale.py"}, plan.Delete)
+	assert.Equal(t, int64(len("model\n")+len("print('v2')\n")), plan.Bytes)
+	assert.FileExists(t, filepath.Join(dst, "stale.py"), "dry run must not modify the destination")
+
+	opts.DryRun = false
+	plan, err = syncSFTP(context.Background(), sc, localDir, "~/project", opts)
+	require.NoError(t, err)
+	assert.Len(t, plan.Add, 1)
+
+	got, err := os.ReadFile(filepath.Join(dst, "train.py"))
+	require.NoError(t, err)
+	assert.Equal(t, "print('v2')\n", string(got))
+	assert.FileExists(t, filepath.Join(dst, "src", "model.py"))
+	assert.FileExists(t, filepath.Join(dst, "cache.pyc"))
+	assert.NoFileExists(t, filepath.Join(dst, "src", "model.pyc"))
+	assert.NoFileExists(t, filepath.Join(dst, "stale.py"))
+	assert.NoDirExists(t, filepath.Join(dst, "old"))
+	assert.NoDirExists(t, filepath.Join(dst, "wandb"))
+
+	plan, err = syncSFTP(context.Background(), sc, localDir, "~/project", opts)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty())
+}
+
+func TestSyncSFTPKeepsExtraFilesWithoutDelete(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	writeTestFile(t, filepath.Join(localDir, "a.txt"), []byte("a"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(remoteDir, "out", "extra.txt"), []byte("extra"), 0644, time.Now())
+	// A file where the source has a directory is always replaced.
+	writeTestFile(t, filepath.Join(localDir, "conf", "b.txt"), []byte("b"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(remoteDir, "out", "conf"), []byte("file"), 0644, time.Now())
+
+	plan, err := syncSFTP(context.Background(), sc, localDir, "out", SyncOptions{Upload: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"conf"}, plan.Delete)
+	assert.FileExists(t, filepath.Join(remoteDir, "out", "extra.txt"))
+	assert.FileExists(t, filepath.Join(remoteDir, "out", "conf", "b.txt"))
+}
+
+func TestSyncSFTPDownload(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+
+	writeTestFile(t, filepath.Join(remoteDir, "results", "metrics.csv"), []byte("loss\n0.1\n"), 0644, time.Now())
+	dst := filepath.Join(localDir, "results")
+
+	plan, err := syncSFTP(context.Background(), sc, dst, "results", SyncOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics.csv"}, plan.Add)
+	got, err := os.ReadFile(filepath.Join(dst, "metrics.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "loss\n0.1\n", string(got))
+}
+
+func TestSyncSFTPUserErrors(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+	writeTestFile(t, filepath.Join(localDir, "file.txt"), []byte("x"), 0644, time.Now())
+
+	_, err := syncSFTP(context.Background(), sc, filepath.Join(localDir, "missing"), "out", SyncOptions{Upload: true})
+	assert.ErrorIs(t, err, ErrTransferUser)
+	assert.Contains(t, err.Error(), "local directory not found")
+
+	_, err = syncSFTP(context.Background(), sc, filepath.Join(localDir, "file.txt"), "out", SyncOptions{Upload: true})
+	assert.ErrorIs(t, err, ErrTransferUser)
+	assert.Contains(t, err.Error(), "local path is not a directory")
+}
+
+func TestWatchSFTPPushesChanges(t *testing.T) {
+	localDir := t.TempDir()
+	remoteDir := t.TempDir()
+	sc := newTestSFTPClient(t, remoteDir)
+	writeTestFile(t, filepath.Join(localDir, "train.py"), []byte("v1"), 0644, time.Now())
+	writeTestFile(t, filepath.Join(localDir, "old.py"), []byte("old"), 0644, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	plans := make(chan *SyncPlan, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- watchSFTP(ctx, sc, SyncWatchConfig{
+			LocalRoot:  localDir,
+			RemoteRoot: "project",
+			Delete:     true,
+			Interval:   20 * time.Millisecond,
+			OnSync:     func(p *SyncPlan) { plans <- p },
+		}, t.Logf)
+	}()
+
+	initial := <-plans
+	assert.Equal(t, []string{"old.py", "train.py"}, initial.Add)
+
+	// Same size as before: only the mtime tells the edit apart.
+	writeTestFile(t, filepath.Join(localDir, "train.py"), []byte("v2"), 0644, time.Now().Add(time.Second))
+	require.NoError(t, os.Remove(filepath.Join(localDir, "old.py")))
+	writeTestFile(t, filepath.Join(localDir, "pkg", "new.py"), []byte("new"), 0644, time.Now())
+
+	require.Eventually(t, func() bool {
+		got, err := os.ReadFile(filepath.Join(remoteDir, "project", "train.py"))
+		if err != nil || string(got) != "v2" {
+			return false
+		}
+		_, err = os.Stat(filepath.Join(remoteDir, "project", "old.py"))
+		return os.IsNotExist(err) && fileExists(filepath.Join(remoteDir, "project", "pkg", "new.py"))
+	}, 5*time.Second, 20*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop after cancellation")
+	}
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RemoteSession describes a tmux session running on an instance.
+type RemoteSession struct {
+	Name     string    `json:"name"`
+	Windows  int       `json:"windows"`
+	Attached bool      `json:"attached"`
+	Created  time.Time `json:"created"`
+}
+
+// sessionNamePattern limits names to characters that are safe to pass to the
+// remote shell unquoted and that tmux does not treat as target separators.
+var sessionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateSessionName checks that name can be used as a remote session name.
+func ValidateSessionName(name string) error {
+	if !sessionNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid session name '%s': use up to 64 letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// tmuxMissingMessage is printed by the remote commands below when tmux is not
+// installed on the instance.
+const tmuxMissingMessage = "tmux is not installed on this instance (try: sudo apt-get install -y tmux)"
+
+// TmuxSessionCommand returns a remote command that attaches to the named tmux
+// session, creating it if it does not exist. The name must pass
+// ValidateSessionName.
+func TmuxSessionCommand(name string) string {
+	return fmt.Sprintf("if ! command -v tmux >/dev/null 2>&1; then echo '%s' >&2; exit 127; fi; "+
+		"exec tmux new-session -A -s %s", tmuxMissingMessage, name)
+}
+
+// ListTmuxSessionsCommand prints one tab-separated line per tmux session, in
+// the format parsed by ParseTmuxSessions. It prints nothing when tmux is not
+// installed or no server is running.
+const ListTmuxSessionsCommand = "command -v tmux >/dev/null 2>&1 && " +
+	"tmux list-sessions -F '#{session_name}\t#{session_windows}\t#{session_attached}\t#{session_created}' 2>/dev/null; true"
+
+// ParseTmuxSessions parses the output of ListTmuxSessionsCommand, sorted by
+// name. Malformed lines are skipped.
+func ParseTmuxSessions(output string) []RemoteSession {
+	sessions := []RemoteSession{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) != 4 || fields[0] == "" {
+			continue
+		}
+		windows, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		attached, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		created, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, RemoteSession{
+			Name:     fields[0],
+			Windows:  windows,
+			Attached: attached > 0,
+			Created:  time.Unix(created, 0),
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Name < sessions[j].Name })
+	return sessions
+}
+
+// tmuxKilledMarker is printed by KillTmuxSessionCommand when a session was
+// removed, since ExecuteSSHCommandStdoutOnly does not report exit statuses.
+const tmuxKilledMarker = "tnr:killed"
+
+// KillTmuxSessionCommand returns a remote command that kills the named tmux
+// session. Its output satisfies TmuxSessionKilled only if the session existed.
+// The name must pass ValidateSessionName.
+func KillTmuxSessionCommand(name string) string {
+	// The "=" prefix makes tmux match the name exactly instead of by prefix.
+	return fmt.Sprintf("tmux kill-session -t '=%s' 2>/dev/null && echo %s; true", name, tmuxKilledMarker)
+}
+
+// TmuxSessionKilled reports whether the output of KillTmuxSessionCommand
+// indicates that the session was removed.
+func TmuxSessionKilled(output string) bool {
+	return strings.TrimSpace(output) == tmuxKilledMarker
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSessionName(t *testing.T) {
+	for _, name := range []string{"train", "exp-01", "a_b", "X"} {
+		assert.NoError(t, ValidateSessionName(name), name)
+	}
+	for _, name := range []string{"", "has space", "a:b", "a.b", "$(reboot)", "x'y", string(make([]byte, 65))} {
+		assert.Error(t, ValidateSessionName(name), name)
+	}
+}
+
+func TestTmuxSessionCommand(t *testing.T) {
+	cmd := TmuxSessionCommand("train")
+	assert.Contains(t, cmd, "exec tmux new-session -A -s train")
+	assert.Contains(t, cmd, tmuxMissingMessage)
+}
+
+func TestParseTmuxSessions(t *testing.T) {
+	out := "train\t2\t1\t1700000000\n" +
+		"eval\t1\t0\t1700000100\r\n" +
+		"garbage line\n" +
+		"bad\tx\t0\t1700000000\n" +
+		"\n"
+
+	sessions := ParseTmuxSessions(out)
+	assert.Equal(t, []RemoteSession{
+		{Name: "eval", Windows: 1, Attached: false, Created: time.Unix(1700000100, 0)},
+		{Name: "train", Windows: 2, Attached: true, Created: time.Unix(1700000000, 0)},
+	}, sessions)
+
+	assert.Empty(t, ParseTmuxSessions(""))
+	assert.NotNil(t, ParseTmuxSessions(""))
+}
+
+func TestKillTmuxSessionCommand(t *testing.T) {
+	cmd := KillTmuxSessionCommand("train")
+	assert.Contains(t, cmd, "tmux kill-session -t '=train'")
+	assert.True(t, TmuxSessionKilled(tmuxKilledMarker+"\n"))
+	assert.False(t, TmuxSessionKilled(""))
+}
@@ -5,13 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
-	"runtime"
 	"strings"
 )
 
-// ErrTransferCancelled is returned when a transfer process is killed by a
-// signal (e.g. user pressed Ctrl+C).
+// ErrTransferCancelled is returned when a transfer is interrupted by context
+// cancellation (e.g. user pressed Ctrl+C).
 var ErrTransferCancelled = errors.New("transfer cancelled")
 
 // ErrTransferUser is a sentinel for transfer errors caused by bad user input
@@ -43,95 +41,119 @@ func WrapAPIError(err error, context string) error {
 	return fmt.Errorf("%s: %w", context, err)
 }
 
-// Transfer uses rsync on Mac/Linux (with scp fallback), scp on Windows.
-// Retries up to 3 times on connection failures.
+// Transfer copies localPath to or from remotePath on an instance over SFTP,
+// without relying on rsync or scp being installed. See SFTPTransfer for the
+// copy semantics. A dropped connection is redialed and the transfer resumed,
+// up to 3 attempts in total.
 func Transfer(ctx context.Context, keyFile, ip string, port int, localPath, remotePath string, upload bool) error {
+	return TransferWithProgress(ctx, keyFile, ip, port, localPath, remotePath, upload, nil)
+}
+
+// TransferWithProgress is Transfer with a progress callback. Progress restarts
+// from the data already at the destination after a reconnect.
+func TransferWithProgress(ctx context.Context, keyFile, ip string, port int, localPath, remotePath string, upload bool, progress TransferProgressFunc) error {
+	source, dest := transferSourceDest(localPath, remotePath, upload)
+	_, err := TransferMany(ctx, keyFile, ip, port, []string{source}, dest, upload, DefaultTransferWorkers, progress)
+	return err
+}
+
+// TransferMany copies several sources to one destination, which must then be
+// a directory, copying up to workers files at once (see MaxTransferWorkers).
+// Sources are local paths and the destination a remote path for an upload,
+// the other way round for a download. Files that fail do not stop the
+// others: the report lists every file's outcome and the returned error
+// summarises the failures. Reconnects work as for Transfer.
+func TransferMany(ctx context.Context, keyFile, ip string, port int, sources []string, destination string, upload bool,
+	workers int, progress TransferProgressFunc) (*TransferReport, error) {
+	rec := newTransferRecorder()
+	host := TransferHost{KeyFile: keyFile, IP: ip, Port: port}
+	err := withTransferConnections(ctx, []TransferHost{host}, func(clients []*SSHClient) error {
+		return copyEndpoints(ctx, localEndpoint, sftpSessionEndpoint(clients[0]), sources, destination,
+			upload, min(workers, MaxTransferWorkers), progress, rec)
+	})
+	return finishTransfer(rec, err)
+}
+
+// TransferHost is the SSH endpoint of an instance taking part in a transfer.
+type TransferHost struct {
+	KeyFile string
+	IP      string
+	Port    int
+}
+
+// TransferBetween copies sources on one instance to destination on another,
+// streaming the data through this machine over an SSH connection to each, so
+// neither instance needs credentials for the other. Copy semantics, progress,
+// per-file results and reconnects are as for TransferMany; losing either
+// connection redials both.
+func TransferBetween(ctx context.Context, from, to TransferHost, sources []string, destination string,
+	workers int, progress TransferProgressFunc) (*TransferReport, error) {
+	rec := newTransferRecorder()
+	err := withTransferConnections(ctx, []TransferHost{from, to}, func(clients []*SSHClient) error {
+		return copyBetween(ctx, sftpSessionEndpoint(clients[0]), sftpSessionEndpoint(clients[1]), sources, destination,
+			min(workers, MaxTransferWorkers), progress, rec)
+	})
+	return finishTransfer(rec, err)
+}
+
+func finishTransfer(rec *transferRecorder, err error) (*TransferReport, error) {
+	report := rec.report()
+	if err != nil {
+		return report, err
+	}
+	return report, report.Err()
+}
+
+// withTransferConnections runs fn on fresh connections to hosts, redialing
+// all of them and running fn again while it fails with a lost connection, up
+// to 3 attempts.
+func withTransferConnections(ctx context.Context, hosts []TransferHost, fn func(clients []*SSHClient) error) error {
 	var err error
 	for attempt := 1; attempt <= 3; attempt++ {
-		if runtime.GOOS != "windows" {
-			if _, lookErr := exec.LookPath("rsync"); lookErr == nil {
-				err = rsyncTransfer(ctx, keyFile, ip, port, localPath, remotePath, upload)
-			} else {
-				err = scpTransfer(ctx, keyFile, ip, port, localPath, remotePath, upload)
+		if ctx.Err() != nil {
+			return ErrTransferCancelled
+		}
+		var clients []*SSHClient
+		clients, err = dialTransferHosts(ctx, hosts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ErrTransferCancelled
+			}
+			// Retrying cannot fix a rejected, missing or unreadable key.
+			if IsAuthError(err) || IsKeyParseError(err) || errors.Is(err, os.ErrNotExist) {
+				return err
 			}
-		} else {
-			err = scpTransfer(ctx, keyFile, ip, port, localPath, remotePath, upload)
+			err = newTransferUserError("connection failed: check your internet or instance status")
+			continue
 		}
-		if err == nil {
-			return nil
+		err = fn(clients)
+		for _, c := range clients {
+			c.Close()
 		}
-		// Only retry on connection failures (exit code 1 or 255)
-		var exitErr *exec.ExitError
-		if !errors.As(err, &exitErr) || (exitErr.ExitCode() != 1 && exitErr.ExitCode() != 255) {
-			return wrapTransferError(err, upload)
+		if !errors.Is(err, errTransferConnectionLost) {
+			return err
 		}
 	}
-	return wrapTransferError(err, upload)
+	return err
 }
 
-func wrapTransferError(err error, upload bool) error {
-	// Context cancellation (Ctrl-C) can arrive before exec.CommandContext
-	// even manages to fork. In that case Run returns context.Canceled
-	// directly with no *exec.ExitError — map it to the same user-facing
-	// "Transfer cancelled" path as a signal-killed subprocess.
-	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-		return ErrTransferCancelled
-	}
-	var exitErr *exec.ExitError
-	if !errors.As(err, &exitErr) {
-		return err
-	}
-
-	switch exitErr.ExitCode() {
-	case -1, 20:
-		return ErrTransferCancelled
-	case 1, 255:
-		return newTransferUserError("connection failed: check your internet or instance status")
-	case 2, 23:
-		if upload {
-			return newTransferUserError("local file not found")
+func dialTransferHosts(ctx context.Context, hosts []TransferHost) ([]*SSHClient, error) {
+	clients := make([]*SSHClient, 0, len(hosts))
+	for _, h := range hosts {
+		port := h.Port
+		if port == 0 {
+			port = 22
 		}
-		return newTransferUserError("remote file not found")
-	case 3:
-		return newTransferUserError("file access error: check that the path exists and you have permission")
-	case 10:
-		return newTransferUserError("connection lost during transfer: check your internet or instance status")
-	case 11:
-		if upload {
-			return newTransferUserError("remote directory does not exist")
+		client, err := RobustSSHConnectCtx(ctx, h.IP, h.KeyFile, port, 30)
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, err
 		}
-		return newTransferUserError("local directory does not exist")
-	default:
-		// Unknown rsync/scp exit code. Almost always indicates a user-side or
-		// network condition, not a CLI bug — wrap as user error so it doesn't
-		// reach Sentry.
-		return newTransferUserError(fmt.Sprintf("transfer failed (exit code %d)", exitErr.ExitCode()))
-	}
-}
-
-func rsyncTransfer(ctx context.Context, keyFile, ip string, port int, localPath, remotePath string, upload bool) error {
-	remote := fmt.Sprintf("ubuntu@%s:%s", ip, remotePath)
-	sshCmd := fmt.Sprintf("ssh -i %s -p %d -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -o LogLevel=ERROR -o ConnectTimeout=30", keyFile, port)
-	args := []string{"-az", "--progress", "-e", sshCmd, localPath, remote}
-	if !upload {
-		args = []string{"-az", "--progress", "-e", sshCmd, remote, localPath}
-	}
-	cmd := exec.CommandContext(ctx, "rsync", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
-func scpTransfer(ctx context.Context, keyFile, ip string, port int, localPath, remotePath string, upload bool) error {
-	remote := fmt.Sprintf("ubuntu@%s:%s", ip, remotePath)
-	args := []string{"-i", keyFile, "-P", fmt.Sprintf("%d", port), "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null", "-o", "LogLevel=ERROR", "-o", "ConnectTimeout=30", "-r", localPath, remote}
-	if !upload {
-		args = []string{"-i", keyFile, "-P", fmt.Sprintf("%d", port), "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null", "-o", "LogLevel=ERROR", "-o", "ConnectTimeout=30", "-r", remote, localPath}
+		clients = append(clients, client)
 	}
-	cmd := exec.CommandContext(ctx, "scp", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return clients, nil
 }
 
 // SCPTransfer is deprecated, use Transfer instead.
@@ -3,15 +3,16 @@ package utils
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 // TestTransferContextAlreadyCancelled guards the CLI-GO-1H regression:
-// when Ctrl-C cancels the context before exec.CommandContext manages to
-// fork the subprocess, cmd.Run returns context.Canceled directly (not an
-// *exec.ExitError). wrapTransferError must recognise this and return
-// ErrTransferCancelled so the caller prints "Transfer cancelled" instead
-// of leaking a raw "context canceled" error to the user (and to Sentry).
+// when Ctrl-C cancels the context before the transfer gets going, Transfer
+// must return ErrTransferCancelled so the caller prints "Transfer cancelled"
+// instead of leaking a raw "context canceled" error (or a key parse error
+// for a key that was never read) to the user and to Sentry.
 func TestTransferContextAlreadyCancelled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
@@ -21,3 +22,20 @@ func TestTransferContextAlreadyCancelled(t *testing.T) {
 		t.Fatalf("expected ErrTransferCancelled, got %T: %v", err, err)
 	}
 }
+
+// TestTransferKeyErrorsAreNotRetried checks that a key problem surfaces as-is
+// instead of as a generic connection failure.
+func TestTransferKeyErrorsAreNotRetried(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "bad_key")
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Transfer(context.Background(), keyFile, "127.0.0.1", 22, "/tmp/x", "/tmp/y", true)
+	if !IsKeyParseError(err) {
+		t.Fatalf("expected key parse error, got %T: %v", err, err)
+	}
+	if errors.Is(err, ErrTransferUser) {
+		t.Fatalf("key parse error should not be reported as a user transfer error: %v", err)
+	}
+}
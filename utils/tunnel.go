@@ -0,0 +1,358 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// TunnelState describes a background port-forward daemon started by
+// `tnr tunnel start`. It is persisted as JSON under ThunderDir()/tunnels,
+// in a subdirectory per profile outside the default one.
+type TunnelState struct {
+	InstanceID string        `json:"instance_id"`
+	PID        int           `json:"pid"`
+	Forwards   []ForwardSpec `json:"forwards"`
+	LogFile    string        `json:"log_file"`
+	StartedAt  time.Time     `json:"started_at"`
+}
+
+// Alive reports whether the daemon that recorded the state is still running.
+// The PID alone cannot tell: once the daemon is gone its PID may be reused by
+// an unrelated process. The daemon's lock on the instance (see LockTunnel) is
+// released by the OS when it exits, so a held lock identifies it.
+func (s *TunnelState) Alive() bool {
+	return tunnelLocked(s.InstanceID) && processRunning(s.PID)
+}
+
+// GetTunnelDir returns the directory holding the selected profile's tunnel
+// state, lock and log files.
+func GetTunnelDir() (string, error) {
+	return ThunderSubdir(profileSubdir("tunnels"))
+}
+
+func tunnelStatePath(instanceID string) (string, error) {
+	dir, err := GetTunnelDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("tnr-%s.json", instanceID)), nil
+}
+
+func tunnelLockPath(instanceID string) (string, error) {
+	dir, err := GetTunnelDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("tnr-%s.lock", instanceID)), nil
+}
+
+// errFileLocked is returned by lockFile when another process holds the lock.
+var errFileLocked = errors.New("file is locked by another process")
+
+// LockTunnel claims an instance for the calling tunnel daemon until release is
+// called or the process exits. It fails if another daemon holds the claim.
+// State files left behind by a previous daemon are stale once the lock is
+// held, so the caller should remove them before recording its own.
+func LockTunnel(instanceID string) (release func(), err error) {
+	path, err := tunnelLockPath(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tunnel lock: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		if errors.Is(err, errFileLocked) {
+			return nil, fmt.Errorf("a tunnel is already running for instance %s", instanceID)
+		}
+		return nil, fmt.Errorf("failed to lock tunnel: %w", err)
+	}
+	return func() { f.Close() }, nil
+}
+
+// tunnelLocked reports whether a daemon currently holds the lock for an instance.
+func tunnelLocked(instanceID string) bool {
+	path, err := tunnelLockPath(instanceID)
+	if err != nil {
+		return false
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return errors.Is(lockFile(f), errFileLocked)
+}
+
+// GetTunnelLogPath returns the log file used by the tunnel daemon for an instance.
+func GetTunnelLogPath(instanceID string) (string, error) {
+	dir, err := GetTunnelDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("tnr-%s.log", instanceID)), nil
+}
+
+// WriteTunnelState atomically records a running tunnel daemon.
+func WriteTunnelState(state *TunnelState) error {
+	path, err := tunnelStatePath(state.InstanceID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tunnel state: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tunnel state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write tunnel state: %w", err)
+	}
+	return nil
+}
+
+// ReadTunnelState returns the recorded tunnel for an instance, or nil if there is none.
+func ReadTunnelState(instanceID string) (*TunnelState, error) {
+	path, err := tunnelStatePath(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	return readTunnelStateFile(path)
+}
+
+func readTunnelStateFile(path string) (*TunnelState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read tunnel state: %w", err)
+	}
+	var state TunnelState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse tunnel state %s: %w", filepath.Base(path), err)
+	}
+	return &state, nil
+}
+
+// RemoveTunnelState deletes the recorded tunnel for an instance.
+func RemoveTunnelState(instanceID string) error {
+	path, err := tunnelStatePath(instanceID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove tunnel state: %w", err)
+	}
+	return nil
+}
+
+// ListTunnelStates returns all running tunnel daemons sorted by instance ID.
+// State left behind by daemons that are no longer running is cleaned up.
+func ListTunnelStates() ([]TunnelState, error) {
+	dir, err := GetTunnelDir()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "tnr-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var states []TunnelState
+	for _, path := range matches {
+		state, err := readTunnelStateFile(path)
+		if err != nil || state == nil {
+			continue
+		}
+		if !state.Alive() {
+			_ = os.Remove(path)
+			continue
+		}
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].InstanceID < states[j].InstanceID
+	})
+	return states, nil
+}
+
+// StopTunnel terminates the daemon recorded in state, waits up to timeout for
+// it to exit and removes its state file. If the daemon is still running after
+// timeout the state is kept and an error is returned.
+func StopTunnel(state *TunnelState, timeout time.Duration) error {
+	if state.Alive() {
+		if err := terminateProcess(state.PID); err != nil {
+			return fmt.Errorf("failed to stop tunnel process %d: %w", state.PID, err)
+		}
+		deadline := time.Now().Add(timeout)
+		for state.Alive() {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("tunnel process %d did not exit within %s", state.PID, timeout)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return RemoveTunnelState(state.InstanceID)
+}
+
+// TunnelConfig configures a long-running port forward started by RunTunnel.
+type TunnelConfig struct {
+	Forwards []ForwardSpec
+	// Dial opens a new SSH connection. It is called at start-up and again
+	// whenever the keepalive reports that the transport is dead.
+	Dial func(ctx context.Context) (*SSHClient, error)
+	// Ready is called once all ports are bound and the first connection is up.
+	Ready func()
+	Logf  func(format string, args ...any)
+}
+
+// RunTunnel keeps cfg.Forwards up over SSH until ctx is cancelled. Unlike the
+// forwards started by RunInteractiveSession, local listeners outlive the SSH
+// connection: when the keepalive detects a dead transport the tunnel redials,
+// keeps serving the same local ports and re-requests any remote forwards.
+// Failing to bind a port or to make the initial connection is returned as an
+// error.
+func RunTunnel(ctx context.Context, cfg TunnelConfig) error {
+	const (
+		initialBackoff = time.Second
+		maxBackoff     = 30 * time.Second
+	)
+
+	logf := cfg.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.RWMutex
+	var current *ssh.Client
+	activeClient := func() *ssh.Client {
+		mu.RLock()
+		defer mu.RUnlock()
+		return current
+	}
+	setClient := func(c *ssh.Client) {
+		mu.Lock()
+		current = c
+		mu.Unlock()
+	}
+
+	var listeners []net.Listener
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	var localForwards, remoteForwards []ForwardSpec
+	for _, fwd := range cfg.Forwards {
+		if fwd.Direction == ForwardRemote {
+			remoteForwards = append(remoteForwards, fwd)
+			continue
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", fwd.BindPort))
+		if err != nil {
+			return fmt.Errorf("listen on port %d: %w", fwd.BindPort, err)
+		}
+		listeners = append(listeners, listener)
+		localForwards = append(localForwards, fwd)
+	}
+
+	sshClient, err := cfg.Dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, listener := range listeners {
+		go func(listener net.Listener, fwd ForwardSpec) {
+			for {
+				localConn, err := listener.Accept()
+				if err != nil {
+					return // listener closed
+				}
+				client := activeClient()
+				if client == nil {
+					// Reconnecting; the caller can retry.
+					localConn.Close()
+					continue
+				}
+				go serveLocalConn(client, localConn, fwd)
+			}
+		}(listener, localForwards[i])
+	}
+
+	for _, fwd := range cfg.Forwards {
+		logf("forwarding %s", fwd.Describe())
+	}
+
+	first := true
+	backoff := initialBackoff
+	for {
+		connCtx, connCancel := context.WithCancel(ctx)
+		for _, fwd := range remoteForwards {
+			if err := startRemoteForward(connCtx, sshClient.GetClient(), fwd); err != nil {
+				if first {
+					connCancel()
+					sshClient.Close()
+					return err
+				}
+				logf("warning: could not forward %s: %v", fwd.Describe(), err)
+			}
+		}
+		setClient(sshClient.GetClient())
+		logf("connected")
+		if first {
+			first = false
+			if cfg.Ready != nil {
+				cfg.Ready()
+			}
+		}
+
+		go func(client *SSHClient) {
+			_ = startKeepalive(connCtx, client.GetClient())
+			client.Close()
+		}(sshClient)
+		_ = sshClient.GetClient().Wait()
+		connCancel()
+		setClient(nil)
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		logf("connection lost, reconnecting")
+
+		for {
+			sshClient, err = cfg.Dial(ctx)
+			if err == nil {
+				backoff = initialBackoff
+				break
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			logf("reconnect failed: %s (retrying in %s)", strings.TrimSpace(err.Error()), backoff)
+			if sleepWithContext(ctx, backoff) != nil {
+				return nil
+			}
+			backoff = minDuration(backoff*2, maxBackoff)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTunnelStateRoundTrip(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	state := &TunnelState{
+		InstanceID: "3",
+		PID:        os.Getpid(),
+		Forwards:   []ForwardSpec{LocalForward(8888), LocalForward(6006)},
+		LogFile:    "tnr-3.log",
+		StartedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+	release, err := LockTunnel("3")
+	require.NoError(t, err)
+	defer release()
+	require.NoError(t, WriteTunnelState(state))
+
+	got, err := ReadTunnelState("3")
+	require.NoError(t, err)
+	assert.Equal(t, state, got)
+	assert.True(t, got.Alive())
+
+	states, err := ListTunnelStates()
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "3", states[0].InstanceID)
+
+	require.NoError(t, RemoveTunnelState("3"))
+	got, err = ReadTunnelState("3")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestListTunnelStatesRemovesStale(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("TNR_HOME", home)
+
+	proc := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, proc.Run())
+
+	release, err := LockTunnel("live")
+	require.NoError(t, err)
+	defer release()
+
+	require.NoError(t, WriteTunnelState(&TunnelState{InstanceID: "dead", PID: proc.Process.Pid}))
+	require.NoError(t, WriteTunnelState(&TunnelState{InstanceID: "live", PID: os.Getpid()}))
+	// The daemon is gone but its PID now belongs to an unrelated process.
+	require.NoError(t, WriteTunnelState(&TunnelState{InstanceID: "reused", PID: os.Getpid()}))
+
+	states, err := ListTunnelStates()
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	assert.Equal(t, "live", states[0].InstanceID)
+
+	for _, id := range []string{"dead", "reused"} {
+		_, err = os.Stat(filepath.Join(home, "tunnels", "tnr-"+id+".json"))
+		assert.True(t, os.IsNotExist(err), id)
+	}
+}
+
+func TestLockTunnelIsExclusive(t *testing.T) {
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	release, err := LockTunnel("5")
+	require.NoError(t, err)
+	assert.True(t, tunnelLocked("5"))
+
+	_, err = LockTunnel("5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already running for instance 5")
+
+	release()
+	assert.False(t, tunnelLocked("5"))
+	release, err = LockTunnel("5")
+	require.NoError(t, err)
+	release()
+}
+
+// TestTunnelDaemonHelperProcess is not a real test: it stands in for a tunnel
+// daemon when the test binary is re-executed by startFakeTunnelDaemon.
+func TestTunnelDaemonHelperProcess(t *testing.T) {
+	id := os.Getenv("TNR_TEST_TUNNEL_DAEMON")
+	if id == "" {
+		t.Skip("helper process")
+	}
+	if os.Getenv("TNR_TEST_TUNNEL_IGNORE_TERM") == "1" {
+		signal.Ignore(syscall.SIGTERM)
+	}
+	if _, err := LockTunnel(id); err != nil {
+		os.Exit(1)
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// startFakeTunnelDaemon starts a process that holds the tunnel lock for
+// instanceID and waits for it to be taken.
+func startFakeTunnelDaemon(t *testing.T, instanceID string, ignoreTerm bool) *exec.Cmd {
+	t.Helper()
+	proc := exec.Command(os.Args[0], "-test.run=^TestTunnelDaemonHelperProcess$")
+	proc.Env = append(os.Environ(), "TNR_TEST_TUNNEL_DAEMON="+instanceID)
+	if ignoreTerm {
+		proc.Env = append(proc.Env, "TNR_TEST_TUNNEL_IGNORE_TERM=1")
+	}
+	require.NoError(t, proc.Start())
+	exited := make(chan struct{})
+	go func() {
+		_ = proc.Wait()
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		_ = proc.Process.Kill()
+		<-exited
+	})
+	require.Eventually(t, func() bool { return tunnelLocked(instanceID) }, 10*time.Second, 50*time.Millisecond)
+	return proc
+}
+
+func TestStopTunnelWaitsForExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on SIGTERM")
+	}
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	proc := startFakeTunnelDaemon(t, "1", false)
+	state := &TunnelState{InstanceID: "1", PID: proc.Process.Pid}
+	require.NoError(t, WriteTunnelState(state))
+
+	require.NoError(t, StopTunnel(state, 10*time.Second))
+	assert.False(t, tunnelLocked("1"))
+	got, err := ReadTunnelState("1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestStopTunnelTimesOut(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("relies on SIGTERM")
+	}
+	t.Setenv("TNR_HOME", t.TempDir())
+
+	proc := startFakeTunnelDaemon(t, "2", true)
+	state := &TunnelState{InstanceID: "2", PID: proc.Process.Pid}
+	require.NoError(t, WriteTunnelState(state))
+
+	err := StopTunnel(state, 300*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not exit")
+	// The daemon is still running, so its state is kept.
+	got, err := ReadTunnelState("2")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
+
+// startEchoServer listens on a random local port and echoes each line back.
+func startEchoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = conn.Write(append(scanner.Bytes(), '\n'))
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// freePort returns a local port that was free at the time of the call.
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func echoThroughTunnel(port int, msg string) (string, error) {
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+		return "", err
+	}
+	return bufio.NewReader(conn).ReadString('\n')
+}
+
+func TestRunTunnelForwardsAndReconnects(t *testing.T) {
+	tmpDir, cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	clientPrivateKey, _, clientPublicKey := generateRSAKeyPair(t)
+	keyFile := filepath.Join(tmpDir, "tunnel_key")
+	savePrivateKeyToFile(t, clientPrivateKey, keyFile)
+
+	server, serverCleanup := setupSSHTestServer(t, clientPublicKey)
+	defer serverCleanup()
+	server.forwardAddr = startEchoServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	localPort := freePort(t)
+	var dials atomic.Int32
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- RunTunnel(ctx, TunnelConfig{
+			Forwards: []ForwardSpec{LocalForward(localPort)},
+			Dial: func(ctx context.Context) (*SSHClient, error) {
+				dials.Add(1)
+				return RobustSSHConnectCtx(ctx, "127.0.0.1", keyFile, server.port, 5)
+			},
+			Ready: func() { close(ready) },
+		})
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("tunnel exited early: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel did not become ready")
+	}
+
+	got, err := echoThroughTunnel(localPort, "ping")
+	require.NoError(t, err)
+	assert.Equal(t, "ping\n", got)
+
+	server.dropConnections()
+	require.Eventually(t, func() bool {
+		if dials.Load() < 2 {
+			return false
+		}
+		got, err := echoThroughTunnel(localPort, "again")
+		return err == nil && got == "again\n"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("tunnel did not stop after cancellation")
+	}
+}
+
+func TestRunTunnelPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	dialed := false
+	err = RunTunnel(context.Background(), TunnelConfig{
+		Forwards: []ForwardSpec{LocalForward(port)},
+		Dial: func(ctx context.Context) (*SSHClient, error) {
+			dialed = true
+			return nil, fmt.Errorf("unexpected dial")
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("listen on port %d", port))
+	assert.False(t, dialed)
+}